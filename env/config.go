@@ -1,10 +1,14 @@
 package env
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
 
-var Env config
-
-type config struct {
+// Config holds the settings of the SDK. It can be built by hand or loaded
+// with Load, and is checked with Validate.
+type Config struct {
 	HTTPHeader              string `mapstructure:"SCRAPELESS_HTTP_HEADER"`
 	ProxyCountry            string `mapstructure:"SCRAPELESS_PROXY_COUNTRY"`
 	ProxySessionDurationMax int64  `mapstructure:"SCRAPELESS_PROXY_SESSION_DURATION_MAX"`
@@ -21,17 +25,18 @@ type config struct {
 	//ScrapelessApiHost     string `mapstructure:"SCRAPELESS_API_HOST"`
 	//ScrapelessCaptchaHost string `mapstructure:"SCRAPELESS_CAPTCHA_HOST"`
 
-	Actor actorEnv `mapstructure:",squash"`
-	Log   logEnv   `mapstructure:",squash"`
+	Actor ActorEnv `mapstructure:",squash"`
+	Log   LogEnv   `mapstructure:",squash"`
 
 	IsOnline bool `mapstructure:"SCRAPELESS_IS_ONLINE"`
 }
 
-type actorEnv struct {
+// ActorEnv describes the actor run and the storages it uses.
+type ActorEnv struct {
 	TeamId  string `mapstructure:"SCRAPELESS_TEAM_ID"`
 	ActorId string `mapstructure:"SCRAPELESS_ACTOR_ID"`
 	RunId   string `mapstructure:"SCRAPELESS_RUN_ID"`
-	ApiKey  string `mapstructure:"SCRAPELESS_API_KEY"`
+	ApiKey  Secret `mapstructure:"SCRAPELESS_API_KEY"`
 
 	KvNamespaceId string `mapstructure:"SCRAPELESS_KV_NAMESPACE_ID"`
 	DatasetId     string `mapstructure:"SCRAPELESS_DATASET_ID"`
@@ -42,45 +47,101 @@ type actorEnv struct {
 	HttpPort string `mapstructure:"SCRAPELESS_HTTP_PORT"`
 }
 
-type logEnv struct {
+// LogEnv configures the log files.
+type LogEnv struct {
 	MaxSize    int    `mapstructure:"SCRAPELESS_LOG_MAX_SIZE"`
 	MaxBackups int    `mapstructure:"SCRAPELESS_LOG_MAX_BACKUPS"`
 	MaxAge     int    `mapstructure:"SCRAPELESS_LOG_MAX_AGE"`
 	LogRootDir string `mapstructure:"SCRAPELESS_LOG_ROOT_DIR"`
 }
 
-func (c *config) Validate() error {
+// Secret is a string that is redacted when printed, so that configs can be
+// logged safely. Convert it with string() to use the value.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 8 {
+		return "****"
+	}
+	return "****" + string(s[len(s)-4:])
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// Default returns a config holding the default values only.
+func Default() *Config {
+	return &Config{
+		HTTPHeader:              "x-api-token",
+		ProxyCountry:            "ANY",
+		ProxySessionDurationMax: 120,
+		ProxyGatewayHost:        "gw-us.scrapeless.io:8789",
+		ScrapelessBaseApiUrl:    "https://api.scrapeless.com",
+		ScrapelessStorageUrl:    "https://storage.scrapeless.com",
+		ScrapelessActorUrl:      "https://actor.scrapeless.com",
+		ScrapelessBrowserUrl:    "https://browser.scrapeless.com",
+		ScrapelessCrawlApiUrl:   "https://api.scrapeless.com",
+	}
+}
+
+// normalize fills the IDs of the local storages when running offline.
+func (c *Config) normalize() {
+	if c.IsOnline {
+		return
+	}
 	defaultID := "default"
-	if !c.IsOnline {
-		c.Actor.TeamId = defaultID
-		c.Actor.ActorId = defaultID
-		c.Actor.RunId = defaultID
-		c.Actor.DatasetId = defaultID
-		c.Actor.QueueId = defaultID
-		c.Actor.CollectionId = defaultID
-		c.Actor.KvNamespaceId = defaultID
-		c.Actor.BucketId = defaultID
+	for _, id := range []*string{
+		&c.Actor.TeamId, &c.Actor.ActorId, &c.Actor.RunId, &c.Actor.DatasetId, &c.Actor.QueueId,
+		&c.Actor.CollectionId, &c.Actor.KvNamespaceId, &c.Actor.BucketId,
+	} {
+		if *id == "" {
+			*id = defaultID
+		}
 	}
+}
+
+// Validate reports every missing or malformed setting.
+func (c *Config) Validate() error {
+	var errs []error
 	if c.Actor.TeamId == "" {
-		return errors.New("invalid env param team_Id")
+		errs = append(errs, errors.New("invalid env param team_Id"))
 	}
 	if c.Actor.ActorId == "" {
-		return errors.New("invalid env param actor_Id")
+		errs = append(errs, errors.New("invalid env param actor_Id"))
 	}
 	if c.Actor.RunId == "" {
-		return errors.New("invalid env param run_Id")
+		errs = append(errs, errors.New("invalid env param run_Id"))
 	}
 	if c.Actor.ApiKey == "" {
-		return errors.New("invalid env param apikey")
+		errs = append(errs, errors.New("invalid env param apikey"))
 	}
-
-	return nil
+	if c.HTTPHeader == "" {
+		errs = append(errs, errors.New("invalid env param http_header"))
+	}
+	for _, u := range []struct{ name, value string }{
+		{"base_api_url", c.ScrapelessBaseApiUrl},
+		{"storage_api_url", c.ScrapelessStorageUrl},
+		{"actor_api_url", c.ScrapelessActorUrl},
+		{"browser_api_url", c.ScrapelessBrowserUrl},
+		{"crawl_api_url", c.ScrapelessCrawlApiUrl},
+	} {
+		if parsed, err := url.Parse(u.value); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid env param %s: %q", u.name, u.value))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func GetLogEnv() *logEnv {
-	return &Env.Log
+// GetLogEnv returns the log settings of the default config.
+func GetLogEnv() *LogEnv {
+	return &Get().Log
 }
 
-func GetActorEnv() *actorEnv {
-	return &Env.Actor
+// GetActorEnv returns the actor settings of the default config.
+func GetActorEnv() *ActorEnv {
+	return &Get().Actor
 }
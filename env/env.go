@@ -1,86 +1,90 @@
 package env
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path"
 	"reflect"
-	"runtime"
-	"time"
+	"sync"
+	"sync/atomic"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-func init() {
-	initLog()
-	// Set default values
-	setDefaults()
+// dotEnv is read by the default config when it exists in the working directory.
+const dotEnv = ".env"
 
-	// Enable automatic environment lookup
-	viper.AutomaticEnv()
-
-	// Bind env vars (required when there's no .env file)
-	if err := bindEnvs(viper.GetViper(), &Env); err != nil {
-		log.Fatalf("failed to bind environment variables: %v", err)
-	}
-
-	// Optionally read .env file (non-fatal)
-	viper.SetConfigFile(".env")
-	err := viper.ReadInConfig()
-	if err != nil {
-		log.Warnf("scrapeless: warn reading config file: %v", err)
-	}
-
-	// Unmarshal all config into struct
-	err = viper.Unmarshal(&Env)
-	if err != nil {
-		panic(err)
-	}
+var (
+	current  atomic.Pointer[Config]
+	loadOnce sync.Once
+)
 
-	// Validate required fields
-	err = Env.Validate()
-	if err != nil {
-		log.Errorf("scrapeless: validate config err: %v", err)
-	}
-	log.Infof("scrapeless: conf: %+v", Env)
+// Get returns the default config of the process. It is loaded from the
+// environment and ./.env on first use, unless Set was called before.
+// The returned config must not be modified.
+func Get() *Config {
+	loadOnce.Do(func() {
+		if current.Load() != nil {
+			return
+		}
+		var files []string
+		if _, err := os.Stat(dotEnv); err == nil {
+			files = append(files, dotEnv)
+		}
+		cfg, err := Load(files...)
+		if err != nil {
+			// A malformed .env must not prevent reading the environment.
+			cfg, _ = Load()
+		}
+		current.CompareAndSwap(nil, cfg)
+	})
+	return current.Load()
 }
 
-func initLog() {
-	log.SetOutput(os.Stdout)
-	log.SetFormatter(&log.TextFormatter{
-		FullTimestamp: true,
-		DisableColors: false,
-		ForceColors:   true,
-		CallerPrettyfier: func(f *runtime.Frame) (function string, file string) {
-			filename := path.Base(f.File)
-			fc := path.Base(f.Function)
-			return fmt.Sprintf("%s()", fc), fmt.Sprintf(" - %s:%d", filename, f.Line)
-		},
-		TimestampFormat: time.DateTime,
-	})
-	log.SetReportCaller(true)
-	log.SetLevel(log.TraceLevel)
+// Set replaces the default config of the process with a copy of cfg.
+// Clients created afterwards use it.
+func Set(cfg Config) {
+	cfg.normalize()
+	current.Store(&cfg)
 }
 
-func setDefaults() {
-	viper.SetDefault("SCRAPELESS_PROXY_COUNTRY", "ANY")
-	//viper.SetDefault("SCRAPELESS_BROWSER_API_HOST", "https://api.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_PROXY_SESSION_DURATION_MAX", 120)
-	viper.SetDefault("SCRAPELESS_PROXY_GATEWAY_HOST", "gw-us.scrapeless.io:8789")
-	viper.SetDefault("SCRAPELESS_HTTP_HEADER", "x-api-token")
-	viper.SetDefault("SCRAPELESS_BASE_API_URL", "https://api.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_ACTOR_API_URL", "https://actor.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_STORAGE_API_URL", "https://storage.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_BROWSER_API_URL", "https://browser.scrapeless.com")
-	viper.SetDefault("SCRAPELESS_CRAWL_API_URL", "https://api.scrapeless.com")
+// Load builds a config from the defaults, the given files and the
+// environment, later sources overriding earlier ones. Files are read
+// according to their extension: .env, .yaml/.yml or .toml. Keys are the
+// environment variable names, in any case:
+//
+//	scrapeless_api_key: your-api-key
+//	scrapeless_is_online: true
+func Load(files ...string) (*Config, error) {
+	v := viper.New()
+	if err := walk(Default(), func(key string, value reflect.Value) error {
+		v.SetDefault(key, value.Interface())
+		return v.BindEnv(key)
+	}); err != nil {
+		return nil, fmt.Errorf("bind environment variables: %w", err)
+	}
+	for _, file := range files {
+		v.SetConfigFile(file)
+		if err := v.MergeInConfig(); err != nil {
+			return nil, fmt.Errorf("read config file %s: %w", file, err)
+		}
+	}
+	cfg := new(Config)
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	cfg.normalize()
+	return cfg, nil
 }
 
-func bindEnvs(v *viper.Viper, iface any) error {
+// walk calls fn for every field of the struct pointed to by iface that has
+// a mapstructure tag, descending into squashed structs.
+func walk(iface any, fn func(key string, value reflect.Value) error) error {
 	val := reflect.ValueOf(iface)
-	if val.Kind() == reflect.Ptr {
-		val = val.Elem()
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Struct {
+		return errors.New("walk needs a pointer to a struct")
 	}
+	val = val.Elem()
 	typ := val.Type()
 
 	for i := 0; i < typ.NumField(); i++ {
@@ -92,14 +96,13 @@ func bindEnvs(v *viper.Viper, iface any) error {
 		}
 		if tag == ",squash" {
 			// Recurse into embedded struct
-			err := bindEnvs(v, val.Field(i).Addr().Interface())
-			if err != nil {
+			if err := walk(val.Field(i).Addr().Interface(), fn); err != nil {
 				return err
 			}
 			continue
 		}
 
-		if err := v.BindEnv(tag); err != nil {
+		if err := fn(tag, val.Field(i)); err != nil {
 			return err
 		}
 	}
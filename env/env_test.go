@@ -1,6 +1,12 @@
 package env
 
-import "testing"
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestGetLogEnv(t *testing.T) {
 	logEnv := GetLogEnv()
@@ -11,3 +17,73 @@ func TestGetActorEnv(t *testing.T) {
 	actorEnv := GetActorEnv()
 	t.Logf("%+v", actorEnv)
 }
+
+func TestLoadFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yaml": "scrapeless_api_key: from-yaml\nSCRAPELESS_IS_ONLINE: true\nscrapeless_team_id: team\n",
+		"run.toml":  "SCRAPELESS_RUN_ID = \"run\"\nscrapeless_actor_id = \"actor\"\n",
+		"local.env": "SCRAPELESS_STORAGE_API_URL=http://localhost:8080\n",
+	}
+	var paths []string
+	for _, name := range []string{"base.yaml", "run.toml", "local.env"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	t.Setenv("SCRAPELESS_API_KEY", "from-env-1234")
+
+	cfg, err := Load(paths...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Actor.ApiKey != "from-env-1234" || !cfg.IsOnline || cfg.Actor.TeamId != "team" || cfg.Actor.RunId != "run" || cfg.Actor.ActorId != "actor" {
+		t.Fatalf("sources not merged: %#v", cfg)
+	}
+	if cfg.ScrapelessStorageUrl != "http://localhost:8080" || cfg.ScrapelessActorUrl != Default().ScrapelessActorUrl {
+		t.Fatalf("urls: %s %s", cfg.ScrapelessStorageUrl, cfg.ScrapelessActorUrl)
+	}
+	if cfg.Actor.DatasetId != "" {
+		t.Fatal("online configs must not get local storage ids")
+	}
+	if err = cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Fatal("missing file should fail")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.ScrapelessCrawlApiUrl = "not a url"
+	err := cfg.Validate()
+	for _, want := range []string{"team_Id", "apikey", "crawl_api_url"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("want %s reported, got %v", want, err)
+		}
+	}
+
+	cfg.normalize()
+	cfg.Actor.ApiKey = "key"
+	cfg.ScrapelessCrawlApiUrl = "https://crawl.example.com"
+	if err = cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	cfg := Default()
+	cfg.Actor.ApiKey = "sk_live_abcdefgh1234"
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if out := fmt.Sprintf(format, cfg); strings.Contains(out, "abcdefgh") {
+			t.Fatalf("%s leaks the key: %s", format, out)
+		}
+	}
+	if got := cfg.Actor.ApiKey.String(); got != "****1234" {
+		t.Fatalf("got %s", got)
+	}
+}
@@ -34,10 +34,10 @@ func main() {
 	request.Header.Set("HEADER KEY", "HEADER VALUE")
 	clientHttp := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(parse)}}
 	resp, err := clientHttp.Do(request)
-	defer resp.Body.Close()
 	if err != nil {
 		panic(err)
 	}
+	defer resp.Body.Close()
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		panic(err)
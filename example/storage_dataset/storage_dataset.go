@@ -4,6 +4,8 @@ import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/scrapeless"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+	"os"
 )
 
 func main() {
@@ -31,6 +33,20 @@ func main() {
 			return
 		}
 		log.Infof("%v", items)
+
+		f, err := os.Create("dataset.csv")
+		if err != nil {
+			log.Error(err.Error())
+			return
+		}
+		defer f.Close()
+		rows, err := client.Storage.Dataset.Export(context.Background(), "datasetId", storage.ExportCSV, f,
+			storage.WithFields("name", "age"))
+		if err != nil {
+			log.Error(err.Error())
+			return
+		}
+		log.Infof("exported %d rows", rows)
 	}
 
 }
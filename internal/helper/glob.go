@@ -0,0 +1,123 @@
+package helper
+
+import "strings"
+
+// GlobMatch reports whether s matches the Redis-style glob pattern: '*'
+// matches any run of characters, '?' a single character, '[abc]', '[a-z]'
+// and '[^a]' character classes, and '\' escapes the next character. Unlike
+// path.Match, '*' also matches '/' and ':'.
+func GlobMatch(pattern, s string) bool {
+	p, i := []rune(pattern), []rune(s)
+	starP, starI := -1, 0
+	pi, si := 0, 0
+	for si < len(i) {
+		if pi < len(p) {
+			switch p[pi] {
+			case '*':
+				starP, starI = pi, si
+				pi++
+				continue
+			case '?':
+				pi++
+				si++
+				continue
+			case '[':
+				if ok, next := matchClass(p, pi, i[si]); next > 0 {
+					if ok {
+						pi, si = next, si+1
+						continue
+					}
+					break
+				}
+				// An unterminated class is a literal '['.
+				if i[si] == '[' {
+					pi++
+					si++
+					continue
+				}
+			case '\\':
+				if pi+1 < len(p) {
+					if p[pi+1] == i[si] {
+						pi += 2
+						si++
+						continue
+					}
+					break
+				}
+				fallthrough
+			default:
+				if p[pi] == i[si] {
+					pi++
+					si++
+					continue
+				}
+			}
+		}
+		if starP < 0 {
+			return false
+		}
+		// Let the last '*' swallow one more character.
+		starI++
+		pi, si = starP+1, starI
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// matchClass matches c against the class starting at p[start] == '['. It
+// returns the index after the closing ']', or 0 when the class is not closed.
+func matchClass(p []rune, start int, c rune) (bool, int) {
+	i := start + 1
+	negate := i < len(p) && (p[i] == '^' || p[i] == '!')
+	if negate {
+		i++
+	}
+	matched := false
+	for first := true; i < len(p); first = false {
+		if p[i] == ']' && !first {
+			return matched != negate, i + 1
+		}
+		lo := p[i]
+		if lo == '\\' && i+1 < len(p) {
+			i++
+			lo = p[i]
+		}
+		hi := lo
+		if i+2 < len(p) && p[i+1] == '-' && p[i+2] != ']' {
+			hi = p[i+2]
+			if hi == '\\' && i+3 < len(p) {
+				i++
+				hi = p[i+2]
+			}
+			i += 2
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if lo <= c && c <= hi {
+			matched = true
+		}
+		i++
+	}
+	return false, 0
+}
+
+// GlobPrefix returns the literal prefix of a glob pattern, the part every
+// match starts with.
+func GlobPrefix(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?', '[':
+			return b.String()
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+		}
+		b.WriteByte(pattern[i])
+	}
+	return b.String()
+}
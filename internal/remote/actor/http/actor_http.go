@@ -20,6 +20,8 @@ func (c *Client) Run(ctx context.Context, req *models.IRunActorData) (string, er
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/runs", c.BaseUrl, req.ActorId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return "", err
@@ -34,6 +36,8 @@ func (c *Client) GetRunInfo(ctx context.Context, runId string) (*models.RunInfo,
 		Url:     fmt.Sprintf("%s/api/v1/actors/runs/%s", c.BaseUrl, runId),
 		Body:    "",
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("get runInfo err:%v", err)
@@ -53,6 +57,8 @@ func (c *Client) AbortRun(ctx context.Context, actorId, runId string) (bool, err
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/runs/%s", c.BaseUrl, actorId, runId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("abort run err:%v", err)
@@ -68,6 +74,8 @@ func (c *Client) Build(ctx context.Context, actorId string, version string) (str
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/builds", c.BaseUrl, actorId),
 		Body:    fmt.Sprintf(`{"version": "%s"}`, version),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("build err:%v", err)
@@ -83,6 +91,8 @@ func (c *Client) GetBuildStatus(ctx context.Context, actorId string, buildId str
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/builds/%s", c.BaseUrl, actorId, buildId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("get build status err:%v", err)
@@ -98,6 +108,8 @@ func (c *Client) AbortBuild(ctx context.Context, actorId string, buildId string)
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/actors/%s/builds/%s", c.BaseUrl, actorId, buildId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("abort build err:%v", err)
@@ -122,6 +134,8 @@ func (c *Client) GetRunList(ctx context.Context, paginationParams *models.IPagin
 		Method:  http.MethodGet,
 		Url:     parse.String(),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("get run list err:%v", err)
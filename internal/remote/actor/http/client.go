@@ -1,41 +1,22 @@
 package http
 
 import (
-	"github.com/scrapeless-ai/sdk-go/env"
-	log "github.com/sirupsen/logrus"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"net/http"
 )
 
-var (
-	defaultGatewayClient *Client
-)
-
-func Init(baseUrl ...string) {
-	log.Info("actor init")
-	var err error
-	u := env.Env.ScrapelessActorUrl
-	if len(baseUrl) > 0 {
-		u = baseUrl[0]
-	}
-	defaultGatewayClient, err = New(u)
-	if err != nil {
-		panic(err)
-	}
-}
-
 type Client struct {
 	client  *http.Client
 	BaseUrl string
+	ApiKey  string
 }
 
-func Default() *Client {
-	return defaultGatewayClient
-}
-
-func New(baseUrl string) (*Client, error) {
+func New(baseUrl string, cfg request2.ClientConfig) (*Client, error) {
+	cfg = request2.ClientConfigOf(cfg)
 	return &Client{
-		client:  &http.Client{},
+		client:  cfg.HTTPClient,
 		BaseUrl: baseUrl,
+		ApiKey:  cfg.ApiKey,
 	}, nil
 }
 
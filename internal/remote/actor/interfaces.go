@@ -4,6 +4,7 @@ import (
 	"context"
 	actor_http "github.com/scrapeless-ai/sdk-go/internal/remote/actor/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/actor/models"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
@@ -15,18 +16,23 @@ type Actor interface {
 	GetBuildStatus(ctx context.Context, actorId string, buildId string) (*models.BuildInfo, error)
 	AbortBuild(ctx context.Context, actorId string, buildId string) (bool, error)
 	GetRunList(ctx context.Context, paginationParams *models.IPaginationParams) ([]models.Payload, error)
+	Close() error
 }
 
-var ClientInterface Actor
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the actor backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Actor {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		actor_http.Init(baseUrl)
-		ClientInterface = actor_http.Default()
+		client, err := actor_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -31,6 +31,8 @@ func (c *Client) ScrapingBrowserCreate(ctx context.Context, req *models.CreateBr
 	request, err := request2.Request(ctx, request2.ReqInfo{
 		Method: http.MethodGet,
 		Url:    parse.String(),
+		ApiKey: c.ApiKey,
+		Client: c.client,
 	})
 	if err != nil {
 		return nil, err
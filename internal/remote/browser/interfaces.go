@@ -4,23 +4,29 @@ import (
 	"context"
 	browser_http "github.com/scrapeless-ai/sdk-go/internal/remote/browser/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/browser/models"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
 type Browser interface {
 	ScrapingBrowserCreate(ctx context.Context, req *models.CreateBrowserRequest) (*models.CreateBrowserResponse, error)
+	Close() error
 }
 
-var ClientInterface Browser
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the browser backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Browser {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		browser_http.Init(baseUrl)
-		ClientInterface = browser_http.Default()
+		client, err := browser_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
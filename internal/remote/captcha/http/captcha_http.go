@@ -6,10 +6,9 @@ import (
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha/models"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"net/http"
 	"time"
 )
@@ -33,6 +32,8 @@ func (c *Client) CaptchaSolverCreateTask(ctx context.Context, req *models.Create
 			"x-api-key": req.ApiKey,
 			"token":     req.ApiKey,
 		},
+		ApiKey: c.ApiKey,
+		Client: c.client,
 	})
 	if err != nil {
 		return "", err
@@ -40,7 +41,7 @@ func (c *Client) CaptchaSolverCreateTask(ctx context.Context, req *models.Create
 	taskId := gjson.Parse(body).Get("taskId").String()
 	if taskId == "" {
 		msg := gjson.Parse(body).Get("message").String()
-		return "", fmt.Errorf("create task err:%s", msg)
+		return "", scerrors.FromResponse(0, 0, "create task: "+msg, "")
 	}
 	return taskId, nil
 
@@ -54,13 +55,15 @@ func (c *Client) CaptchaSolverGetTaskResult(ctx context.Context, req *models.Get
 			"x-api-key": req.ApiKey,
 			"token":     req.ApiKey,
 		},
+		ApiKey: c.ApiKey,
+		Client: c.client,
 	})
 	if err != nil {
 		return nil, err
 	}
 	if ok := gjson.Parse(body).Get("success").Bool(); !ok {
 		log.Error(body)
-		return nil, fmt.Errorf("get task result err")
+		return nil, scerrors.FromResponse(0, 0, "get task result: "+gjson.Parse(body).Get("message").String(), "")
 	}
 	var solution map[string]any
 	solutionStr := gjson.Parse(body).Get("solution").String()
@@ -78,11 +81,11 @@ func (c *Client) CaptchaSolverSolverTask(ctx context.Context, req *models.Create
 	for {
 		select {
 		case <-ctx.Done():
-			return nil, status.Errorf(codes.DeadlineExceeded, ctx.Err().Error())
+			return nil, scerrors.From(ctx.Err())
 		case <-time.After(time.Second):
 			result, err := c.CaptchaSolverGetTaskResult(ctx, &models.GetTaskResultRequest{TaskId: task, ApiKey: req.ApiKey})
 			if err != nil {
-				return nil, status.Errorf(codes.Aborted, err.Error())
+				return nil, err
 			}
 			return result, nil
 		}
@@ -4,6 +4,7 @@ import (
 	"context"
 	captcha_http "github.com/scrapeless-ai/sdk-go/internal/remote/captcha/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha/models"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
@@ -11,18 +12,23 @@ type Captcha interface {
 	CaptchaSolverCreateTask(ctx context.Context, req *models.CreateTaskRequest) (string, error)
 	CaptchaSolverGetTaskResult(ctx context.Context, req *models.GetTaskResultRequest) (map[string]any, error)
 	CaptchaSolverSolverTask(ctx context.Context, req *models.CreateTaskRequest) (map[string]any, error)
+	Close() error
 }
 
-var ClientInterface Captcha
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the captcha backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Captcha {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		captcha_http.Init(baseUrl)
-		ClientInterface = captcha_http.Default()
+		client, err := captcha_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
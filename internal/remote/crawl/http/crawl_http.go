@@ -3,12 +3,13 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/crawl/models"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/tidwall/gjson"
 	"net/http"
+	"net/url"
 )
 
 // ScrapeUrl scrape a single url
@@ -20,6 +21,8 @@ func (c *Client) ScrapeUrl(ctx context.Context, req *models.ScrapeOptions) (id s
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape", c.BaseUrl),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	fmt.Println(response)
 	if err != nil {
@@ -28,7 +31,7 @@ func (c *Client) ScrapeUrl(ctx context.Context, req *models.ScrapeOptions) (id s
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return "", errors.New("server error")
+		return "", failure(data)
 	}
 	return data.Get("id").String(), nil
 }
@@ -40,6 +43,8 @@ func (c *Client) BatchScrapeUrls(ctx context.Context, req *models.ScrapeOptionsM
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape/batch", c.BaseUrl),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -47,7 +52,7 @@ func (c *Client) BatchScrapeUrls(ctx context.Context, req *models.ScrapeOptionsM
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return nil, errors.New("server error")
+		return nil, failure(data)
 	}
 	var invalidURLs = make([]string, 0)
 	id := data.Get("id").String()
@@ -66,6 +71,8 @@ func (c *Client) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatus
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape/%s", c.BaseUrl, id),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -73,7 +80,7 @@ func (c *Client) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatus
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return nil, errors.New("server error")
+		return nil, failure(data)
 	}
 	if err = json.Unmarshal([]byte(response), &scrapeStatusResponse); err != nil {
 		return nil, err
@@ -81,7 +88,7 @@ func (c *Client) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatus
 	if scrapeStatusResponse.Success {
 		return scrapeStatusResponse, nil
 	}
-	return nil, errors.New(scrapeStatusResponse.Error)
+	return nil, scerrors.FromResponse(0, 0, scrapeStatusResponse.Error, "")
 }
 
 func (c *Client) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeStatusResponseMultiple *models.ScrapeStatusResponseMultiple, err error) {
@@ -89,6 +96,8 @@ func (c *Client) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeS
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/scrape/batch/%s", c.BaseUrl, id),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -96,7 +105,7 @@ func (c *Client) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeS
 	data := gjson.Parse(response)
 	success := data.Get("success").Bool()
 	if !success {
-		return nil, errors.New("server error")
+		return nil, failure(data)
 	}
 	if err = json.Unmarshal([]byte(response), &scrapeStatusResponseMultiple); err != nil {
 		return nil, err
@@ -112,39 +121,80 @@ func (c *Client) CrawlUrl(ctx context.Context, req *models.CrawlParams) (id stri
 		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl", c.BaseUrl),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	fmt.Println(response)
 	if err != nil {
 		return "", err
 	}
-	success := gjson.Parse(response).Get("success").Bool()
-	if !success {
-		return "", errors.New("server error")
+	data := gjson.Parse(response)
+	if !data.Get("success").Bool() {
+		return "", failure(data)
 	}
-	return gjson.Parse(response).Get("id").String(), nil
+	return data.Get("id").String(), nil
 }
 
 func (c *Client) CheckCrawlStatus(ctx context.Context, id string) (crawlStatusResponse *models.CrawlStatusResponse, err error) {
+	return c.crawlStatus(ctx, fmt.Sprintf("%s/api/v1/crawler/crawl/%s", c.BaseUrl, id))
+}
+
+// CheckCrawlStatusNext fetches the page of crawl data that a previous status
+// response pointed to with its next field.
+func (c *Client) CheckCrawlStatusNext(ctx context.Context, next string) (crawlStatusResponse *models.CrawlStatusResponse, err error) {
+	nextUrl, err := c.resolve(next)
+	if err != nil {
+		return nil, err
+	}
+	return c.crawlStatus(ctx, nextUrl)
+}
+
+func (c *Client) crawlStatus(ctx context.Context, statusUrl string) (crawlStatusResponse *models.CrawlStatusResponse, err error) {
 	response, err := request2.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
-		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl/%s", c.BaseUrl, id),
+		Url:     statusUrl,
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
-	fmt.Println("body:", response)
 	if err != nil {
 		return nil, err
 	}
+	data := gjson.Parse(response)
+	if success := data.Get("success"); success.Exists() && !success.Bool() {
+		return nil, failure(data)
+	}
 	if err = json.Unmarshal([]byte(response), &crawlStatusResponse); err != nil {
 		return nil, err
 	}
 	return
 }
 
+// resolve turns a next link into an absolute URL on the crawl API. Links to
+// other hosts are refused so that the API key is never sent elsewhere.
+func (c *Client) resolve(next string) (string, error) {
+	base, err := url.Parse(c.BaseUrl)
+	if err != nil {
+		return "", scerrors.Newf(scerrors.KindInvalidArgument, "invalid crawl base url: %v", err)
+	}
+	ref, err := url.Parse(next)
+	if err != nil {
+		return "", scerrors.Newf(scerrors.KindInvalidArgument, "invalid next url %q: %v", next, err)
+	}
+	abs := base.ResolveReference(ref)
+	if abs.Host != base.Host {
+		return "", scerrors.Newf(scerrors.KindInvalidArgument, "next url %q is not on %s", next, base.Host)
+	}
+	return abs.String(), nil
+}
+
 func (c *Client) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *models.CrawlErrorsResponse, err error) {
 	response, err := request2.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl/%s/errors", c.BaseUrl, id),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -160,6 +210,8 @@ func (c *Client) CancelCrawl(ctx context.Context, id string) (errorResponse *mod
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/crawler/crawl/%s", c.BaseUrl, id),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -168,7 +220,16 @@ func (c *Client) CancelCrawl(ctx context.Context, id string) (errorResponse *mod
 		return nil, err
 	}
 	if errorResponse.Error != "" {
-		return nil, errors.New(errorResponse.Error)
+		return nil, scerrors.FromResponse(0, 0, errorResponse.Error, "")
 	}
 	return errorResponse, nil
 }
+
+// failure converts a response with "success": false into a typed error.
+func failure(data gjson.Result) error {
+	msg := data.Get("error").String()
+	if msg == "" {
+		msg = "server error"
+	}
+	return scerrors.FromResponse(0, 0, msg, "")
+}
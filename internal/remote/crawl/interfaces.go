@@ -4,30 +4,37 @@ import (
 	"context"
 	crawl_http "github.com/scrapeless-ai/sdk-go/internal/remote/crawl/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/crawl/models"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
-type Captcha interface {
+type Crawl interface {
 	ScrapeUrl(ctx context.Context, req *models.ScrapeOptions) (id string, err error)
 	BatchScrapeUrls(ctx context.Context, req *models.ScrapeOptionsMultiple) (scrapeResponse *models.ScrapeResponse, err error)
 	CheckScrapeStatus(ctx context.Context, id string) (scrapeStatusResponse *models.ScrapeStatusResponse, err error)
 	CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeStatusResponseMultiple *models.ScrapeStatusResponseMultiple, err error)
 	CrawlUrl(ctx context.Context, req *models.CrawlParams) (id string, err error)
 	CheckCrawlStatus(ctx context.Context, id string) (crawlStatusResponse *models.CrawlStatusResponse, err error)
+	CheckCrawlStatusNext(ctx context.Context, next string) (crawlStatusResponse *models.CrawlStatusResponse, err error)
 	CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *models.CrawlErrorsResponse, err error)
 	CancelCrawl(ctx context.Context, id string) (errorResponse *models.ErrorResponse, err error)
+	Close() error
 }
 
-var ClientInterface Captcha
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the crawl backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Crawl {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		crawl_http.Init(baseUrl)
-		ClientInterface = crawl_http.Default()
+		client, err := crawl_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -172,6 +172,7 @@ type CrawlStatusResponse struct {
 	Status    CrawlStatus             `json:"status"`
 	Completed int                     `json:"completed"`
 	Total     int                     `json:"total"`
+	Next      string                  `json:"next,omitempty"` // URL of the next page of data, empty on the last one
 	Data      []ScrapingCrawlDocument `json:"data"`
 }
 
@@ -4,24 +4,30 @@ import (
 	"context"
 	deepserp_http "github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/models"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
 type DeepSerp interface {
 	CreateTask(ctx context.Context, req *models.DeepserpTaskRequest) ([]byte, error)
 	GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error)
+	Close() error
 }
 
-var ClientInterface DeepSerp
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the deepserp backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) DeepSerp {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		deepserp_http.Init(baseUrl)
-		ClientInterface = deepserp_http.Default()
+		client, err := deepserp_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -57,7 +57,7 @@ func (c *Client) Upload(ctx context.Context, filePath, pluginName string) (exten
 		return nil, err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
-	req.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
+	req.Header.Set(env.Get().HTTPHeader, c.ApiKey)
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -130,7 +130,7 @@ func (c *Client) Update(ctx context.Context, extensionId, filePath, pluginName s
 		return false, err
 	}
 	request.Header.Set("Content-Type", writer.FormDataContentType())
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
+	request.Header.Set(env.Get().HTTPHeader, c.ApiKey)
 
 	resp, err := c.client.Do(request)
 	if err != nil {
@@ -154,6 +154,8 @@ func (c *Client) Get(ctx context.Context, extensionId string) (extensionDetail *
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/browser/extensions/%s", c.BaseUrl, extensionId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("get plugin result:%s", response)
 	if err != nil {
@@ -173,6 +175,8 @@ func (c *Client) List(ctx context.Context) (extensionList []models.ExtensionList
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/browser/extensions/list", c.BaseUrl),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("get plugin list result err:%v", err)
@@ -190,6 +194,8 @@ func (c *Client) Delete(ctx context.Context, extensionId string) (success bool,
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/browser/extensions/%s", c.BaseUrl, extensionId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("delete plugin result:%s", response)
 	if err != nil {
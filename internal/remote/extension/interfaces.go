@@ -4,6 +4,7 @@ import (
 	"context"
 	extension_http "github.com/scrapeless-ai/sdk-go/internal/remote/extension/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/extension/models"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
@@ -13,18 +14,23 @@ type Extension interface {
 	Get(ctx context.Context, extensionId string) (extensionDetail *models.ExtensionDetail, err error)
 	List(ctx context.Context) (extensionList []models.ExtensionListItem, err error)
 	Delete(ctx context.Context, extensionId string) (success bool, err error)
+	Close() error
 }
 
-var ClientInterface Extension
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the extension backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Extension {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		extension_http.Init(baseUrl)
-		ClientInterface = extension_http.Default()
+		client, err := extension_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
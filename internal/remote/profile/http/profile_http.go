@@ -3,10 +3,10 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile/models"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"net/http"
 	"net/url"
 )
@@ -21,6 +21,8 @@ func (c *Client) Create(ctx context.Context, name string) (profile *models.Profi
 		Url:     fmt.Sprintf("%s/browser/profiles", c.BaseUrl),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -37,12 +39,14 @@ func (c *Client) Get(ctx context.Context, profileId string) (profile *models.Pro
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/browser/profiles/%s", c.BaseUrl, profileId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
 	}
 	if response == "" {
-		return nil, errors.New("profile not found")
+		return nil, scerrors.New(scerrors.KindNotFound, "profile not found")
 	}
 	if err = json.Unmarshal([]byte(response), profile); err != nil {
 		return nil, err
@@ -70,6 +74,8 @@ func (c *Client) List(ctx context.Context, req *models.ListProfileRequest) (resp
 		Method:  http.MethodGet,
 		Url:     u.String(),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -88,6 +94,8 @@ func (c *Client) Delete(ctx context.Context, profileId string) (resp *models.Del
 		Url:     fmt.Sprintf("%s/browser/profiles/%s", c.BaseUrl, profileId),
 		Body:    "",
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -109,6 +117,8 @@ func (c *Client) Update(ctx context.Context, profileId string, name string) (res
 		Url:     fmt.Sprintf("%s/browser/profiles/%s", c.BaseUrl, profileId),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -4,6 +4,7 @@ import (
 	"context"
 	profile_http "github.com/scrapeless-ai/sdk-go/internal/remote/profile/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile/models"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
@@ -13,18 +14,23 @@ type Profile interface {
 	List(ctx context.Context, req *models.ListProfileRequest) (resp *models.ListProfileResponse, err error)
 	Update(ctx context.Context, profileId string, name string) (resp *models.UpdateProfileRequest, err error)
 	Delete(ctx context.Context, profileId string) (resp *models.DeleteProfileResponse, err error)
+	Close() error
 }
 
-var ClientInterface Profile
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the profile backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Profile {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		profile_http.Init(baseUrl)
-		ClientInterface = profile_http.Default()
+		client, err := profile_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -15,17 +15,17 @@ func (c *Client) ProxyGetProxy(ctx context.Context, req *models.GetProxyRequest)
 		return "", status.Errorf(codes.InvalidArgument, "api key is required")
 	}
 	if req.Country == "" {
-		req.Country = env.Env.ProxyCountry
+		req.Country = env.Get().ProxyCountry
 	}
-	if int64(req.SessionDuration) > env.Env.ProxySessionDurationMax {
-		req.SessionDuration = uint64(env.Env.ProxySessionDurationMax)
+	if int64(req.SessionDuration) > env.Get().ProxySessionDurationMax {
+		req.SessionDuration = uint64(env.Get().ProxySessionDurationMax)
 	}
 	if req.SessionId == "" {
 
 		req.SessionId = funk.RandomString(10)
 	}
 	if req.Gateway == "" {
-		req.Gateway = env.Env.ProxyGatewayHost
+		req.Gateway = env.Get().ProxyGatewayHost
 	}
 
 	proxyURL := fmt.Sprintf(
@@ -9,18 +9,23 @@ import (
 
 type Proxy interface {
 	ProxyGetProxy(ctx context.Context, req *models.GetProxyRequest) (string, error)
+	Close() error
 }
 
-var ClientInterface Proxy
-
-func NewClient(serverMode string) {
+// NewClient returns the proxy backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode string) Proxy {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		proxy_http.Init()
-		ClientInterface = proxy_http.Default()
+		client, err := proxy_http.New()
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -0,0 +1,64 @@
+package request
+
+import (
+	"net/http"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+)
+
+// ClientConfig holds what the remote backends of one SDK client need to reach
+// the Scrapeless APIs. Backends built from different configs share nothing,
+// so clients with different credentials can live in the same process.
+type ClientConfig struct {
+	ApiKey     string
+	HTTPClient *http.Client // Defaults to a client using the default transport
+
+	BaseApiUrl string
+	StorageUrl string
+	ActorUrl   string
+	BrowserUrl string
+	CrawlUrl   string
+}
+
+// DefaultClientConfig returns the configuration read from the environment.
+func DefaultClientConfig() ClientConfig {
+	return ClientConfigFrom(env.Get())
+}
+
+// ClientConfigFrom returns the client configuration described by cfg.
+func ClientConfigFrom(cfg *env.Config) ClientConfig {
+	return ClientConfig{
+		ApiKey:     string(cfg.Actor.ApiKey),
+		HTTPClient: defaultTransport.Client(),
+		BaseApiUrl: cfg.ScrapelessBaseApiUrl,
+		StorageUrl: cfg.ScrapelessStorageUrl,
+		ActorUrl:   cfg.ScrapelessActorUrl,
+		BrowserUrl: cfg.ScrapelessBrowserUrl,
+		CrawlUrl:   cfg.ScrapelessCrawlApiUrl,
+	}
+}
+
+// ClientConfigOf returns the first of cfgs with its empty fields taken from
+// the environment, or DefaultClientConfig when cfgs is empty.
+func ClientConfigOf(cfgs ...ClientConfig) ClientConfig {
+	def := DefaultClientConfig()
+	if len(cfgs) == 0 {
+		return def
+	}
+	cfg := cfgs[0]
+	fill := func(v *string, d string) {
+		if *v == "" {
+			*v = d
+		}
+	}
+	fill(&cfg.ApiKey, def.ApiKey)
+	fill(&cfg.BaseApiUrl, def.BaseApiUrl)
+	fill(&cfg.StorageUrl, def.StorageUrl)
+	fill(&cfg.ActorUrl, def.ActorUrl)
+	fill(&cfg.BrowserUrl, def.BrowserUrl)
+	fill(&cfg.CrawlUrl, def.CrawlUrl)
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = def.HTTPClient
+	}
+	return cfg
+}
@@ -3,27 +3,22 @@ package request
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/scrapeless-ai/sdk-go/env"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"io"
 	"net/http"
 	"strings"
 )
 
-var (
-	c *http.Client
-)
-
-func init() {
-	c = &http.Client{}
-}
-
 type ReqInfo struct {
 	Method  string `json:"method"`
 	Url     string `json:"url"`
 	Body    string `json:"body"`
 	Headers map[string]string
+
+	ApiKey string       `json:"-"` // Overrides the API key of the environment
+	Client *http.Client `json:"-"` // Overrides the client using the default transport
 }
 
 type RespInfo struct {
@@ -45,75 +40,103 @@ func (resp RespInfo) GetData() any {
 	return resp.Data
 }
 
-func Request(ctx context.Context, reqInfo ReqInfo) (string, error) {
+// AsError returns the typed error described by the envelope.
+func (resp RespInfo) AsError() error {
+	return scerrors.FromResponse(0, resp.Code, resp.Msg, "")
+}
+
+// Response is a raw HTTP response with its body already read.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Err returns a typed error when the status is 400 or above, nil otherwise.
+// Failures reported in the body envelope are left to the caller.
+func (r *Response) Err() error {
+	if r.StatusCode >= http.StatusBadRequest {
+		return scerrors.FromHTTP(r.StatusCode, r.Header, r.Body)
+	}
+	return nil
+}
+
+// Do sends the request and returns the full response, whatever its status.
+func Do(ctx context.Context, reqInfo ReqInfo) (*Response, error) {
 	request, err := http.NewRequestWithContext(ctx, reqInfo.Method, reqInfo.Url, strings.NewReader(reqInfo.Body))
 	if err != nil {
 		log.Error(err.Error())
-		return "", err
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "build request: %v", err)
 	}
 	for k, v := range reqInfo.Headers {
 		request.Header.Set(k, v)
 	}
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
-	if reqInfo.Body != "" {
+	apiKey := reqInfo.ApiKey
+	if apiKey == "" {
+		apiKey = string(env.GetActorEnv().ApiKey)
+	}
+	request.Header.Set(env.Get().HTTPHeader, apiKey)
+	if reqInfo.Body != "" && request.Header.Get("Content-Type") == "" {
 		if reqInfo.Body[0] == '[' || reqInfo.Body[0] == '{' {
 			request.Header.Set("Content-Type", "application/json")
 		} else {
 			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		}
 	}
-	do, err := c.Do(request)
+	client := reqInfo.Client
+	if client == nil {
+		client = defaultTransport.Client()
+	}
+	do, err := client.Do(request)
 	if err != nil {
 		log.Error(err.Error())
-		return "", err
+		return nil, scerrors.From(err)
 	}
+	defer do.Body.Close()
 	all, err := io.ReadAll(do.Body)
 	if err != nil {
 		log.Error(err.Error())
-		return "", err
+		return nil, scerrors.From(err)
 	}
-	defer do.Body.Close()
-	return string(all), nil
+	return &Response{
+		StatusCode: do.StatusCode,
+		Header:     do.Header,
+		Body:       all,
+	}, nil
 }
 
-// RequestData return request data
-func RequestData(ctx context.Context, reqInfo ReqInfo) ([]byte, error) {
-	request, err := http.NewRequestWithContext(ctx, reqInfo.Method, reqInfo.Url, strings.NewReader(reqInfo.Body))
+// Request sends the request and returns the response body. Failed responses
+// are reported as a typed error from the scrapeless/errors package.
+func Request(ctx context.Context, reqInfo ReqInfo) (string, error) {
+	resp, err := Do(ctx, reqInfo)
 	if err != nil {
-		log.Error(err.Error())
-		return nil, err
-	}
-	for k, v := range reqInfo.Headers {
-		request.Header.Set(k, v)
+		return "", err
 	}
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
-	if reqInfo.Body != "" {
-		if reqInfo.Body[0] == '[' || reqInfo.Body[0] == '{' {
-			request.Header.Set("Content-Type", "application/json")
-		} else {
-			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		}
+	if err = resp.Err(); err != nil {
+		return "", err
 	}
-	do, err := c.Do(request)
+	return string(resp.Body), nil
+}
+
+// RequestData return request data
+func RequestData(ctx context.Context, reqInfo ReqInfo) ([]byte, error) {
+	do, err := Do(ctx, reqInfo)
 	if err != nil {
-		log.Error(err.Error())
 		return nil, err
 	}
-	all, err := io.ReadAll(do.Body)
-	if err != nil {
-		log.Error(err.Error())
+	all := do.Body
+	log.Infof("request data :%s", string(all))
+	if err = do.Err(); err != nil {
 		return nil, err
 	}
-	defer do.Body.Close()
-	log.Infof("request data :%s", string(all))
 	var resp RespInfo
 	err = json.Unmarshal(all, &resp)
 	if err != nil {
 		log.Errorf("unmarshal resp error :%v", err)
-		return nil, err
+		return nil, scerrors.Newf(scerrors.KindInternal, "unmarshal response: %v", err)
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("resp err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	return json.Marshal(resp.Data)
 }
@@ -0,0 +1,64 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/poller"
+)
+
+func TestRequestTypedErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.Header().Set("X-Request-Id", "req-42")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"msg":"dataset not found","code":200005}`))
+		case "/envelope":
+			_, _ = w.Write([]byte(`{"err":true,"msg":"name already used","code":200006}`))
+		default:
+			_, _ = w.Write([]byte(`{"err":false,"data":{"id":"1"}}`))
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	_, err := Request(ctx, ReqInfo{Method: http.MethodGet, Url: srv.URL + "/missing"})
+	var apiErr *scerrors.Error
+	if !errors.As(err, &apiErr) || !errors.Is(err, scerrors.ErrNotFound) {
+		t.Fatalf("want not found error, got %v", err)
+	}
+	if apiErr.HTTPStatus != http.StatusNotFound || apiErr.Code != 200005 || apiErr.RequestID != "req-42" {
+		t.Fatalf("details lost: %+v", apiErr)
+	}
+
+	if _, err = RequestData(ctx, ReqInfo{Method: http.MethodGet, Url: srv.URL + "/envelope"}); !errors.Is(err, scerrors.ErrConflict) {
+		t.Fatalf("want conflict error, got %v", err)
+	}
+	data, err := RequestData(ctx, ReqInfo{Method: http.MethodGet, Url: srv.URL + "/ok"})
+	if err != nil || string(data) != `{"id":"1"}` {
+		t.Fatalf("got %s, %v", data, err)
+	}
+}
+
+func TestTaskResult(t *testing.T) {
+	if _, err := TaskResult(&Response{StatusCode: http.StatusAccepted}); !errors.Is(err, poller.ErrPending) {
+		t.Fatalf("202 should be pending, got %v", err)
+	}
+	_, err := TaskResult(&Response{StatusCode: http.StatusTooManyRequests})
+	if !errors.Is(err, scerrors.ErrRateLimited) || !scerrors.IsRetryable(err) || poller.IsTerminal(err) {
+		t.Fatalf("429 should be a transient rate limit, got %v", err)
+	}
+	_, err = TaskResult(&Response{StatusCode: http.StatusUnauthorized, Body: []byte(`{"message":"bad key"}`)})
+	if !errors.Is(err, scerrors.ErrUnauthorized) || !poller.IsTerminal(err) {
+		t.Fatalf("401 should be terminal, got %v", err)
+	}
+	body, err := TaskResult(&Response{StatusCode: http.StatusOK, Body: []byte(`{"taskId":"t","state":"success","data":1}`)})
+	if err != nil || len(body) == 0 {
+		t.Fatalf("finished task: %s, %v", body, err)
+	}
+}
@@ -0,0 +1,73 @@
+package request
+
+import (
+	"net/http"
+	"strings"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/poller"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	taskPendingStates = map[string]bool{
+		"pending": true, "created": true, "queued": true, "waiting": true,
+		"running": true, "processing": true, "in_progress": true, "active": true,
+	}
+	taskFailedStates = map[string]bool{
+		"failed": true, "error": true, "cancelled": true, "canceled": true, "timeout": true,
+	}
+)
+
+// TaskResult classifies the outcome of fetching an async task result so it
+// can drive a poller.Poll loop:
+//
+//   - 201/202, or a task envelope whose state is still running, is pending;
+//   - 408, 425, 429 and 5xx are transient;
+//   - any other 4xx, or a task envelope reporting failure, is terminal;
+//   - everything else is the finished result.
+func TaskResult(resp *Response) ([]byte, error) {
+	status, body := resp.StatusCode, resp.Body
+	switch {
+	case status == http.StatusCreated || status == http.StatusAccepted:
+		return nil, poller.Pending(taskState(body, http.StatusText(status)))
+	case status == http.StatusRequestTimeout || status == http.StatusTooEarly ||
+		status == http.StatusTooManyRequests || status >= http.StatusInternalServerError:
+		err := scerrors.FromHTTP(status, resp.Header, body)
+		err.Retryable = true
+		return nil, err
+	case status >= http.StatusBadRequest:
+		return nil, poller.Terminal(scerrors.FromHTTP(status, resp.Header, body))
+	}
+
+	// A finished task returns its payload as is; an unfinished one may still
+	// answer 200 with the task envelope, recognisable by its taskId.
+	result := gjson.ParseBytes(body)
+	if !result.Get("taskId").Exists() {
+		return body, nil
+	}
+	state := strings.ToLower(taskState(body, ""))
+	switch {
+	case taskPendingStates[state]:
+		return nil, poller.Pending(state)
+	case taskFailedStates[state]:
+		msg := result.Get("message").String()
+		if msg == "" {
+			msg = result.Get("error").String()
+		}
+		err := scerrors.Newf(scerrors.KindUnknown, "task %s %s: %s", result.Get("taskId").String(), state, msg)
+		err.RequestID = scerrors.RequestID(resp.Header)
+		return nil, poller.Terminal(err)
+	}
+	return body, nil
+}
+
+func taskState(body []byte, fallback string) string {
+	result := gjson.ParseBytes(body)
+	for _, key := range []string{"state", "status"} {
+		if v := result.Get(key); v.Type == gjson.String && v.String() != "" {
+			return v.String()
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,453 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+const (
+	defaultTimeout          = 3 * time.Minute
+	defaultMaxAttempts      = 3
+	defaultInitialBackoff   = 500 * time.Millisecond
+	defaultMaxBackoff       = 10 * time.Second
+	defaultBreakerThreshold = 10
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned while the circuit breaker of a host is open.
+// It matches scerrors.ErrUnavailable.
+var ErrCircuitOpen error = scerrors.New(scerrors.KindUnavailable, "circuit breaker is open")
+
+var defaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy describes how failed requests are retried.
+type RetryPolicy struct {
+	MaxAttempts        int           // Total attempts including the first one; 1 disables retries
+	InitialBackoff     time.Duration // Delay before the first retry, doubled after each one
+	MaxBackoff         time.Duration // Upper bound of the delay between retries
+	RetryStatuses      []int         // Status codes worth retrying; defaults to 429, 502, 503 and 504
+	RetryNonIdempotent bool          // Also retry POST/PATCH requests without an Idempotency-Key header
+}
+
+// EndpointPolicy overrides the retry policy for requests whose method and
+// URL path match. An empty Method matches every method.
+type EndpointPolicy struct {
+	Method     string
+	PathPrefix string
+	Retry      RetryPolicy
+}
+
+// RateLimit is a token bucket applied per API key. A zero Rate disables it.
+type RateLimit struct {
+	Rate  float64 // Requests per second
+	Burst int     // Bucket size; defaults to 1
+}
+
+// BreakerConfig opens the circuit of a host after FailureThreshold
+// consecutive failures and lets one probe through after Cooldown.
+// A negative FailureThreshold disables the breaker.
+type BreakerConfig struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+}
+
+// Config configures a Transport.
+type Config struct {
+	Base      http.RoundTripper // Underlying transport; defaults to a clone of http.DefaultTransport
+	Timeout   time.Duration     // Limit of a whole call, retries and reading the body included; of inactivity for streamed calls, see WithStreaming
+	Retry     RetryPolicy
+	Endpoints []EndpointPolicy // Checked in order, the first match wins
+	RateLimit RateLimit
+	Breaker   BreakerConfig
+}
+
+// DefaultConfig returns the configuration used when nothing is customized.
+func DefaultConfig() Config {
+	return Config{
+		Timeout: defaultTimeout,
+		Retry: RetryPolicy{
+			MaxAttempts:    defaultMaxAttempts,
+			InitialBackoff: defaultInitialBackoff,
+			MaxBackoff:     defaultMaxBackoff,
+		},
+		Breaker: BreakerConfig{
+			FailureThreshold: defaultBreakerThreshold,
+			Cooldown:         defaultBreakerCooldown,
+		},
+	}
+}
+
+// Transport is an http.RoundTripper adding retries, rate limiting and
+// circuit breaking on top of a base transport. Its configuration can be
+// swapped at any time with Configure; clients built from it pick up the
+// change on their next request.
+type Transport struct {
+	mu       sync.Mutex
+	cfg      Config
+	base     http.RoundTripper
+	limiters map[string]*tokenBucket
+	breakers map[string]*breaker
+}
+
+var defaultTransport = NewTransport(DefaultConfig())
+
+// Default returns the transport shared by every remote client.
+func Default() *Transport {
+	return defaultTransport
+}
+
+// Configure replaces the configuration of the default transport.
+func Configure(cfg Config) {
+	defaultTransport.Configure(cfg)
+}
+
+// NewTransport creates a transport from cfg.
+func NewTransport(cfg Config) *Transport {
+	t := &Transport{}
+	t.Configure(cfg)
+	return t
+}
+
+// Configure replaces the configuration and resets rate limiters and breakers.
+func (t *Transport) Configure(cfg Config) {
+	base := cfg.Base
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg = cfg
+	t.base = base
+	t.limiters = make(map[string]*tokenBucket)
+	t.breakers = make(map[string]*breaker)
+}
+
+// Config returns a copy of the current configuration.
+func (t *Transport) Config() Config {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg
+}
+
+// Client returns an http.Client sending its requests through t.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// CloseIdleConnections closes idle connections of the base transport.
+func (t *Transport) CloseIdleConnections() {
+	t.mu.Lock()
+	base := t.base
+	t.mu.Unlock()
+	if ci, ok := base.(interface{ CloseIdleConnections() }); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	cfg, base := t.cfg, t.base
+	limiter := t.limiter(req.Header.Get(env.Get().HTTPHeader))
+	brk := t.breaker(req.URL.Host)
+	t.mu.Unlock()
+
+	if cfg.Timeout > 0 && streaming(req.Context()) {
+		return t.roundTripStream(req, cfg, base, limiter, brk)
+	}
+	if cfg.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), cfg.Timeout)
+		resp, err := t.roundTrip(req.WithContext(ctx), cfg, base, limiter, brk)
+		if err != nil || resp.Body == nil {
+			cancel()
+			return resp, err
+		}
+		resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	}
+	return t.roundTrip(req, cfg, base, limiter, brk)
+}
+
+// roundTripStream bounds a streamed call by cfg.Timeout without any byte
+// sent or received rather than by its whole duration.
+func (t *Transport) roundTripStream(req *http.Request, cfg Config, base http.RoundTripper, limiter *tokenBucket, brk *breaker) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	idle := &idleTimer{timeout: cfg.Timeout}
+	idle.timer = time.AfterFunc(cfg.Timeout, func() {
+		idle.fired.Store(true)
+		cancel()
+	})
+	stop := func() {
+		idle.timer.Stop()
+		cancel()
+	}
+	req = req.WithContext(ctx)
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = &idleBody{ReadCloser: req.Body, idle: idle}
+		if getBody := req.GetBody; getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, err := getBody()
+				if err != nil {
+					return nil, err
+				}
+				return &idleBody{ReadCloser: body, idle: idle}, nil
+			}
+		}
+	}
+	resp, err := t.roundTrip(req, cfg, base, limiter, brk)
+	if err != nil || resp.Body == nil {
+		stop()
+		return resp, idle.err(err)
+	}
+	idle.timer.Reset(cfg.Timeout)
+	resp.Body = &cancelBody{ReadCloser: &idleBody{ReadCloser: resp.Body, idle: idle}, cancel: stop}
+	return resp, nil
+}
+
+func (t *Transport) roundTrip(req *http.Request, cfg Config, base http.RoundTripper, limiter *tokenBucket, brk *breaker) (*http.Response, error) {
+	ctx := req.Context()
+	policy := retryPolicy(cfg, req)
+	retryable := policy.RetryNonIdempotent || isIdempotent(req)
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if brk != nil && !brk.allow() {
+			return nil, ErrCircuitOpen
+		}
+		resp, err := base.RoundTrip(attemptReq)
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		switch {
+		case brk == nil:
+		case ctx.Err() != nil:
+			// Canceled by the caller or out of time: the host is not to blame.
+			brk.release()
+		default:
+			brk.record(!failed)
+		}
+		if ctx.Err() != nil || attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			if !retryable || req.Body != nil && req.GetBody == nil {
+				return resp, err
+			}
+			wait = backoff(policy, attempt)
+		case slices.Contains(policy.RetryStatuses, resp.StatusCode):
+			// A 429 means the request was rejected before being handled, so
+			// it is safe to repeat even when the method is not idempotent.
+			if !retryable && resp.StatusCode != http.StatusTooManyRequests || req.Body != nil && req.GetBody == nil {
+				return resp, nil
+			}
+			wait = backoff(policy, attempt)
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = after
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+				return resp, nil
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			_ = resp.Body.Close()
+		default:
+			return resp, nil
+		}
+
+		log.Warnf("retrying %s %s in %s (attempt %d/%d)", req.Method, req.URL.Path, wait, attempt+1, policy.MaxAttempts)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// cancelBody releases the per call timeout once the body has been consumed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+type streamingKey struct{}
+
+// WithStreaming marks the requests made with the returned context as
+// streamed transfers: the Timeout of the transport then limits the time
+// without any byte sent or received instead of the whole call, so that
+// large uploads and downloads are not cut while they make progress.
+func WithStreaming(ctx context.Context) context.Context {
+	return context.WithValue(ctx, streamingKey{}, true)
+}
+
+func streaming(ctx context.Context) bool {
+	on, _ := ctx.Value(streamingKey{}).(bool)
+	return on
+}
+
+// idleTimer cancels a streamed call once no byte moved for timeout.
+type idleTimer struct {
+	timer   *time.Timer
+	timeout time.Duration
+	fired   atomic.Bool
+}
+
+// err reports the cancellation of the call by the timer as a timeout.
+func (i *idleTimer) err(err error) error {
+	if err != nil && i.fired.Load() && errors.Is(err, context.Canceled) {
+		return fmt.Errorf("no data transferred for %s: %w", i.timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// idleBody restarts the idle timer whenever bytes are read.
+type idleBody struct {
+	io.ReadCloser
+	idle *idleTimer
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.idle.timer.Reset(b.idle.timeout)
+	}
+	return n, b.idle.err(err)
+}
+
+// limiter must be called with t.mu held.
+func (t *Transport) limiter(apiKey string) *tokenBucket {
+	if t.cfg.RateLimit.Rate <= 0 {
+		return nil
+	}
+	l, ok := t.limiters[apiKey]
+	if !ok {
+		l = newTokenBucket(t.cfg.RateLimit.Rate, t.cfg.RateLimit.Burst)
+		t.limiters[apiKey] = l
+	}
+	return l
+}
+
+// breaker must be called with t.mu held.
+func (t *Transport) breaker(host string) *breaker {
+	if t.cfg.Breaker.FailureThreshold < 0 {
+		return nil
+	}
+	b, ok := t.breakers[host]
+	if !ok {
+		threshold, cooldown := t.cfg.Breaker.FailureThreshold, t.cfg.Breaker.Cooldown
+		if threshold == 0 {
+			threshold = defaultBreakerThreshold
+		}
+		if cooldown <= 0 {
+			cooldown = defaultBreakerCooldown
+		}
+		b = &breaker{threshold: threshold, cooldown: cooldown}
+		t.breakers[host] = b
+	}
+	return b
+}
+
+func retryPolicy(cfg Config, req *http.Request) RetryPolicy {
+	policy := cfg.Retry
+	for _, ep := range cfg.Endpoints {
+		if ep.Method != "" && !strings.EqualFold(ep.Method, req.Method) {
+			continue
+		}
+		if strings.HasPrefix(req.URL.Path, ep.PathPrefix) {
+			policy = ep.Retry
+			break
+		}
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultInitialBackoff
+	}
+	if policy.MaxBackoff < policy.InitialBackoff {
+		policy.MaxBackoff = max(defaultMaxBackoff, policy.InitialBackoff)
+	}
+	if policy.RetryStatuses == nil {
+		policy.RetryStatuses = defaultRetryStatuses
+	}
+	return policy
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// rewind returns the request to send for the given attempt, with a fresh
+// body for every retry.
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// backoff returns the full-jitter exponential delay before the next attempt.
+func backoff(policy RetryPolicy, attempt int) time.Duration {
+	d := policy.InitialBackoff << (attempt - 1)
+	if d <= 0 || d > policy.MaxBackoff {
+		d = policy.MaxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
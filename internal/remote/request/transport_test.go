@@ -0,0 +1,256 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetry(attempts int) RetryPolicy {
+	return RetryPolicy{MaxAttempts: attempts, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+}
+
+// flakyServer fails the first n requests with status, then answers 200.
+func flakyServer(t *testing.T, n int32, status int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) <= n {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestTransportRetriesIdempotent(t *testing.T) {
+	srv, calls := flakyServer(t, 2, http.StatusServiceUnavailable, nil)
+	client := NewTransport(Config{Retry: fastRetry(3)}).Client()
+
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestTransportSkipsNonIdempotent(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusServiceUnavailable, nil)
+	client := NewTransport(Config{Retry: fastRetry(3)}).Client()
+
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("payload"))
+	req.Header.Set("Idempotency-Key", "abc")
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "payload" {
+		t.Fatalf("retried body was not rewound: %q", body)
+	}
+}
+
+func TestTransportRetryAfter(t *testing.T) {
+	srv, calls := flakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	client := NewTransport(Config{Retry: fastRetry(2)}).Client()
+
+	start := time.Now()
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Fatalf("status %d after %d calls", resp.StatusCode, calls.Load())
+	}
+	if time.Since(start) < time.Second {
+		t.Fatal("Retry-After was not honoured")
+	}
+}
+
+func TestTransportEndpointPolicy(t *testing.T) {
+	srv, calls := flakyServer(t, 5, http.StatusBadGateway, nil)
+	client := NewTransport(Config{
+		Retry:     fastRetry(5),
+		Endpoints: []EndpointPolicy{{Method: http.MethodGet, PathPrefix: "/once", Retry: fastRetry(1)}},
+	}).Client()
+
+	resp, err := client.Get(srv.URL + "/once/path")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if calls.Load() != 1 {
+		t.Fatalf("endpoint policy ignored, %d calls", calls.Load())
+	}
+}
+
+// slowReader yields n bytes, one every delay.
+type slowReader struct {
+	n     int
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	r.n--
+	p[0] = 'x'
+	return 1, nil
+}
+
+func TestTransportStreamingTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		pause := 20 * time.Millisecond
+		if r.URL.Path == "/stall" {
+			pause = 200 * time.Millisecond
+		}
+		for i := 0; i < 6 && r.Context().Err() == nil; i++ {
+			_, _ = w.Write([]byte{'x'})
+			w.(http.Flusher).Flush()
+			time.Sleep(pause)
+		}
+		_, _ = fmt.Fprint(w, n)
+	}))
+	t.Cleanup(srv.Close)
+	client := NewTransport(Config{Timeout: 80 * time.Millisecond, Retry: fastRetry(1)}).Client()
+	call := func(ctx context.Context, path string) (string, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL+path, &slowReader{n: 6, delay: 20 * time.Millisecond})
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	// Uploading and downloading take about 240ms each, past the timeout,
+	// but bytes keep moving.
+	body, err := call(WithStreaming(context.Background()), "/")
+	if err != nil || body != "xxxxxx6" {
+		t.Fatalf("streamed call: %q, %v", body, err)
+	}
+	if _, err = call(context.Background(), "/"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("plain call outlived the timeout: %v", err)
+	}
+	if _, err = call(WithStreaming(context.Background()), "/stall"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("stalled stream: %v", err)
+	}
+}
+
+func TestTransportCircuitBreaker(t *testing.T) {
+	srv, calls := flakyServer(t, 100, http.StatusInternalServerError, nil)
+	client := NewTransport(Config{
+		Retry:   fastRetry(1),
+		Breaker: BreakerConfig{FailureThreshold: 2, Cooldown: time.Hour},
+	}).Client()
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(srv.URL); err == nil || !strings.Contains(err.Error(), ErrCircuitOpen.Error()) {
+		t.Fatalf("want open circuit, got %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("open circuit still reached the server: %d calls", calls.Load())
+	}
+}
+
+func TestTransportBreakerIgnoresCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			<-r.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	client := NewTransport(Config{
+		Retry:   fastRetry(1),
+		Breaker: BreakerConfig{FailureThreshold: 2, Cooldown: time.Hour},
+	}).Client()
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/slow", nil)
+		if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("abandoned call: %v", err)
+		}
+		cancel()
+	}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("abandoned calls opened the circuit: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestTransportRateLimit(t *testing.T) {
+	srv, _ := flakyServer(t, 0, http.StatusOK, nil)
+	client := NewTransport(Config{RateLimit: RateLimit{Rate: 20, Burst: 1}}).Client()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("rate limit not applied, 3 requests took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("canceled request should fail")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if d, ok := retryAfter("3"); !ok || d != 3*time.Second {
+		t.Fatalf("seconds: %v %v", d, ok)
+	}
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if d, ok := retryAfter(date); !ok || d <= 0 || d > time.Minute {
+		t.Fatalf("date: %v %v", d, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Fatal("invalid value should be ignored")
+	}
+}
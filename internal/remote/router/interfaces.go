@@ -1,6 +1,7 @@
 package router
 
 import (
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	router_http "github.com/scrapeless-ai/sdk-go/internal/remote/router/http"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"io"
@@ -8,18 +9,23 @@ import (
 
 type Router interface {
 	Request(keyword string, method string, path string, body io.Reader, headers map[string]string) (data []byte, err error)
+	Close() error
 }
 
-var ClientInterface Router
-
-func NewClient(serverMode string) {
+// NewClient returns the router backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Router {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		router_http.Init()
-		ClientInterface = router_http.Default()
+		client, err := router_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -17,6 +17,8 @@ func (c *Client) Scrape(ctx context.Context, req *models.ScrapingRequest) ([]byt
 		Url:     fmt.Sprintf("%s/scraping", c.BaseUrl),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -31,6 +33,8 @@ func (c *Client) CreateTask(ctx context.Context, req *models.ScrapingTaskRequest
 		Url:     fmt.Sprintf("%s/api/v1/scraper/request", c.BaseUrl),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -39,15 +43,17 @@ func (c *Client) CreateTask(ctx context.Context, req *models.ScrapingTaskRequest
 }
 
 func (c *Client) GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := request2.Do(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/result/%s", c.BaseUrl, taskIKd),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
-	log.Infof("get task result:%s", response)
 	if err != nil {
 		log.Errorf("get task result err:%v", err)
 		return nil, err
 	}
-	return []byte(response), nil
+	log.Infof("get task result:%s", response.Body)
+	return request2.TaskResult(response)
 }
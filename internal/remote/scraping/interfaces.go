@@ -2,6 +2,7 @@ package scraping
 
 import (
 	"context"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	scraping_http "github.com/scrapeless-ai/sdk-go/internal/remote/scraping/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/scraping/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
@@ -11,18 +12,23 @@ type Scraping interface {
 	Scrape(ctx context.Context, req *models.ScrapingRequest) ([]byte, error)
 	CreateTask(ctx context.Context, req *models.ScrapingTaskRequest) ([]byte, error)
 	GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error)
+	Close() error
 }
 
-var ClientInterface Scraping
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the scraping backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Scraping {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		scraping_http.Init(baseUrl)
-		ClientInterface = scraping_http.Default()
+		client, err := scraping_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
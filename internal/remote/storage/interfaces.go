@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/env"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_memory"
@@ -16,6 +17,7 @@ type Dataset interface {
 	DelDataset(ctx context.Context, datasetID string) (bool, error)
 	GetDataset(ctx context.Context, req *models.GetDataset) (*models.DatasetItem, error)
 	AddDatasetItem(ctx context.Context, datasetId string, data []map[string]any) (bool, error)
+	QueryDataset(ctx context.Context, req *models.QueryDataset) (*models.QueryDatasetResult, error)
 	Close() error
 }
 
@@ -27,10 +29,15 @@ type KV interface {
 	RenameNamespace(ctx context.Context, namespaceId string, name string) (bool, error)
 	SetValue(ctx context.Context, req *models.SetValue) (bool, error)
 	ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models.KvKeys, error)
+	ScanKeys(ctx context.Context, req *models.ScanKeys) (*models.ScanKeysResult, error)
 	GetValue(ctx context.Context, namespaceId string, key string) (string, error)
 	DelValue(ctx context.Context, namespaceId string, key string) (bool, error)
 	BulkSetValue(ctx context.Context, req *models.BulkSet) (int64, error)
 	BulkDelValue(ctx context.Context, namespaceId string, keys []string) (bool, error)
+	CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error)
+	Increment(ctx context.Context, req *models.Increment) (int64, error)
+	SetNX(ctx context.Context, req *models.SetValue) (bool, error)
+	GetAndDelete(ctx context.Context, namespaceId string, key string) (string, error)
 	Close() error
 }
 
@@ -55,6 +62,13 @@ type Object interface {
 	GetObject(ctx context.Context, req *models.ObjectRequest) ([]byte, error)
 	DeleteObject(ctx context.Context, req *models.ObjectRequest) (bool, error)
 	PutObject(ctx context.Context, req *models.PutObjectRequest) (string, error)
+	PutObjectStream(ctx context.Context, req *models.PutObjectStreamRequest) (string, error)
+	GetObjectStream(ctx context.Context, req *models.GetObjectStreamRequest) (*models.ObjectStream, error)
+	CreateUpload(ctx context.Context, req *models.CreateUploadRequest) (string, error)
+	UploadPart(ctx context.Context, req *models.UploadPartRequest) (*models.UploadedPart, error)
+	ListParts(ctx context.Context, bucketId string, uploadId string) ([]models.UploadedPart, error)
+	CompleteUpload(ctx context.Context, req *models.CompleteUploadRequest) (string, error)
+	AbortUpload(ctx context.Context, bucketId string, uploadId string) error
 	Close() error
 }
 
@@ -81,10 +95,10 @@ type Storage interface {
 	Vector
 }
 
-var ClientInterface Storage
-
-func NewClient(serverMode, baseUrl string) {
-	if !env.Env.IsOnline {
+// NewClient returns the storage backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Storage {
+	if !env.Get().IsOnline {
 		serverMode = "dev"
 	}
 	switch serverMode {
@@ -92,10 +106,13 @@ func NewClient(serverMode, baseUrl string) {
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
-		storage_memory.Init()
-		ClientInterface = storage_memory.Default()
+		return storage_memory.New()
 	default:
-		storage_http.Init(baseUrl)
-		ClientInterface = storage_http.Default()
+		client, err := storage_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"io"
 	"time"
 )
 
@@ -59,6 +60,37 @@ type DatasetItem struct {
 	PageSize  int              `json:"pageSize"`
 }
 
+// QueryDataset filters, projects and aggregates the items of a dataset.
+// Paths use the gjson syntax.
+type QueryDataset struct {
+	DatasetId    string               `json:"datasetId"`
+	Filters      []DatasetFilter      `json:"filters,omitempty"` // All of them must hold
+	Fields       []string             `json:"fields,omitempty"`  // Paths kept in the results, every field when empty
+	Distinct     bool                 `json:"distinct,omitempty"`
+	GroupBy      []string             `json:"groupBy,omitempty"`
+	Aggregations []DatasetAggregation `json:"aggregations,omitempty"`
+	Desc         bool                 `json:"desc,omitempty"`
+	Offset       int                  `json:"offset,omitempty"`
+	Limit        int                  `json:"limit,omitempty"` // No limit when 0
+}
+
+type DatasetFilter struct {
+	Path  string `json:"path"`
+	Op    string `json:"op"` // eq, ne, gt, gte, lt, lte, in, regex or exists
+	Value any    `json:"value,omitempty"`
+}
+
+type DatasetAggregation struct {
+	Op   string `json:"op"`             // count, sum, min, max or avg
+	Path string `json:"path,omitempty"` // Counted values, every item when empty
+	As   string `json:"as,omitempty"`   // Name of the result, op or op(path) when empty
+}
+
+type QueryDatasetResult struct {
+	Items   []map[string]any `json:"items"`
+	Scanned int              `json:"scanned"` // Items read to answer the query
+}
+
 // Queue
 
 type GetQueueRequest struct {
@@ -198,10 +230,13 @@ type CreateBucketRequest struct {
 }
 
 type ListObjectsRequest struct {
-	BucketId string `json:"bucketId,omitempty"`
-	Search   string `json:"search,omitempty"`
-	Page     int64  `json:"page,omitempty"`
-	PageSize int64  `json:"pageSize,omitempty"`
+	BucketId    string            `json:"bucketId,omitempty"`
+	Search      string            `json:"search,omitempty"`
+	ContentType string            `json:"contentType,omitempty"` // Prefix of the content type, like "image/"
+	Metadata    map[string]string `json:"metadata,omitempty"`    // Entries the objects must all have
+	Tags        []string          `json:"tags,omitempty"`        // Tags the objects must all have
+	Page        int64             `json:"page,omitempty"`
+	PageSize    int64             `json:"pageSize,omitempty"`
 }
 
 type ObjectList struct {
@@ -221,8 +256,18 @@ type BucketObject struct {
 	ActorId   string `json:"actorId"`
 	RunId     string `json:"runId"`
 	FileType  string `json:"fileType"`
+	MD5       string `json:"md5,omitempty"`    // Hex digest of the content, when known
+	SHA256    string `json:"sha256,omitempty"` // Hex digest of the content, when known
 	CreatedAt string `json:"createdAt"`
 	UpdatedAt string `json:"updatedAt"`
+	ObjectAttributes
+}
+
+// ObjectAttributes are the attributes set by the uploader of an object.
+type ObjectAttributes struct {
+	ContentType string            `json:"contentType,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
 }
 
 type ObjectRequest struct {
@@ -236,6 +281,67 @@ type PutObjectRequest struct {
 	Data     []byte `json:"data,omitempty"`
 	ActorId  string `json:"actorId,omitempty"`
 	RunId    string `json:"runId,omitempty"`
+	ObjectAttributes
+}
+
+// PutObjectStreamRequest uploads the content of Body in a single request.
+type PutObjectStreamRequest struct {
+	BucketId string    `json:"bucketId,omitempty"`
+	Filename string    `json:"filename,omitempty"`
+	Body     io.Reader `json:"-"`
+	Size     int64     `json:"size"` // -1 when unknown
+	ActorId  string    `json:"actorId,omitempty"`
+	RunId    string    `json:"runId,omitempty"`
+	ObjectAttributes
+}
+
+// GetObjectStreamRequest reads Length bytes of an object from Offset.
+type GetObjectStreamRequest struct {
+	BucketId string `json:"bucketId,omitempty"`
+	ObjectId string `json:"objectId,omitempty"`
+	Offset   int64  `json:"offset"`
+	Length   int64  `json:"length"` // Up to the end when 0
+}
+
+// ObjectStream is the content of an object, or of a range of it.
+type ObjectStream struct {
+	Body      io.ReadCloser
+	Size      int64 // Bytes in Body, -1 when unknown
+	TotalSize int64 // Size of the whole object, -1 when unknown
+}
+
+// CreateUploadRequest starts a multipart upload.
+type CreateUploadRequest struct {
+	BucketId string `json:"bucketId,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	Size     int64  `json:"size"` // -1 when unknown
+	ActorId  string `json:"actorId,omitempty"`
+	RunId    string `json:"runId,omitempty"`
+	ObjectAttributes
+}
+
+type UploadPartRequest struct {
+	BucketId string `json:"bucketId,omitempty"`
+	UploadId string `json:"uploadId,omitempty"`
+	Number   int    `json:"number"` // From 1
+	Data     []byte `json:"-"`
+	MD5      string `json:"md5"` // Hex digest of Data
+}
+
+type UploadedPart struct {
+	Number int    `json:"number"`
+	Size   int64  `json:"size"`
+	MD5    string `json:"md5"`
+}
+
+// CompleteUploadRequest assembles the parts of an upload into an object.
+// The digests of the whole content are checked when set.
+type CompleteUploadRequest struct {
+	BucketId string         `json:"bucketId,omitempty"`
+	UploadId string         `json:"uploadId,omitempty"`
+	Parts    []UploadedPart `json:"parts"`
+	MD5      string         `json:"md5,omitempty"`
+	SHA256   string         `json:"sha256,omitempty"`
 }
 
 type KvNamespace struct {
@@ -269,6 +375,23 @@ type SetValue struct {
 	Expiration  uint   `json:"expiration"`
 }
 
+// CompareAndSwap replaces the value of Key with New if it currently holds Old.
+type CompareAndSwap struct {
+	NamespaceId string `json:"namespaceId"`
+	Key         string `json:"key"`
+	Old         string `json:"old"`
+	New         string `json:"new"`
+	Expiration  uint   `json:"expiration"`
+}
+
+// Increment adds Delta to the integer held by Key, a missing key counting as 0.
+type Increment struct {
+	NamespaceId string `json:"namespaceId"`
+	Key         string `json:"key"`
+	Delta       int64  `json:"delta"`
+	Expiration  uint   `json:"expiration"`
+}
+
 type SetValueLocal struct {
 	SetValue
 	Size     int       `json:"size"`
@@ -289,6 +412,26 @@ type KvKeys struct {
 	TotalPage int64            `json:"totalPage"`
 }
 
+// ScanKeys asks for the keys matching a glob pattern, resuming after Cursor.
+type ScanKeys struct {
+	NamespaceId string `json:"namespaceId"`
+	Match       string `json:"match"`  // Glob pattern, empty matches every key
+	Cursor      string `json:"cursor"` // Empty to start a scan
+	Count       int64  `json:"count"`  // Hint of the number of keys to return
+}
+
+type KeyInfo struct {
+	Key      string    `json:"key"`
+	Size     int64     `json:"size"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+// ScanKeysResult is a batch of a scan. An empty Cursor ends the scan.
+type ScanKeysResult struct {
+	Items  []KeyInfo `json:"items"`
+	Cursor string    `json:"cursor"`
+}
+
 type BulkSet struct {
 	NamespaceId string     `json:"namespaceId"`
 	Items       []BulkItem `json:"items"`
@@ -325,6 +468,7 @@ type Doc struct {
 	Content      string             `json:"content"`
 	SparseVector map[string]float64 `json:"sparseVector"`
 	Score        float64            `json:"score"`
+	Metadata     map[string]any     `json:"metadata,omitempty"`
 }
 
 type ListCollectionsRequest struct {
@@ -347,6 +491,7 @@ type CreateCollectionRequest struct {
 	ActorId     string `json:"actorId"`
 	Description string `json:"description"`
 	Dimension   int    `json:"dimension"`
+	Metric      string `json:"metric,omitempty"`
 	Name        string `json:"name"`
 	RunId       string `json:"runId"`
 }
@@ -393,6 +538,18 @@ type QueryVectorRequest struct {
 	Topk           int32              `json:"topk"`
 	IncludeVector  bool               `json:"includeVector"`
 	IncludeContent bool               `json:"includeContent"`
+	Filter         *MetadataFilter    `json:"filter,omitempty"` // Condition on the metadata of the docs
+}
+
+// MetadataFilter is a condition on the metadata of vector docs. A leaf tests
+// the value at Field, a gjson path, with Op; otherwise And or Or combine
+// nested filters.
+type MetadataFilter struct {
+	Field string           `json:"field,omitempty"`
+	Op    string           `json:"op,omitempty"` // eq, ne, gt, gte, lt, lte, in, regex or exists
+	Value any              `json:"value,omitempty"`
+	And   []MetadataFilter `json:"and,omitempty"` // All of them must hold
+	Or    []MetadataFilter `json:"or,omitempty"`  // One of them must hold
 }
 
 type QueryDocsByIdsRequest DeleteDocsRequest
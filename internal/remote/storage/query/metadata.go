@@ -0,0 +1,99 @@
+package query
+
+import (
+	"encoding/json"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/tidwall/gjson"
+)
+
+// maxFilterDepth bounds the nesting of And and Or.
+const maxFilterDepth = 32
+
+// Metadata is a compiled metadata filter.
+type Metadata struct {
+	leaf *filter
+	and  []*Metadata
+	or   []*Metadata
+}
+
+// NewMetadata checks and compiles f. A node is either a leaf, with an Op, or
+// a combination, with And or Or, never both.
+func NewMetadata(f *models.MetadataFilter) (*Metadata, error) {
+	return compileMetadata(f, 0)
+}
+
+func compileMetadata(f *models.MetadataFilter, depth int) (*Metadata, error) {
+	if depth > maxFilterDepth {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "metadata filter nested deeper than %d", maxFilterDepth)
+	}
+	parts := 0
+	for _, set := range []bool{f.Op != "", len(f.And) > 0, len(f.Or) > 0} {
+		if set {
+			parts++
+		}
+	}
+	if parts != 1 {
+		return nil, scerrors.New(scerrors.KindInvalidArgument, "a metadata filter needs exactly one of op, and, or")
+	}
+	m := &Metadata{}
+	switch {
+	case f.Op != "":
+		if f.Field == "" {
+			return nil, scerrors.Newf(scerrors.KindInvalidArgument, "metadata filter %s needs a field", f.Op)
+		}
+		c, err := compileFilter(models.DatasetFilter{Path: f.Field, Op: f.Op, Value: f.Value})
+		if err != nil {
+			return nil, err
+		}
+		m.leaf = &c
+	case len(f.And) > 0:
+		for i := range f.And {
+			sub, err := compileMetadata(&f.And[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m.and = append(m.and, sub)
+		}
+	default:
+		for i := range f.Or {
+			sub, err := compileMetadata(&f.Or[i], depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m.or = append(m.or, sub)
+		}
+	}
+	return m, nil
+}
+
+// Match reports whether metadata satisfies the filter.
+func (m *Metadata) Match(metadata map[string]any) bool {
+	doc, err := json.Marshal(metadata)
+	if err != nil {
+		return false
+	}
+	return m.match(doc)
+}
+
+func (m *Metadata) match(doc []byte) bool {
+	switch {
+	case m.leaf != nil:
+		return m.leaf.match(gjson.GetBytes(doc, m.leaf.Path))
+	case m.and != nil:
+		for _, sub := range m.and {
+			if !sub.match(doc) {
+				return false
+			}
+		}
+		return true
+	default:
+		for _, sub := range m.or {
+			if sub.match(doc) {
+				return true
+			}
+		}
+		return false
+	}
+}
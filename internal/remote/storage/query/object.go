@@ -0,0 +1,64 @@
+package query
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+// MatchObject reports whether the object passes the search and filters of
+// req: its filename contains the search, case insensitively, its content
+// type starts with the one of req, and it has every metadata entry and tag
+// of req.
+func MatchObject(object *models.BucketObject, req *models.ListObjectsRequest) bool {
+	if !strings.Contains(strings.ToLower(object.Filename), strings.ToLower(req.Search)) {
+		return false
+	}
+	if !strings.HasPrefix(object.ContentType, req.ContentType) {
+		return false
+	}
+	for key, value := range req.Metadata {
+		if v, ok := object.Metadata[key]; !ok || v != value {
+			return false
+		}
+	}
+	for _, tag := range req.Tags {
+		if !slices.Contains(object.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListObjects answers req by reading every object of the bucket matching the
+// search with list, page by page, and keeping those passing MatchObject.
+func ListObjects(ctx context.Context, req *models.ListObjectsRequest, list func(context.Context, *models.ListObjectsRequest) (*models.ObjectList, error)) (*models.ObjectList, error) {
+	matched := []models.BucketObject{}
+	size := int64(0) // Objects per page, servers may return fewer than asked
+	for page := int64(1); ; page++ {
+		objects, err := list(ctx, &models.ListObjectsRequest{BucketId: req.BucketId, Search: req.Search, Page: page, PageSize: PageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range objects.Objects {
+			if MatchObject(&object, req) {
+				matched = append(matched, object)
+			}
+		}
+		size = max(size, int64(len(objects.Objects)))
+		if len(objects.Objects) == 0 || objects.Total > 0 && page*size >= objects.Total {
+			break
+		}
+	}
+
+	total := int64(len(matched))
+	result := &models.ObjectList{Objects: matched, Total: total, TotalPage: min(total, 1), Page: req.Page, PageSize: req.PageSize}
+	if req.PageSize > 0 {
+		start := min(max(req.Page-1, 0)*req.PageSize, total)
+		result.Objects = matched[start:min(start+req.PageSize, total)]
+		result.TotalPage = (total + req.PageSize - 1) / req.PageSize
+	}
+	return result, nil
+}
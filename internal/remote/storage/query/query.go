@@ -0,0 +1,369 @@
+// Package query evaluates dataset queries, vector metadata filters and object
+// filters on the client, for the backends and the API versions that cannot
+// run them.
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/tidwall/gjson"
+)
+
+// PageSize is the number of items read per page of the dataset.
+const PageSize = 100
+
+// Query is a compiled dataset query. Items are fed in dataset order with
+// Add, then Result returns the answer.
+type Query struct {
+	req     *models.QueryDataset
+	filters []filter
+	grouped bool
+
+	scanned int
+	rows    []map[string]any
+	seen    map[string]bool
+	groups  map[string]*group
+	order   []string // Group keys by first appearance
+}
+
+type filter struct {
+	models.DatasetFilter
+	value  any
+	values []any
+	re     *regexp.Regexp
+}
+
+type group struct {
+	keys   []any
+	counts []int
+	sums   []float64
+	mins   []float64
+	maxs   []float64
+}
+
+// New checks and compiles req.
+func New(req *models.QueryDataset) (*Query, error) {
+	q := &Query{
+		req:     req,
+		grouped: len(req.GroupBy) > 0 || len(req.Aggregations) > 0,
+		seen:    map[string]bool{},
+		groups:  map[string]*group{},
+	}
+	if req.Offset < 0 || req.Limit < 0 {
+		return nil, scerrors.New(scerrors.KindInvalidArgument, "query offset and limit cannot be negative")
+	}
+	for _, f := range req.Filters {
+		c, err := compileFilter(f)
+		if err != nil {
+			return nil, err
+		}
+		q.filters = append(q.filters, c)
+	}
+	for _, a := range req.Aggregations {
+		switch a.Op {
+		case "count":
+		case "sum", "min", "max", "avg":
+			if a.Path == "" {
+				return nil, scerrors.Newf(scerrors.KindInvalidArgument, "aggregation %s needs a path", a.Op)
+			}
+		default:
+			return nil, scerrors.Newf(scerrors.KindInvalidArgument, "unknown aggregation %q", a.Op)
+		}
+	}
+	return q, nil
+}
+
+// compileFilter checks f and parses its value.
+func compileFilter(f models.DatasetFilter) (filter, error) {
+	c := filter{DatasetFilter: f}
+	switch f.Op {
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		c.value = normalize(f.Value)
+	case "in":
+		list, ok := normalize(f.Value).([]any)
+		if !ok {
+			return c, scerrors.Newf(scerrors.KindInvalidArgument, "filter %s in needs a list, got %T", f.Path, f.Value)
+		}
+		c.values = list
+	case "regex":
+		pattern, ok := f.Value.(string)
+		if !ok {
+			return c, scerrors.Newf(scerrors.KindInvalidArgument, "filter %s regex needs a string, got %T", f.Path, f.Value)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return c, scerrors.Newf(scerrors.KindInvalidArgument, "filter %s: %v", f.Path, err)
+		}
+		c.re = re
+	case "exists":
+		if f.Value == nil {
+			c.value = true
+		} else if b, ok := f.Value.(bool); ok {
+			c.value = b
+		} else {
+			return c, scerrors.Newf(scerrors.KindInvalidArgument, "filter %s exists needs a boolean, got %T", f.Path, f.Value)
+		}
+	default:
+		return c, scerrors.Newf(scerrors.KindInvalidArgument, "unknown filter operator %q", f.Op)
+	}
+	return c, nil
+}
+
+// Add feeds the next item of the dataset.
+func (q *Query) Add(item map[string]any) error {
+	q.scanned++
+	doc, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	for _, f := range q.filters {
+		if !f.match(gjson.GetBytes(doc, f.Path)) {
+			return nil
+		}
+	}
+	if q.grouped {
+		q.aggregate(doc)
+		return nil
+	}
+
+	row := item
+	if len(q.req.Fields) > 0 {
+		row = make(map[string]any, len(q.req.Fields))
+		for _, path := range q.req.Fields {
+			if r := gjson.GetBytes(doc, path); r.Exists() {
+				row[path] = r.Value()
+			}
+		}
+	}
+	if q.req.Distinct {
+		key := encode(row)
+		if q.seen[key] {
+			return nil
+		}
+		q.seen[key] = true
+	}
+	q.rows = append(q.rows, row)
+	return nil
+}
+
+// Done reports whether the items fed so far answer the query, so that no
+// more pages need to be read.
+func (q *Query) Done() bool {
+	return !q.grouped && q.req.Limit > 0 && len(q.rows) >= q.req.Offset+q.req.Limit
+}
+
+// Result returns the rows of the query: the matching items, or one row per
+// group holding the grouped paths and the aggregations.
+func (q *Query) Result() *models.QueryDatasetResult {
+	rows := q.rows
+	if q.grouped {
+		rows = q.groupRows()
+	}
+	start := min(q.req.Offset, len(rows))
+	end := len(rows)
+	if q.req.Limit > 0 {
+		end = min(start+q.req.Limit, end)
+	}
+	return &models.QueryDatasetResult{Items: rows[start:end], Scanned: q.scanned}
+}
+
+func (q *Query) aggregate(doc []byte) {
+	keys := make([]any, len(q.req.GroupBy))
+	for i, path := range q.req.GroupBy {
+		keys[i] = gjson.GetBytes(doc, path).Value()
+	}
+	g := q.group(keys)
+	for i, a := range q.req.Aggregations {
+		if a.Path == "" {
+			g.counts[i]++
+			continue
+		}
+		r := gjson.GetBytes(doc, a.Path)
+		if a.Op == "count" {
+			if r.Exists() && r.Type != gjson.Null {
+				g.counts[i]++
+			}
+			continue
+		}
+		if r.Type != gjson.Number {
+			continue
+		}
+		g.counts[i]++
+		g.sums[i] += r.Num
+		g.mins[i] = min(g.mins[i], r.Num)
+		g.maxs[i] = max(g.maxs[i], r.Num)
+	}
+}
+
+// group returns the group of keys, created on first use.
+func (q *Query) group(keys []any) *group {
+	id := encode(keys)
+	if g := q.groups[id]; g != nil {
+		return g
+	}
+	n := len(q.req.Aggregations)
+	g := &group{keys: keys, counts: make([]int, n), sums: make([]float64, n), mins: make([]float64, n), maxs: make([]float64, n)}
+	for i := range n {
+		g.mins[i], g.maxs[i] = math.Inf(1), math.Inf(-1)
+	}
+	q.groups[id] = g
+	q.order = append(q.order, id)
+	return g
+}
+
+func (q *Query) groupRows() []map[string]any {
+	if len(q.order) == 0 && len(q.req.GroupBy) == 0 {
+		// Aggregations over no item still make a row.
+		q.group([]any{})
+	}
+	rows := make([]map[string]any, 0, len(q.order))
+	for _, id := range q.order {
+		g := q.groups[id]
+		row := make(map[string]any, len(q.req.GroupBy)+len(q.req.Aggregations))
+		for i, path := range q.req.GroupBy {
+			row[path] = g.keys[i]
+		}
+		for i, a := range q.req.Aggregations {
+			name := a.As
+			if name == "" {
+				name = a.Op
+				if a.Path != "" {
+					name = fmt.Sprintf("%s(%s)", a.Op, a.Path)
+				}
+			}
+			var value any // null for min, max and avg of no number
+			switch {
+			case a.Op == "count":
+				value = g.counts[i]
+			case a.Op == "sum":
+				value = g.sums[i]
+			case g.counts[i] == 0:
+			case a.Op == "min":
+				value = g.mins[i]
+			case a.Op == "max":
+				value = g.maxs[i]
+			case a.Op == "avg":
+				value = g.sums[i] / float64(g.counts[i])
+			}
+			row[name] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func (f *filter) match(r gjson.Result) bool {
+	switch f.Op {
+	case "exists":
+		return r.Exists() == f.value.(bool)
+	case "eq":
+		return r.Exists() && equal(r.Value(), f.value)
+	case "ne":
+		return !r.Exists() || !equal(r.Value(), f.value)
+	case "in":
+		if !r.Exists() {
+			return false
+		}
+		for _, v := range f.values {
+			if equal(r.Value(), v) {
+				return true
+			}
+		}
+		return false
+	case "regex":
+		return r.Exists() && r.Type != gjson.Null && f.re.MatchString(r.String())
+	}
+	c, ok := compare(r.Value(), f.value)
+	if !ok {
+		return false
+	}
+	switch f.Op {
+	case "gt":
+		return c > 0
+	case "gte":
+		return c >= 0
+	case "lt":
+		return c < 0
+	default: // lte
+		return c <= 0
+	}
+}
+
+// compare orders two numbers or two strings.
+func compare(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	}
+	return 0, false
+}
+
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// normalize converts a value given by the caller to the values gjson
+// returns, so that 3 equals 3.0.
+func normalize(v any) any {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out any
+	if err = json.Unmarshal(buf, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+func encode(v any) string {
+	buf, _ := json.Marshal(v)
+	return string(buf)
+}
+
+// Run answers req by reading the dataset page by page with getDataset.
+func Run(ctx context.Context, req *models.QueryDataset, getDataset func(context.Context, *models.GetDataset) (*models.DatasetItem, error)) (*models.QueryDatasetResult, error) {
+	q, err := New(req)
+	if err != nil {
+		return nil, err
+	}
+	size := 0 // Items per page, servers may return fewer than asked
+	for page := 1; ; page++ {
+		items, err := getDataset(ctx, &models.GetDataset{DatasetId: req.DatasetId, Desc: req.Desc, Page: page, PageSize: PageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items.Items {
+			if err = q.Add(item); err != nil {
+				return nil, err
+			}
+			if q.Done() {
+				return q.Result(), nil
+			}
+		}
+		size = max(size, len(items.Items))
+		if len(items.Items) == 0 || items.Total > 0 && page*size >= items.Total {
+			return q.Result(), nil
+		}
+	}
+}
@@ -1,44 +1,26 @@
 package storage_http
 
 import (
-	"github.com/scrapeless-ai/sdk-go/env"
-	"google.golang.org/grpc"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"net/http"
 )
 
-var (
-	defaultStorageClient *Client
-)
-
 type Client struct {
-	client  *http.Client
-	BaseUrl string
+	client      *http.Client
+	BaseUrl     string
+	ApiKey      string
+	queueHandel map[HandleFuncName]*HttpHandle[request2.RespInfo]
 }
 
-func Init(baseUrl ...string) {
-	var (
-		err error
-	)
-	u := env.Env.ScrapelessStorageUrl
-	if len(baseUrl) > 0 {
-		u = baseUrl[0]
-	}
-	defaultStorageClient, err = New(u)
-	defaultStorageClient.regisHttpHandleFunc()
-	if err != nil {
-		panic(err)
-	}
-}
-
-func Default() *Client {
-	return defaultStorageClient
-}
-
-func New(baseUrl string, opts ...grpc.DialOption) (*Client, error) {
-	return &Client{
-		client:  &http.Client{},
+func New(baseUrl string, cfg request2.ClientConfig) (*Client, error) {
+	cfg = request2.ClientConfigOf(cfg)
+	c := &Client{
+		client:  cfg.HTTPClient,
 		BaseUrl: baseUrl,
-	}, nil
+		ApiKey:  cfg.ApiKey,
+	}
+	c.regisHttpHandleFunc()
+	return c, nil
 }
 
 func (c *Client) Close() error {
@@ -16,6 +16,8 @@ func (c *Client) ListDatasets(ctx context.Context, req *models.ListDatasetsReque
 		Url:     fmt.Sprintf("%s/api/v1/dataset?actorId=%s&desc=%v&page=%d&pageSize=%d&runId=%s", c.BaseUrl, *req.ActorId, req.Desc, req.Page, req.PageSize, *req.RunId),
 		Body:    "",
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("list dataset body:%s", body)
 	if err != nil {
@@ -29,7 +31,7 @@ func (c *Client) ListDatasets(ctx context.Context, req *models.ListDatasetsReque
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.ListDatasetsResponse
@@ -52,6 +54,8 @@ func (c *Client) CreateDataset(ctx context.Context, req *models.CreateDatasetReq
 		Url:     fmt.Sprintf("%s/api/v1/dataset", c.BaseUrl),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("create dataset body:%s", body)
 	if err != nil {
@@ -65,7 +69,7 @@ func (c *Client) CreateDataset(ctx context.Context, req *models.CreateDatasetReq
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("creat dataset err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Dataset
@@ -83,6 +87,8 @@ func (c *Client) UpdateDataset(ctx context.Context, datasetID, name string) (boo
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s", c.BaseUrl, datasetID),
 		Body:    fmt.Sprintf(`{"name":"%s"}`, name),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("up dataset body:%s", body)
 	if err != nil {
@@ -96,7 +102,7 @@ func (c *Client) UpdateDataset(ctx context.Context, datasetID, name string) (boo
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("edit dataset err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -105,6 +111,8 @@ func (c *Client) DelDataset(ctx context.Context, datasetID string) (bool, error)
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s", c.BaseUrl, datasetID),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("del dataset body:%s", body)
 	if err != nil {
@@ -118,7 +126,7 @@ func (c *Client) DelDataset(ctx context.Context, datasetID string) (bool, error)
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("del dataset err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -128,6 +136,8 @@ func (c *Client) GetDataset(ctx context.Context, req *models.GetDataset) (*model
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s/items?page=%d&pageSize=%d&desc=%v", c.BaseUrl, req.DatasetId, req.Page, req.PageSize, req.Desc),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("get dataset body:%s", body)
 	if err != nil {
@@ -141,7 +151,7 @@ func (c *Client) GetDataset(ctx context.Context, req *models.GetDataset) (*model
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset item err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DatasetItem
@@ -166,6 +176,8 @@ func (c *Client) AddDatasetItem(ctx context.Context, datasetId string, data []ma
 		Url:     fmt.Sprintf("%s/api/v1/dataset/%s/items", c.BaseUrl, datasetId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("add dataset item body:%s", body)
 	if err != nil {
@@ -180,7 +192,7 @@ func (c *Client) AddDatasetItem(ctx context.Context, datasetId string, data []ma
 	}
 	if resp.Err {
 		log.Errorf("add dataset item err:%s", resp.Msg)
-		return false, fmt.Errorf("add dataset item err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
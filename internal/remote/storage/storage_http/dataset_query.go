@@ -0,0 +1,14 @@
+package storage_http
+
+import (
+	"context"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+)
+
+// QueryDataset reads the pages of the dataset and evaluates the query
+// locally, the API has no query endpoint.
+func (c *Client) QueryDataset(ctx context.Context, req *models.QueryDataset) (*models.QueryDatasetResult, error) {
+	return query.Run(ctx, req, c.GetDataset)
+}
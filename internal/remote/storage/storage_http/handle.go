@@ -14,6 +14,7 @@ import (
 type IResponse interface {
 	IsErr() bool
 	Error() string
+	AsError() error
 	GetData() any
 }
 
@@ -26,8 +27,6 @@ type HttpHandle[T IResponse] struct {
 	respInfo       T // Compatible with other HTTP interfaces with different response structures
 }
 
-var queueHandel map[HandleFuncName]*HttpHandle[request2.RespInfo]
-
 type HandleFuncName string
 
 const (
@@ -45,7 +44,7 @@ const (
 
 func (c *Client) regisHttpHandleFunc() {
 
-	queueHandel = map[HandleFuncName]*HttpHandle[request2.RespInfo]{
+	c.queueHandel = map[HandleFuncName]*HttpHandle[request2.RespInfo]{
 		createQueue: {
 			Method:         http.MethodPost,
 			Url:            fmt.Sprintf("%s/api/v1/queue", c.BaseUrl),
@@ -135,9 +134,13 @@ func (c *Client) regisHttpHandleFunc() {
 		},
 	}
 }
+
+// setReq returns a copy of the handle carrying req, so that concurrent calls
+// never share a request.
 func (h *HttpHandle[T]) setReq(req any) *HttpHandle[T] {
-	h.Req = req
-	return h
+	cp := *h
+	cp.Req = req
+	return &cp
 }
 
 func (h *HttpHandle[T]) setRespInfo(info T) *HttpHandle[T] {
@@ -147,7 +150,7 @@ func (h *HttpHandle[T]) setRespInfo(info T) *HttpHandle[T] {
 
 func (h *HttpHandle[T]) Unmarshal(resp any) error {
 	if h.respInfo.IsErr() {
-		return errors.New(h.respInfo.Error())
+		return h.respInfo.AsError()
 	}
 	marshal, err := json.Marshal(h.respInfo.GetData())
 	if err != nil {
@@ -160,9 +163,7 @@ func (h *HttpHandle[T]) Unmarshal(resp any) error {
 	return nil
 }
 
-func (h *HttpHandle[T]) sendRequest(ctx context.Context) (*HttpHandle[T], error) {
-	defer h.setReq(nil)
-
+func (h *HttpHandle[T]) sendRequest(ctx context.Context, c *Client) (*HttpHandle[T], error) {
 	reqBody := ""
 	if h.NeedMarshalReq {
 		if h.Req == nil {
@@ -189,6 +190,8 @@ func (h *HttpHandle[T]) sendRequest(ctx context.Context) (*HttpHandle[T], error)
 		Url:     url,
 		Body:    reqBody,
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		log.Errorf("request err:%v", err)
@@ -202,7 +205,7 @@ func (h *HttpHandle[T]) sendRequest(ctx context.Context) (*HttpHandle[T], error)
 	}
 	h.setRespInfo(resp)
 	if resp.IsErr() {
-		return h, errors.New(resp.Error())
+		return h, resp.AsError()
 	}
 	return h, nil
 }
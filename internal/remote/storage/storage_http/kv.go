@@ -6,6 +6,7 @@ import (
 	"fmt"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
 	"net/http"
@@ -17,6 +18,8 @@ func (c *Client) ListNamespaces(ctx context.Context, page int64, pageSize int64,
 		Url:     fmt.Sprintf("%s/api/v1/kv/namespaces?desc=%v&page=%d&pageSize=%d", c.BaseUrl, desc, page, pageSize),
 		Body:    "",
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("list namespaces body:%s", body)
 	if err != nil {
@@ -30,7 +33,7 @@ func (c *Client) ListNamespaces(ctx context.Context, page int64, pageSize int64,
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.KvNamespace
@@ -52,6 +55,8 @@ func (c *Client) CreateNamespace(ctx context.Context, req *models.CreateKvNamesp
 		Url:     fmt.Sprintf("%s/api/v1/kv/namespaces", c.BaseUrl),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("create namespace body:%s", body)
 	if err != nil {
@@ -65,7 +70,7 @@ func (c *Client) CreateNamespace(ctx context.Context, req *models.CreateKvNamesp
 		return "", err
 	}
 	if resp.Err {
-		return "", fmt.Errorf("create namespace err:%s", resp.Msg)
+		return "", resp.AsError()
 	}
 	id := gjson.Parse(body).Get("data.id").String()
 	return id, nil
@@ -75,6 +80,8 @@ func (c *Client) GetNamespace(ctx context.Context, namespaceId string) (*models.
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s", c.BaseUrl, namespaceId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("get namespace body:%s", body)
 	if err != nil {
@@ -88,7 +95,7 @@ func (c *Client) GetNamespace(ctx context.Context, namespaceId string) (*models.
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get namespace err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	data := gjson.Parse(body).Get("data").String()
 	var kvi models.KvNamespaceItem
@@ -104,6 +111,8 @@ func (c *Client) DelNamespace(ctx context.Context, namespaceId string) (bool, er
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s", c.BaseUrl, namespaceId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("del namespace body:%s", body)
 	if err != nil {
@@ -116,7 +125,7 @@ func (c *Client) DelNamespace(ctx context.Context, namespaceId string) (bool, er
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("get namespace err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -127,6 +136,8 @@ func (c *Client) RenameNamespace(ctx context.Context, namespaceId string, name s
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/rename", c.BaseUrl, namespaceId),
 		Body:    fmt.Sprintf(`{"name":"%s"}`, name),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("rename namespace body:%s", body)
 	if err != nil {
@@ -140,7 +151,7 @@ func (c *Client) RenameNamespace(ctx context.Context, namespaceId string, name s
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("get namespace err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -161,6 +172,8 @@ func (c *Client) SetValue(ctx context.Context, req *models.SetValue) (bool, erro
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/key", c.BaseUrl, req.NamespaceId),
 		Body:    string(reqBodyStr),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("set value body :%s", body)
 	if err != nil {
@@ -175,7 +188,7 @@ func (c *Client) SetValue(ctx context.Context, req *models.SetValue) (bool, erro
 	}
 	if resp.Err {
 		log.Errorf("set value err :%v", resp.Msg)
-		return false, fmt.Errorf("set value err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -185,6 +198,8 @@ func (c *Client) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/keys?page=%d&pageSize=%d", c.BaseUrl, req.NamespaceId, req.Page, req.Size),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("list keys body :%s", body)
 	if err != nil {
@@ -198,7 +213,7 @@ func (c *Client) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("list Keys err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.KvKeys
@@ -215,6 +230,8 @@ func (c *Client) GetValue(ctx context.Context, namespaceId string, key string) (
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/%s", c.BaseUrl, namespaceId, key),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("get value body :%s", body)
 	if err != nil {
@@ -228,10 +245,14 @@ func (c *Client) GetValue(ctx context.Context, namespaceId string, key string) (
 		return "", err
 	}
 	if resp.Err {
-		return "", fmt.Errorf("get value err:%s", resp.Msg)
+		return "", resp.AsError()
 	}
-	data := gjson.Parse(body).Get("data").String()
-	return data, nil
+	// The API answers a missing key with no data, an empty value is a string.
+	data := gjson.Parse(body).Get("data")
+	if !data.Exists() || data.Type == gjson.Null {
+		return "", scerrors.Newf(scerrors.KindNotFound, "key %q not found", key)
+	}
+	return data.String(), nil
 }
 
 func (c *Client) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
@@ -239,6 +260,8 @@ func (c *Client) DelValue(ctx context.Context, namespaceId string, key string) (
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/%s", c.BaseUrl, namespaceId, key),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("del value body :%s", body)
 	if err != nil {
@@ -252,7 +275,7 @@ func (c *Client) DelValue(ctx context.Context, namespaceId string, key string) (
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("list Keys err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -267,6 +290,8 @@ func (c *Client) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64,
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/bulk", c.BaseUrl, req.NamespaceId),
 		Body:    fmt.Sprintf(`{"Items":%s}`, reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("bulk set value body :%s", body)
 	if err != nil {
@@ -279,7 +304,7 @@ func (c *Client) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64,
 		return 0, err
 	}
 	if resp.Err {
-		return 0, fmt.Errorf("bulk set value err:%s", resp.Msg)
+		return 0, resp.AsError()
 	}
 	successfulKeyCount := gjson.Parse(body).Get("data.successfulKeyCount").Int()
 	return successfulKeyCount, nil
@@ -295,6 +320,8 @@ func (c *Client) BulkDelValue(ctx context.Context, namespaceId string, keys []st
 		Url:     fmt.Sprintf("%s/api/v1/kv/%s/bulk", c.BaseUrl, namespaceId),
 		Body:    fmt.Sprintf(`{"keys":%s}`, reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("bulk del value body :%s", body)
 	if err != nil {
@@ -308,7 +335,7 @@ func (c *Client) BulkDelValue(ctx context.Context, namespaceId string, keys []st
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("bulk set value err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
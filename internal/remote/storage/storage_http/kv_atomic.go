@@ -0,0 +1,29 @@
+package storage_http
+
+import (
+	"context"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// The KV API has no conditional writes. Emulating them with a read and a
+// write would race with the other runs writing the same key, so they are
+// reported as unsupported rather than pretending to be atomic.
+var errNoAtomicKV = scerrors.New(scerrors.KindNotImplemented, "atomic KV operations are not supported by the API")
+
+func (c *Client) CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error) {
+	return false, errNoAtomicKV
+}
+
+func (c *Client) Increment(ctx context.Context, req *models.Increment) (int64, error) {
+	return 0, errNoAtomicKV
+}
+
+func (c *Client) SetNX(ctx context.Context, req *models.SetValue) (bool, error) {
+	return false, errNoAtomicKV
+}
+
+func (c *Client) GetAndDelete(ctx context.Context, namespaceId string, key string) (string, error) {
+	return "", errNoAtomicKV
+}
@@ -0,0 +1,75 @@
+package storage_http
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+// listCursor prefixes the cursors of scans. They hold the next page to list.
+const listCursor = "list:"
+
+const scanPageSize = 100
+
+// ScanKeys filters the pages of ListKeys, as the API has no scan endpoint
+// of its own: a route like /kv/{namespace}/scan would be the value of a key
+// named "scan".
+func (c *Client) ScanKeys(ctx context.Context, req *models.ScanKeys) (*models.ScanKeysResult, error) {
+	return c.scanListedKeys(ctx, req)
+}
+
+// scanListedKeys lists pages of keys from the page held by the cursor until
+// Count keys matched. Whole pages are consumed, so a batch may hold more
+// than Count keys.
+func (c *Client) scanListedKeys(ctx context.Context, req *models.ScanKeys) (*models.ScanKeysResult, error) {
+	page := int64(1)
+	if req.Cursor != "" {
+		n, err := strconv.ParseInt(strings.TrimPrefix(req.Cursor, listCursor), 10, 64)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid scan cursor %q", req.Cursor)
+		}
+		page = n
+	}
+	result := &models.ScanKeysResult{}
+	size := int64(0) // Keys per page, servers may return fewer than asked
+	for {
+		keys, err := c.ListKeys(ctx, &models.ListKeyInfo{NamespaceId: req.NamespaceId, Page: page, Size: scanPageSize})
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range keys.Items {
+			key := keyInfo(item)
+			if req.Match == "" || helper.GlobMatch(req.Match, key.Key) {
+				result.Items = append(result.Items, key)
+			}
+		}
+		size = max(size, int64(len(keys.Items)))
+		if len(keys.Items) == 0 || keys.Total > 0 && page*size >= keys.Total {
+			return result, nil
+		}
+		page++
+		if int64(len(result.Items)) >= max(req.Count, 1) {
+			result.Cursor = listCursor + strconv.FormatInt(page, 10)
+			return result, nil
+		}
+	}
+}
+
+// keyInfo reads an item of ListKeys. The expiry is only known when the API
+// reports it.
+func keyInfo(item map[string]any) models.KeyInfo {
+	var key models.KeyInfo
+	key.Key, _ = item["key"].(string)
+	if size, ok := item["size"].(float64); ok {
+		key.Size = int64(size)
+	}
+	if expireAt, ok := item["expireAt"].(string); ok {
+		key.ExpireAt, _ = time.Parse(time.RFC3339, expireAt)
+	}
+	return key
+}
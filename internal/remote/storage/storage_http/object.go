@@ -8,11 +8,19 @@ import (
 	"github.com/scrapeless-ai/sdk-go/env"
 	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
 )
 
 func (c *Client) ListBuckets(ctx context.Context, page, size int) (*models.Object, error) {
@@ -20,6 +28,8 @@ func (c *Client) ListBuckets(ctx context.Context, page, size int) (*models.Objec
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets?page=%d&pageSize=%d", c.BaseUrl, page, size),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("list buckets body :%s", body)
 	if err != nil {
@@ -33,7 +43,7 @@ func (c *Client) ListBuckets(ctx context.Context, page, size int) (*models.Objec
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get dataset list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Object
@@ -55,6 +65,8 @@ func (c *Client) CreateBucket(ctx context.Context, req *models.CreateBucketReque
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets", c.BaseUrl),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("create bucket body :%s", body)
 	if err != nil {
@@ -68,13 +80,13 @@ func (c *Client) CreateBucket(ctx context.Context, req *models.CreateBucketReque
 		return "", err
 	}
 	if resp.Err {
-		return "", fmt.Errorf("create bucket err:%s", resp.Msg)
+		return "", resp.AsError()
 	}
 	id := gjson.Parse(body).Get("data.id").String()
 	if id != "" {
 		return id, nil
 	}
-	return "", fmt.Errorf("create bucket err:%s", resp.Msg)
+	return "", scerrors.New(scerrors.KindInternal, "create bucket: response has no bucket id")
 }
 
 func (c *Client) DeleteBucket(ctx context.Context, bucketId string) (bool, error) {
@@ -82,6 +94,8 @@ func (c *Client) DeleteBucket(ctx context.Context, bucketId string) (bool, error
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s", c.BaseUrl, bucketId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("del bucket body :%s", body)
 	if err != nil {
@@ -95,7 +109,7 @@ func (c *Client) DeleteBucket(ctx context.Context, bucketId string) (bool, error
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("get bucket err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
@@ -104,6 +118,8 @@ func (c *Client) GetBucket(ctx context.Context, bucketId string) (*models.Bucket
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s", c.BaseUrl, bucketId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("get bucket body :%s", body)
 	if err != nil {
@@ -117,7 +133,7 @@ func (c *Client) GetBucket(ctx context.Context, bucketId string) (*models.Bucket
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get bucket err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Bucket
@@ -129,11 +145,44 @@ func (c *Client) GetBucket(ctx context.Context, bucketId string) (*models.Bucket
 	return &respData, nil
 }
 
+// ListObjects lists the objects of a bucket. The API only searches objects,
+// so a content type filter is applied here to all the objects matching the
+// search, on the content type the API reports or else the one of the
+// filename extension. The API stores no metadata nor tags to filter on.
 func (c *Client) ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ObjectList, error) {
+	if len(req.Metadata) > 0 || len(req.Tags) > 0 {
+		return nil, errNoObjectAttributes
+	}
+	if req.ContentType == "" {
+		return c.listObjects(ctx, req)
+	}
+	return query.ListObjects(ctx, req, func(ctx context.Context, req *models.ListObjectsRequest) (*models.ObjectList, error) {
+		list, err := c.listObjects(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list.Objects {
+			if object := &list.Objects[i]; object.ContentType == "" {
+				object.ContentType = mime.TypeByExtension(path.Ext(object.Filename))
+			}
+		}
+		return list, nil
+	})
+}
+
+func (c *Client) listObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ObjectList, error) {
+	params := url.Values{}
+	params.Set("page", strconv.FormatInt(req.Page, 10))
+	params.Set("pageSize", strconv.FormatInt(req.PageSize, 10))
+	if req.Search != "" {
+		params.Set("search", req.Search)
+	}
 	body, err := request2.Request(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
-		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/objects", c.BaseUrl, req.BucketId),
+		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/objects?%s", c.BaseUrl, req.BucketId, params.Encode()),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("list objects body :%s", body)
 	if err != nil {
@@ -147,7 +196,7 @@ func (c *Client) ListObjects(ctx context.Context, req *models.ListObjectsRequest
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get bucket err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.ObjectList
@@ -164,6 +213,8 @@ func (c *Client) GetObject(ctx context.Context, req *models.ObjectRequest) ([]by
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/%s", c.BaseUrl, req.BucketId, req.ObjectId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("get object body :%s", body)
 	if err != nil {
@@ -177,7 +228,7 @@ func (c *Client) GetObject(ctx context.Context, req *models.ObjectRequest) ([]by
 		return []byte(body), nil
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get object err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	return []byte(body), nil
 }
@@ -187,6 +238,8 @@ func (c *Client) DeleteObject(ctx context.Context, req *models.ObjectRequest) (b
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/object/buckets/%s/%s", c.BaseUrl, req.BucketId, req.ObjectId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("del object body :%s", body)
 	if err != nil {
@@ -200,30 +253,37 @@ func (c *Client) DeleteObject(ctx context.Context, req *models.ObjectRequest) (b
 		return false, err
 	}
 	if resp.Err {
-		return false, fmt.Errorf("delete object err:%s", resp.Msg)
+		return false, resp.AsError()
 	}
 	return true, nil
 }
 
 func (c *Client) PutObject(ctx context.Context, req *models.PutObjectRequest) (string, error) {
+	if len(req.Metadata) > 0 || len(req.Tags) > 0 {
+		return "", errNoObjectAttributes
+	}
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
-	part, _ := writer.CreateFormFile("file", req.Filename)
+	part, err := writeObjectForm(writer, req.Filename, req.ActorId, req.RunId, req.ObjectAttributes)
+	if err != nil {
+		return "", scerrors.Newf(scerrors.KindInvalidArgument, "build request: %v", err)
+	}
 	part.Write(req.Data)
-	writer.WriteField("actorId", req.ActorId)
-	writer.WriteField("runId", req.RunId)
 	writer.Close()
 
 	url := fmt.Sprintf("%s/api/v1/object/buckets/%s/object", c.BaseUrl, req.BucketId)
 	request, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	request.Header.Set("Content-Type", writer.FormDataContentType())
-	request.Header.Set(env.Env.HTTPHeader, env.GetActorEnv().ApiKey)
+	request.Header.Set(env.Get().HTTPHeader, c.ApiKey)
 	resp, err := c.client.Do(request)
+	if err != nil {
+		log.Errorf("request error :%v", err)
+		return "", scerrors.From(err)
+	}
 	defer resp.Body.Close()
 	all, _ := io.ReadAll(resp.Body)
 	log.Infof("put object body :%s", string(all))
-	if err != nil {
-		log.Errorf("request error :%v", err)
+	if err = (&request2.Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: all}).Err(); err != nil {
 		return "", err
 	}
 	var respInfo request2.RespInfo
@@ -233,11 +293,39 @@ func (c *Client) PutObject(ctx context.Context, req *models.PutObjectRequest) (s
 		return "", err
 	}
 	if respInfo.Err {
-		return "", fmt.Errorf("put object err:%s", respInfo.Msg)
+		return "", respInfo.AsError()
 	}
 	objectId := gjson.Parse(string(all)).Get("data.objectId").String()
 	if objectId == "" {
-		return "", fmt.Errorf("put object err:%s", respInfo.Msg)
+		return "", scerrors.New(scerrors.KindInternal, "put object: response has no object id")
 	}
 	return objectId, nil
 }
+
+// errNoObjectAttributes is returned for the metadata and tags of objects,
+// which the API does not store.
+var errNoObjectAttributes = scerrors.New(scerrors.KindNotImplemented, "object metadata and tags are not supported by the API")
+
+// writeObjectForm writes the fields of an upload form and returns the writer
+// of its file part, which comes last. The content type goes in the header of
+// the file part.
+func writeObjectForm(writer *multipart.Writer, filename, actorId, runId string, attrs models.ObjectAttributes) (io.Writer, error) {
+	// Servers commonly strip the directories from the filename of the file
+	// part, the field keeps names like "shots/1.png" whole.
+	for _, field := range [][2]string{{"actorId", actorId}, {"runId", runId}, {"filename", filename}} {
+		if err := writer.WriteField(field[0], field[1]); err != nil {
+			return nil, err
+		}
+	}
+	contentType := attrs.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, quoteEscaper.Replace(filename)))
+	header.Set("Content-Type", contentType)
+	return writer.CreatePart(header)
+}
+
+// quoteEscaper escapes a filename like multipart.Writer.CreateFormFile.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -0,0 +1,183 @@
+package storage_http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/tidwall/gjson"
+)
+
+// errNoMultipart is returned by the multipart upload methods; the object has
+// to be sent in a single request.
+var errNoMultipart = scerrors.New(scerrors.KindNotImplemented, "multipart upload is not supported by the API")
+
+// PutObjectStream sends the body as the file of a multipart form, encoded
+// while it is sent. The transfer is only timed out when it stalls.
+func (c *Client) PutObjectStream(ctx context.Context, req *models.PutObjectStreamRequest) (string, error) {
+	if len(req.Metadata) > 0 || len(req.Tags) > 0 {
+		return "", errNoObjectAttributes
+	}
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		err := func() error {
+			part, err := writeObjectForm(writer, req.Filename, req.ActorId, req.RunId, req.ObjectAttributes)
+			if err != nil {
+				return err
+			}
+			n, err := io.Copy(part, req.Body)
+			if err != nil {
+				return err
+			}
+			if req.Size >= 0 && n != req.Size {
+				return scerrors.Newf(scerrors.KindInvalidArgument, "object %s has %d bytes, %d were announced", req.Filename, n, req.Size)
+			}
+			return writer.Close()
+		}()
+		pw.CloseWithError(err)
+	}()
+
+	url := fmt.Sprintf("%s/api/v1/object/buckets/%s/object", c.BaseUrl, req.BucketId)
+	request, err := http.NewRequestWithContext(request2.WithStreaming(ctx), http.MethodPost, url, pr)
+	if err != nil {
+		_ = pr.CloseWithError(err)
+		return "", scerrors.Newf(scerrors.KindInvalidArgument, "build request: %v", err)
+	}
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+	request.Header.Set(env.Get().HTTPHeader, c.ApiKey)
+	resp, err := c.client.Do(request)
+	// Unblock the encoding goroutine when the request ended early.
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		log.Errorf("request error :%v", err)
+		return "", scerrors.From(err)
+	}
+	defer resp.Body.Close()
+	all, _ := io.ReadAll(resp.Body)
+	log.Infof("put object stream body :%s", string(all))
+	if err = (&request2.Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: all}).Err(); err != nil {
+		return "", err
+	}
+	var respInfo request2.RespInfo
+	if err = json.Unmarshal(all, &respInfo); err != nil {
+		log.Errorf("unmarshal resp error :%v", err)
+		return "", err
+	}
+	if respInfo.Err {
+		return "", respInfo.AsError()
+	}
+	objectId := gjson.GetBytes(all, "data.objectId").String()
+	if objectId == "" {
+		return "", scerrors.New(scerrors.KindInternal, "put object: response has no object id")
+	}
+	return objectId, nil
+}
+
+// GetObjectStream asks for a byte range of the object. A server ignoring
+// the Range header sends the whole object, which is then cut to the range.
+// Reading the body is only timed out when it stalls.
+func (c *Client) GetObjectStream(ctx context.Context, req *models.GetObjectStreamRequest) (*models.ObjectStream, error) {
+	url := fmt.Sprintf("%s/api/v1/object/buckets/%s/%s", c.BaseUrl, req.BucketId, req.ObjectId)
+	request, err := http.NewRequestWithContext(request2.WithStreaming(ctx), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "build request: %v", err)
+	}
+	request.Header.Set(env.Get().HTTPHeader, c.ApiKey)
+	ranged := req.Offset > 0 || req.Length > 0
+	if ranged {
+		rng := fmt.Sprintf("bytes=%d-", req.Offset)
+		if req.Length > 0 {
+			rng += strconv.FormatInt(req.Offset+req.Length-1, 10)
+		}
+		request.Header.Set("Range", rng)
+	}
+	resp, err := c.client.Do(request)
+	if err != nil {
+		log.Errorf("request error :%v", err)
+		return nil, scerrors.From(err)
+	}
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// The range starts at or after the end of the object.
+		_ = resp.Body.Close()
+		return &models.ObjectStream{Body: http.NoBody, Size: 0, TotalSize: -1}, nil
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		all, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return nil, (&request2.Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: all}).Err()
+	}
+
+	stream := &models.ObjectStream{Body: resp.Body, Size: resp.ContentLength, TotalSize: resp.ContentLength}
+	if resp.StatusCode == http.StatusPartialContent {
+		stream.TotalSize = contentRangeTotal(resp.Header.Get("Content-Range"))
+		return stream, nil
+	}
+	if !ranged {
+		return stream, nil
+	}
+	if _, err = io.CopyN(io.Discard, resp.Body, req.Offset); err != nil && err != io.EOF {
+		_ = resp.Body.Close()
+		return nil, scerrors.From(err)
+	}
+	var body io.Reader = resp.Body
+	if resp.ContentLength >= 0 {
+		stream.Size = max(resp.ContentLength-req.Offset, 0)
+	}
+	if req.Length > 0 {
+		body = io.LimitReader(resp.Body, req.Length)
+		if stream.Size >= 0 {
+			stream.Size = min(stream.Size, req.Length)
+		}
+	}
+	stream.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, resp.Body}
+	return stream, nil
+}
+
+// contentRangeTotal reads the size of the object in "bytes 0-99/1234".
+func contentRangeTotal(header string) int64 {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok {
+		return -1
+	}
+	n, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// CreateUpload fails with errNoMultipart: the API has no multipart upload
+// endpoints, so objects sent over HTTP always go in one streamed request.
+func (c *Client) CreateUpload(ctx context.Context, req *models.CreateUploadRequest) (string, error) {
+	return "", errNoMultipart
+}
+
+func (c *Client) UploadPart(ctx context.Context, req *models.UploadPartRequest) (*models.UploadedPart, error) {
+	return nil, errNoMultipart
+}
+
+func (c *Client) ListParts(ctx context.Context, bucketId string, uploadId string) ([]models.UploadedPart, error) {
+	return nil, errNoMultipart
+}
+
+func (c *Client) CompleteUpload(ctx context.Context, req *models.CompleteUploadRequest) (string, error) {
+	return "", errNoMultipart
+}
+
+func (c *Client) AbortUpload(ctx context.Context, bucketId string, uploadId string) error {
+	return errNoMultipart
+}
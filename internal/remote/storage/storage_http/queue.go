@@ -8,11 +8,11 @@ import (
 )
 
 func (c *Client) CreateQueue(ctx context.Context, req *models.CreateQueueRequest) (*models.CreateQueueResponse, error) {
-	handel, ok := queueHandel[createQueue]
+	handel, ok := c.queueHandel[createQueue]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		return nil, err
 	}
@@ -26,11 +26,11 @@ func (c *Client) CreateQueue(ctx context.Context, req *models.CreateQueueRequest
 }
 
 func (c *Client) GetQueue(ctx context.Context, req *models.GetQueueRequest) (*models.GetQueueResponse, error) {
-	handel, ok := queueHandel[getQueue]
+	handel, ok := c.queueHandel[getQueue]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		return nil, err
 	}
@@ -44,11 +44,11 @@ func (c *Client) GetQueue(ctx context.Context, req *models.GetQueueRequest) (*mo
 }
 
 func (c *Client) GetQueues(ctx context.Context, req *models.GetQueuesRequest) (*models.ListQueuesResponse, error) {
-	handel, ok := queueHandel[getQueues]
+	handel, ok := c.queueHandel[getQueues]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		return nil, err
 	}
@@ -62,11 +62,11 @@ func (c *Client) GetQueues(ctx context.Context, req *models.GetQueuesRequest) (*
 }
 
 func (c *Client) UpdateQueue(ctx context.Context, req *models.UpdateQueueRequest) error {
-	handel, ok := queueHandel[updateQueue]
+	handel, ok := c.queueHandel[updateQueue]
 	if !ok {
 		return fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		log.Error(err.Error())
 		return err
@@ -76,11 +76,11 @@ func (c *Client) UpdateQueue(ctx context.Context, req *models.UpdateQueueRequest
 }
 
 func (c *Client) DelQueue(ctx context.Context, req *models.DelQueueRequest) error {
-	handel, ok := queueHandel[delQueue]
+	handel, ok := c.queueHandel[delQueue]
 	if !ok {
 		return fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		return err
 	}
@@ -89,11 +89,11 @@ func (c *Client) DelQueue(ctx context.Context, req *models.DelQueueRequest) erro
 }
 
 func (c *Client) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*models.CreateMsgResponse, error) {
-	handel, ok := queueHandel[createMsg]
+	handel, ok := c.queueHandel[createMsg]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		return nil, err
 	}
@@ -107,11 +107,11 @@ func (c *Client) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*
 }
 
 func (c *Client) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models.GetMsgResponse, error) {
-	handel, ok := queueHandel[getMsg]
+	handel, ok := c.queueHandel[getMsg]
 	if !ok {
 		return nil, fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		return nil, err
 	}
@@ -124,11 +124,11 @@ func (c *Client) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models
 }
 
 func (c *Client) AckMsg(ctx context.Context, req *models.AckMsgRequest) error {
-	handel, ok := queueHandel[ackMsg]
+	handel, ok := c.queueHandel[ackMsg]
 	if !ok {
 		return fmt.Errorf("not found handle func")
 	}
-	handel, err := handel.setReq(req).sendRequest(ctx)
+	handel, err := handel.setReq(req).sendRequest(ctx, c)
 	if err != nil {
 		return err
 	}
@@ -28,6 +28,8 @@ func (c *Client) ListCollections(ctx context.Context, req *models.ListCollection
 		Method:  http.MethodGet,
 		Url:     u.String(),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("list collection body:%s", body)
 	if err != nil {
@@ -41,7 +43,7 @@ func (c *Client) ListCollections(ctx context.Context, req *models.ListCollection
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get collections list err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.ListCollectionsResponse
@@ -64,6 +66,8 @@ func (c *Client) CreateCollections(ctx context.Context, req *models.CreateCollec
 		Url:     fmt.Sprintf("%s/api/v1/vector", c.BaseUrl),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("create collection body:%s", body)
 	if err != nil {
@@ -76,7 +80,7 @@ func (c *Client) CreateCollections(ctx context.Context, req *models.CreateCollec
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("create collection err: %s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var coll models.Collection
@@ -101,6 +105,8 @@ func (c *Client) UpdateCollection(ctx context.Context, req *models.UpdateCollect
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("update collection body:%s", body)
 	if err != nil {
@@ -114,7 +120,7 @@ func (c *Client) UpdateCollection(ctx context.Context, req *models.UpdateCollect
 		return err
 	}
 	if resp.Err {
-		return fmt.Errorf("update collection err:%s", resp.Msg)
+		return resp.AsError()
 	}
 	return nil
 }
@@ -124,6 +130,8 @@ func (c *Client) DelCollection(ctx context.Context, collId string) error {
 		Method:  http.MethodDelete,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s", c.BaseUrl, collId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("delete collection body:%s", body)
 	if err != nil {
@@ -137,7 +145,7 @@ func (c *Client) DelCollection(ctx context.Context, collId string) error {
 		return err
 	}
 	if resp.Err {
-		return fmt.Errorf("delete collection err:%s", resp.Msg)
+		return resp.AsError()
 	}
 	return nil
 }
@@ -147,6 +155,8 @@ func (c *Client) GetCollection(ctx context.Context, collId string) (*models.Coll
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s", c.BaseUrl, collId),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("get collection body:%s", body)
 	if err != nil {
@@ -160,7 +170,7 @@ func (c *Client) GetCollection(ctx context.Context, collId string) (*models.Coll
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("get collection err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.Collection
@@ -182,6 +192,8 @@ func (c *Client) CreateDocs(ctx context.Context, req *models.CreateDocsRequest)
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("create docs body:%s", body)
 	if err != nil {
@@ -195,7 +207,7 @@ func (c *Client) CreateDocs(ctx context.Context, req *models.CreateDocsRequest)
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("create docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -217,6 +229,8 @@ func (c *Client) UpdateDocs(ctx context.Context, req *models.UpdateDocsRequest)
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("update docs body:%s", body)
 	if err != nil {
@@ -230,7 +244,7 @@ func (c *Client) UpdateDocs(ctx context.Context, req *models.UpdateDocsRequest)
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("update docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -252,6 +266,8 @@ func (c *Client) UpsertDocs(ctx context.Context, req *models.UpsertVectorDocsPar
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs/upsert", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("upsert docs body:%s", body)
 	if err != nil {
@@ -265,7 +281,7 @@ func (c *Client) UpsertDocs(ctx context.Context, req *models.UpsertVectorDocsPar
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("upsert docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -287,6 +303,8 @@ func (c *Client) DelDocs(ctx context.Context, req *models.DeleteDocsRequest) (*m
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("del docs body:%s", body)
 	if err != nil {
@@ -300,7 +318,7 @@ func (c *Client) DelDocs(ctx context.Context, req *models.DeleteDocsRequest) (*m
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("del docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	var respData models.DocOpResponse
@@ -322,6 +340,8 @@ func (c *Client) QueryDocs(ctx context.Context, req *models.QueryVectorRequest)
 		Url:     fmt.Sprintf("%s/api/v1/vector/%s/docs/query", c.BaseUrl, req.CollId),
 		Body:    string(reqBody),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	log.Infof("query docs body:%s", body)
 	if err != nil {
@@ -335,7 +355,7 @@ func (c *Client) QueryDocs(ctx context.Context, req *models.QueryVectorRequest)
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("query docs err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	respData := make([]*models.Doc, 0)
@@ -362,6 +382,8 @@ func (c *Client) QueryDocsByIds(ctx context.Context, req *models.QueryDocsByIdsR
 	body, err := request2.Request(ctx, request2.ReqInfo{
 		Method: http.MethodGet,
 		Url:    u.String(),
+		ApiKey: c.ApiKey,
+		Client: c.client,
 	})
 	log.Infof("query docs byIds body: %s", body)
 	if err != nil {
@@ -375,7 +397,7 @@ func (c *Client) QueryDocsByIds(ctx context.Context, req *models.QueryDocsByIdsR
 		return nil, err
 	}
 	if resp.Err {
-		return nil, fmt.Errorf("query docs byIds err:%s", resp.Msg)
+		return nil, resp.AsError()
 	}
 	marshal, _ := json.Marshal(&resp.Data)
 	respData := make(map[string]*models.Doc)
@@ -6,6 +6,7 @@ import (
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
@@ -16,17 +17,22 @@ const (
 	keyValueDir = "kv_stores"
 	queueDir    = "queues_stores"
 	objectDir   = "objects_stores"
+	vectorDir   = "vector_stores"
 
 	metadataFile = "metadata.json"
 	inputJson    = "INPUT.json"
 	defaultDir   = "default"
 )
 
-var defaultLocalClient *LocalClient
-
-type LocalClient struct{}
+// LocalClient stores everything under the storage directory of the working
+// directory. Every LocalClient shares that directory. Until it is closed, a
+// LocalClient removes the expired KV keys in the background.
+type LocalClient struct {
+	stop      chan struct{}
+	closeOnce sync.Once
+}
 
-func Init() {
+func New() *LocalClient {
 	cwd, err := os.Getwd()
 	if err != nil {
 		panic("Unable to get the current working directory：" + err.Error())
@@ -36,11 +42,9 @@ func Init() {
 	if err != nil {
 		log.Warnf("warn create storage dir err: %v", err)
 	}
-	defaultLocalClient = &LocalClient{}
-}
-
-func Default() *LocalClient {
-	return defaultLocalClient
+	c := &LocalClient{stop: make(chan struct{})}
+	go runJanitor(c.stop)
+	return c
 }
 
 // EnsureDir Ensure that the directory exists (create if it does not exist)
@@ -55,6 +59,8 @@ func EnsureDir(storageDir string) error {
 	createMetadata(path, datasetDir)
 	path, err = createDir(absPath, objectDir)
 	createMetadata(path, objectDir)
+	path, err = createDir(absPath, vectorDir)
+	createMetadata(path, vectorDir)
 	createInput(absPath)
 	return err
 }
@@ -111,6 +117,19 @@ func createMetadata(path, category string) {
 			Size:        0,
 		}
 		meta, _ = json.MarshalIndent(bucket, "", "  ")
+	case vectorDir:
+		coll := models.Collection{
+			Id:          def,
+			Name:        def,
+			TeamId:      def,
+			ActorId:     def,
+			RunId:       def,
+			Description: def,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Metric:      defaultCollMetric,
+		}
+		meta, _ = json.MarshalIndent(coll, "", "  ")
 	}
 	exists := isFileExists(metaPath)
 	if !exists {
@@ -150,5 +169,6 @@ func createDir(absPath string, dirName string) (string, error) {
 }
 
 func (c *LocalClient) Close() error {
+	c.closeOnce.Do(func() { close(c.stop) })
 	return nil
 }
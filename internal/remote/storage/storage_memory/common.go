@@ -7,14 +7,38 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 )
 
 var (
-	ErrResourceNotFound          = errors.New("resource not found")
-	ErrResourceExists            = errors.New("resource exists")
-	ErrLocalStorageUnimplemented = errors.New("local storage unimplemented")
+	ErrResourceNotFound          = scerrors.New(scerrors.KindNotFound, "resource not found")
+	ErrResourceExists            = scerrors.New(scerrors.KindConflict, "resource exists")
+	ErrLocalStorageUnimplemented = scerrors.New(scerrors.KindNotImplemented, "local storage unimplemented")
 )
 
+// lockFileName is the lock file created in the directories passed to lockDir.
+const lockFileName = ".lock"
+
+var dirLocks sync.Map // directory path -> *sync.Mutex
+
+// lockDir serializes access to a storage directory between the goroutines of
+// this process and, through a lock file, between processes.
+func lockDir(dirPath string) (func(), error) {
+	mu, _ := dirLocks.LoadOrStore(dirPath, new(sync.Mutex))
+	mu.(*sync.Mutex).Lock()
+	unlockFile, err := lockFile(filepath.Join(dirPath, lockFileName))
+	if err != nil {
+		mu.(*sync.Mutex).Unlock()
+		return nil, fmt.Errorf("lock %s failed: %v", dirPath, err)
+	}
+	return func() {
+		unlockFile()
+		mu.(*sync.Mutex).Unlock()
+	}, nil
+}
+
 func isDirExists(path string) bool {
 	info, err := os.Stat(path)
 	if os.IsNotExist(err) {
@@ -31,17 +55,30 @@ func isFileExists(path string) bool {
 	return !info.IsDir()
 }
 
-func totalPage(total, pageSize int64) int64 {
+func totalPage[T ~int | ~int64](total, pageSize T) T {
+	if pageSize < 1 {
+		return min(total, 1)
+	}
 	return (total + pageSize - 1) / pageSize
 }
 
+// pageRange returns the bounds of a page within total items. Pages start at
+// 1; a page size below 1 selects everything.
+func pageRange[T ~int | ~int64](page, pageSize, total T) (start, end T) {
+	if pageSize < 1 {
+		return 0, total
+	}
+	start = min(max(page-1, 0)*pageSize, total)
+	return start, min(start+pageSize, total)
+}
+
 func isNameExists(path string, name string) (bool, error) {
 	err := filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
 		if !d.IsDir() {
 			return nil
 		}
 		if d.Name() == queueDir || d.Name() == datasetDir || d.Name() == keyValueDir ||
-			d.Name() == objectDir || d.Name() == metadataFile {
+			d.Name() == objectDir || d.Name() == vectorDir || d.Name() == metadataFile {
 			return nil
 		}
 		metaDataPath := filepath.Join(path, metadataFile)
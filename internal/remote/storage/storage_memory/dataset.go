@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"os"
 	"path/filepath"
@@ -61,14 +62,7 @@ func (c *LocalClient) ListDatasets(ctx context.Context, req *models.ListDatasets
 	total := int64(len(allDatasets))
 
 	// page
-	start := (req.Page - 1) * req.PageSize
-	if start > total {
-		start = total
-	}
-	end := start + req.PageSize
-	if end > total {
-		end = total
-	}
+	start, end := pageRange(req.Page, req.PageSize, total)
 
 	pagedItems := allDatasets[start:end]
 
@@ -77,7 +71,7 @@ func (c *LocalClient) ListDatasets(ctx context.Context, req *models.ListDatasets
 		Total:     total,
 		Page:      req.Page,
 		PageSize:  req.PageSize,
-		TotalPage: total/req.PageSize + 1,
+		TotalPage: totalPage(total, req.PageSize),
 	}, nil
 }
 
@@ -120,7 +114,7 @@ func (c *LocalClient) DelDataset(ctx context.Context, datasetID string) (bool, e
 
 func (c *LocalClient) GetDataset(ctx context.Context, req *models.GetDataset) (*models.DatasetItem, error) {
 	dirPath := filepath.Join(storageDir, datasetDir, req.DatasetId)
-	if isDirExists(dirPath) {
+	if !isDirExists(dirPath) {
 		return nil, ErrResourceNotFound
 	}
 	entries, err := os.ReadDir(dirPath)
@@ -131,7 +125,7 @@ func (c *LocalClient) GetDataset(ctx context.Context, req *models.GetDataset) (*
 	var files []os.DirEntry
 
 	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" || entry.Name() == metadataFile {
 			continue
 		}
 		files = append(files, entry)
@@ -148,14 +142,7 @@ func (c *LocalClient) GetDataset(ctx context.Context, req *models.GetDataset) (*
 	total := len(files)
 
 	// page
-	start := (req.Page - 1) * req.PageSize
-	if start > total {
-		start = total
-	}
-	end := start + req.PageSize
-	if end > total {
-		end = total
-	}
+	start, end := pageRange(req.Page, req.PageSize, total)
 
 	pagedFiles := files[start:end]
 
@@ -182,7 +169,7 @@ func (c *LocalClient) GetDataset(ctx context.Context, req *models.GetDataset) (*
 		Total:     total,
 		Page:      req.Page,
 		PageSize:  req.PageSize,
-		TotalPage: total/req.PageSize + 1,
+		TotalPage: totalPage(total, req.PageSize),
 	}, nil
 }
 
@@ -299,3 +286,9 @@ func updateMetadata(datasetId string, name string) (*models.Dataset, error) {
 	}
 	return meta, nil
 }
+
+// QueryDataset evaluates the query over the pages of the dataset, as the
+// http client does when the API cannot run it.
+func (c *LocalClient) QueryDataset(ctx context.Context, req *models.QueryDataset) (*models.QueryDatasetResult, error) {
+	return query.Run(ctx, req, c.GetDataset)
+}
@@ -3,18 +3,25 @@ package storage_memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
-	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 )
 
-const MaxExpireTime = 24 * 60 * 60 * 7
+const (
+	MaxExpireTime = 24 * 60 * 60 * 7
+
+	defaultScanCount = 10
+)
 
 func (c *LocalClient) GetNamespace(ctx context.Context, namespaceId string) (*models.KvNamespaceItem, error) {
 	nsPath := filepath.Join(storageDir, keyValueDir, namespaceId)
@@ -32,39 +39,7 @@ func (c *LocalClient) GetNamespace(ctx context.Context, namespaceId string) (*mo
 	if err = json.Unmarshal(file, &namespace); err != nil {
 		return nil, fmt.Errorf("json unmarshal failed: %s", err)
 	}
-	now := time.Now()
-	stats := models.Stats{}
-	_ = filepath.WalkDir(nsPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() || d.Name() == metadataFile {
-			return nil
-		}
-		kvFile, err := os.ReadFile(filepath.Join(nsPath, d.Name()))
-		if err != nil {
-			log.Warnf("read file %s failed: %v", path, err)
-			return nil
-		}
-		if d.Name() == "INPUT.json" {
-			stats.Size += uint64(len(kvFile))
-			stats.Count++
-			return nil
-		}
-		var kv models.SetValueLocal
-		err = json.Unmarshal(kvFile, &kv)
-		if err != nil {
-			log.Warnf("json unmarshal failed: %s", err)
-			return nil
-		}
-		if kv.ExpireAt.Before(now) {
-			return nil
-		}
-		stats.Count++
-		stats.Size += uint64(kv.Size)
-		return nil
-	})
-	namespace.Stats = stats
+	namespace.Stats = kvStats(namespaceId)
 
 	return &namespace, nil
 }
@@ -111,52 +86,11 @@ func (c *LocalClient) ListNamespaces(ctx context.Context, page int64, pageSize i
 	total := int64(len(allNamespaces))
 
 	// page
-	start := (page - 1) * pageSize
-	if start > total {
-		start = total
-	}
-	end := start + pageSize
-	if end > total {
-		end = total
-	}
+	start, end := pageRange(page, pageSize, total)
 
 	pagedItems := allNamespaces[start:end]
-	now := time.Now()
 	for i := range pagedItems {
-		stats := models.Stats{}
-		nsPath := filepath.Join(dirPath, pagedItems[i].Id)
-		_ = filepath.WalkDir(nsPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
-			if d.IsDir() || d.Name() == metadataFile {
-				return nil
-			}
-
-			kvFile, err := os.ReadFile(path)
-			if err != nil {
-				log.Warnf("read file %s failed: %v", path, err)
-				return nil
-			}
-			if d.Name() == "INPUT.json" {
-				stats.Size += uint64(len(kvFile))
-				stats.Count++
-				return nil
-			}
-			var kv models.SetValueLocal
-			err = json.Unmarshal(kvFile, &kv)
-			if err != nil {
-				log.Warnf("json unmarshal failed: %s", err)
-				return nil
-			}
-			if kv.ExpireAt.Before(now) {
-				return nil
-			}
-			stats.Count++
-			stats.Size += uint64(kv.Size)
-			return nil
-		})
-		pagedItems[i].Stats = stats
+		pagedItems[i].Stats = kvStats(pagedItems[i].Id)
 	}
 
 	return &models.KvNamespace{
@@ -208,7 +142,13 @@ func (c *LocalClient) CreateNamespace(ctx context.Context, req *models.CreateKvN
 
 func (c *LocalClient) DelNamespace(ctx context.Context, namespaceId string) (bool, error) {
 	absPath := filepath.Join(storageDir, keyValueDir, namespaceId)
-	err := os.RemoveAll(absPath)
+	unlock, err := lockDir(absPath)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	err = os.RemoveAll(absPath)
+	forgetKV(absPath)
 	if err != nil {
 		return false, fmt.Errorf("delete namespace failed, cause: %v", err)
 	}
@@ -249,67 +189,39 @@ func (c *LocalClient) SetValue(ctx context.Context, req *models.SetValue) (bool,
 	if req.Key == "INPUT" && req.NamespaceId == "default" {
 		return false, nil
 	}
-	keyFile := fmt.Sprintf("%s.json", req.Key)
-	if keyFile == metadataFile {
-		return false, fmt.Errorf("key name can't use 'metadata'")
+	if err := checkKey(req.Key); err != nil {
+		return false, err
 	}
-	path := filepath.Join(storageDir, keyValueDir, req.NamespaceId)
-	file := filepath.Join(path, keyFile)
-	if req.Expiration == 0 {
-		req.Expiration = MaxExpireTime
+	// Like the hosted store, a write does not need the namespace to be
+	// listed first.
+	nsPath := filepath.Join(storageDir, keyValueDir, req.NamespaceId)
+	if err := os.MkdirAll(nsPath, os.ModePerm); err != nil {
+		return false, fmt.Errorf("create namespace dir failed: %v", err)
 	}
-	local := models.SetValueLocal{
-		SetValue: models.SetValue{
-			Expiration:  req.Expiration,
-			Key:         req.Key,
-			Value:       req.Value,
-			NamespaceId: req.NamespaceId,
-		},
-		ExpireAt: time.Now().Add(time.Duration(req.Expiration) * time.Second),
-		Size:     len([]byte(req.Value)),
-	}
-
-	marshal, err := json.Marshal(local)
+	err := withKV(req.NamespaceId, func(nsPath string, ix *kvIndex) error {
+		return ix.write(nsPath, newKVRecord(req.NamespaceId, req.Key, req.Value, req.Expiration, time.Now()))
+	})
 	if err != nil {
-		return false, fmt.Errorf("json marshal failed: %s", err)
+		return false, err
 	}
-	err = os.WriteFile(file, marshal, os.ModePerm)
-
 	return true, nil
 }
 
 func (c *LocalClient) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*models.KvKeys, error) {
-	dirPath := filepath.Join(storageDir, keyValueDir, req.NamespaceId)
 	var keys []map[string]any
-
-	now := time.Now()
-	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() || d.Name() == metadataFile {
-			return nil
-		}
-
-		kvFile, err := os.ReadFile(filepath.Join(dirPath, d.Name()))
-		if err != nil {
-			return fmt.Errorf("read file %s failed: %v", path, err)
-		}
-		var kv models.SetValueLocal
-		err = json.Unmarshal(kvFile, &kv)
-		if err != nil {
-			return fmt.Errorf("json unmarshal failed: %s", err)
+	err := withKV(req.NamespaceId, func(nsPath string, ix *kvIndex) error {
+		if info, err := os.Stat(filepath.Join(nsPath, inputJson)); err == nil {
+			keys = append(keys, map[string]any{
+				"key":  "INPUT",
+				"size": int(info.Size()),
+			})
 		}
-
-		if kv.ExpireAt.Before(now) {
-			return nil
+		for _, entry := range ix.live(time.Now()) {
+			keys = append(keys, map[string]any{
+				"key":  entry.Key,
+				"size": entry.Size,
+			})
 		}
-
-		keys = append(keys, map[string]any{
-			"key":  kv.Key,
-			"size": kv.Size,
-		})
-
 		return nil
 	})
 	if err != nil {
@@ -323,82 +235,125 @@ func (c *LocalClient) ListKeys(ctx context.Context, req *models.ListKeyInfo) (*m
 		TotalPage: totalPage(total, req.Size),
 	}
 
-	start := (req.Page - 1) * req.Size
-	if start >= total {
-		return kvKeys, nil
-	}
-
-	end := start + req.Size
-	if end > total {
-		end = total
-	}
+	start, end := pageRange(req.Page, req.Size, total)
 	kvKeys.Items = keys[start:end]
 	return kvKeys, nil
 }
 
-func (c *LocalClient) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64, error) {
-	var success int64
-	for i := range req.Items {
-		ok, _ := c.SetValue(ctx, &models.SetValue{
-			NamespaceId: req.NamespaceId,
-			Key:         req.Items[i].Key,
-			Value:       req.Items[i].Value,
-			Expiration:  req.Items[i].Expiration,
+// ScanKeys walks the live keys in key order. The cursor is the last key
+// returned, so keys written during a scan are seen when they sort after it.
+func (c *LocalClient) ScanKeys(ctx context.Context, req *models.ScanKeys) (*models.ScanKeysResult, error) {
+	count := int(req.Count)
+	if count < 1 {
+		count = defaultScanCount
+	}
+	prefix := helper.GlobPrefix(req.Match)
+	result := &models.ScanKeysResult{}
+	err := withKV(req.NamespaceId, func(nsPath string, ix *kvIndex) error {
+		var keys []models.KeyInfo
+		if info, err := os.Stat(filepath.Join(nsPath, inputJson)); err == nil {
+			keys = append(keys, models.KeyInfo{Key: "INPUT", Size: info.Size()})
+		}
+		for _, entry := range ix.live(time.Now()) {
+			keys = append(keys, models.KeyInfo{Key: entry.Key, Size: int64(entry.Size), ExpireAt: entry.ExpireAt})
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Key < keys[j].Key })
+
+		start := sort.Search(len(keys), func(i int) bool {
+			return keys[i].Key > req.Cursor && keys[i].Key >= prefix
 		})
-		if ok {
-			success++
+		for _, key := range keys[start:] {
+			if !strings.HasPrefix(key.Key, prefix) {
+				break
+			}
+			if req.Match != "" && !helper.GlobMatch(req.Match, key.Key) {
+				continue
+			}
+			if len(result.Items) == count {
+				result.Cursor = result.Items[count-1].Key
+				break
+			}
+			result.Items = append(result.Items, key)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return success, nil
+	return result, nil
 }
 
-func (c *LocalClient) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
-	file := fmt.Sprintf("%s.json", key)
-	if file == metadataFile {
-		return true, nil
+func (c *LocalClient) BulkSetValue(ctx context.Context, req *models.BulkSet) (int64, error) {
+	now := time.Now()
+	recs := make([]kvRecord, 0, len(req.Items))
+	for _, item := range req.Items {
+		if (item.Key == "INPUT" && req.NamespaceId == "default") || checkKey(item.Key) != nil {
+			continue
+		}
+		recs = append(recs, newKVRecord(req.NamespaceId, item.Key, item.Value, item.Expiration, now))
 	}
-	path := filepath.Join(storageDir, keyValueDir, namespaceId, file)
-	err := os.Remove(path)
+	err := withKV(req.NamespaceId, func(nsPath string, ix *kvIndex) error {
+		return ix.write(nsPath, recs...)
+	})
 	if err != nil {
-		return false, fmt.Errorf("delete file %s failed: %v", path, err)
+		return 0, err
 	}
+	return int64(len(recs)), nil
+}
 
-	return true, nil
+func (c *LocalClient) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
+	return c.BulkDelValue(ctx, namespaceId, []string{key})
 }
 
 func (c *LocalClient) BulkDelValue(ctx context.Context, namespaceId string, keys []string) (bool, error) {
-	for i := range keys {
-		_, err := c.DelValue(ctx, namespaceId, keys[i])
-		if err != nil {
-			return false, err
+	err := withKV(namespaceId, func(nsPath string, ix *kvIndex) error {
+		var recs []kvRecord
+		for _, key := range keys {
+			if _, ok := ix.entries[key]; ok {
+				recs = append(recs, kvRecord{SetValueLocal: models.SetValueLocal{SetValue: models.SetValue{Key: key}}, Deleted: true})
+			}
+		}
+		if len(recs) == 0 {
+			return nil
 		}
+		return ix.write(nsPath, recs...)
+	})
+	if err != nil {
+		return false, err
 	}
 	return true, nil
 }
 
 func (c *LocalClient) GetValue(ctx context.Context, namespaceId string, key string) (string, error) {
-	namespacePath := filepath.Join(storageDir, keyValueDir, namespaceId)
-	if !isDirExists(namespacePath) {
-		return "", ErrResourceNotFound
-	}
-	file := fmt.Sprintf("%s.json", key)
-	path := filepath.Join(namespacePath, file)
-	buff, err := os.ReadFile(path)
-	if err != nil {
-		return "", fmt.Errorf("read file %s failed: %v", path, err)
-	}
-
 	if key == "INPUT" && namespaceId == "default" {
+		path := filepath.Join(storageDir, keyValueDir, namespaceId, inputJson)
+		buff, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", ErrResourceNotFound
+		}
+		if err != nil {
+			return "", fmt.Errorf("read file %s failed: %v", path, err)
+		}
 		return string(buff), nil
 	}
+	var value string
+	err := withKV(namespaceId, func(nsPath string, ix *kvIndex) error {
+		entry := ix.get(key, time.Now())
+		if entry == nil {
+			return ErrResourceNotFound
+		}
+		value = entry.Value
+		return nil
+	})
+	return value, err
+}
 
-	var kv models.SetValueLocal
-	if err := json.Unmarshal(buff, &kv); err != nil {
-		return "", fmt.Errorf("json unmarshal failed: %s", err)
+func checkKey(key string) error {
+	if key == "" {
+		return scerrors.New(scerrors.KindInvalidArgument, "key is empty")
 	}
-	if kv.ExpireAt.Before(time.Now()) {
-		return "", nil
+	if key == "metadata" {
+		return scerrors.New(scerrors.KindInvalidArgument, "key name can't use 'metadata'")
 	}
-	return kv.Value, nil
+	return nil
 }
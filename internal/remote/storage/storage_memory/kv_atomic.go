@@ -0,0 +1,89 @@
+package storage_memory
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// The operations below hold the namespace lock for the whole
+// read-modify-write, so they are atomic between goroutines and between
+// processes sharing the storage directory.
+
+func (c *LocalClient) CompareAndSwap(ctx context.Context, req *models.CompareAndSwap) (bool, error) {
+	var swapped bool
+	err := updateKV(req.NamespaceId, req.Key, func(nsPath string, ix *kvIndex, kv *models.SetValueLocal, now time.Time) error {
+		if kv == nil || kv.Value != req.Old {
+			return nil
+		}
+		swapped = true
+		return ix.write(nsPath, newKVRecord(req.NamespaceId, req.Key, req.New, req.Expiration, now))
+	})
+	return swapped && err == nil, err
+}
+
+func (c *LocalClient) Increment(ctx context.Context, req *models.Increment) (int64, error) {
+	var value int64
+	err := updateKV(req.NamespaceId, req.Key, func(nsPath string, ix *kvIndex, kv *models.SetValueLocal, now time.Time) error {
+		expiration := req.Expiration
+		if kv != nil {
+			current, err := strconv.ParseInt(kv.Value, 10, 64)
+			if err != nil {
+				return scerrors.Newf(scerrors.KindInvalidArgument, "value of %s is not an integer", req.Key)
+			}
+			value = current
+			if expiration == 0 {
+				// Keep the lifetime of an existing counter.
+				expiration = uint(max((kv.ExpireAt.Sub(now)+time.Second-1)/time.Second, 1))
+			}
+		}
+		value += req.Delta
+		return ix.write(nsPath, newKVRecord(req.NamespaceId, req.Key, strconv.FormatInt(value, 10), expiration, now))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+func (c *LocalClient) SetNX(ctx context.Context, req *models.SetValue) (bool, error) {
+	var set bool
+	err := updateKV(req.NamespaceId, req.Key, func(nsPath string, ix *kvIndex, kv *models.SetValueLocal, now time.Time) error {
+		if kv != nil {
+			return nil
+		}
+		set = true
+		return ix.write(nsPath, newKVRecord(req.NamespaceId, req.Key, req.Value, req.Expiration, now))
+	})
+	return set && err == nil, err
+}
+
+func (c *LocalClient) GetAndDelete(ctx context.Context, namespaceId string, key string) (string, error) {
+	var value string
+	err := updateKV(namespaceId, key, func(nsPath string, ix *kvIndex, kv *models.SetValueLocal, now time.Time) error {
+		if kv == nil {
+			return ErrResourceNotFound
+		}
+		value = kv.Value
+		return ix.write(nsPath, kvRecord{SetValueLocal: models.SetValueLocal{SetValue: models.SetValue{Key: key}}, Deleted: true})
+	})
+	return value, err
+}
+
+// updateKV calls fn with the current value of key, nil when it is missing or
+// expired, while holding the namespace lock.
+func updateKV(namespaceId, key string, fn func(nsPath string, ix *kvIndex, kv *models.SetValueLocal, now time.Time) error) error {
+	if key == "INPUT" && namespaceId == "default" {
+		return scerrors.New(scerrors.KindInvalidArgument, "the INPUT key is read-only")
+	}
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	return withKV(namespaceId, func(nsPath string, ix *kvIndex) error {
+		now := time.Now()
+		return fn(nsPath, ix, ix.get(key, now), now)
+	})
+}
@@ -0,0 +1,391 @@
+package storage_memory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// A namespace keeps all of its keys in one append-only log, kvLogFile. Every
+// write appends a record; the latest record of a key wins. The log is
+// indexed in memory and the index follows the appends made by other
+// processes by reading the log from where it stopped. Once the log holds
+// more dead records than live ones it is compacted, that is rewritten with
+// the live keys only.
+//
+// Namespaces written by older versions, one <key>.json file per key, are
+// moved into the log when first opened. The INPUT.json file of the default
+// namespace is not a key record and stays as is.
+
+const (
+	kvLogFile = "data.jsonl"
+
+	// compactMinDead is the number of dead records below which a log is
+	// never compacted, so that small namespaces are not rewritten on every
+	// write.
+	compactMinDead = 256
+
+	kvSweepInterval = time.Minute
+)
+
+// kvRecord is a line of the log.
+type kvRecord struct {
+	models.SetValueLocal
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// kvIndex holds the live keys of a namespace log. It is only used while the
+// namespace lock is held.
+type kvIndex struct {
+	info    os.FileInfo // Log the index was built from, nil when there is none
+	offset  int64       // Bytes of the log already indexed
+	records int         // Records in the log, live or dead
+	entries map[string]*models.SetValueLocal
+}
+
+var kvIndexes sync.Map // namespace path -> *kvIndex
+
+// openKV returns the up-to-date index of the namespace at nsPath. The caller
+// must hold the namespace lock.
+func openKV(nsPath string) (*kvIndex, error) {
+	logPath := filepath.Join(nsPath, kvLogFile)
+	info, err := os.Stat(logPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("stat %s failed: %v", logPath, err)
+	}
+	if v, ok := kvIndexes.Load(nsPath); ok {
+		ix := v.(*kvIndex)
+		switch {
+		case info == nil && ix.info == nil:
+			return ix, nil
+		case info != nil && ix.info != nil && os.SameFile(info, ix.info) && info.Size() >= ix.offset:
+			if info.Size() > ix.offset {
+				if err = ix.load(logPath); err != nil {
+					return nil, err
+				}
+			}
+			ix.info = info
+			return ix, nil
+		}
+	}
+
+	// First use, or the log was replaced by a compaction or removed.
+	ix := &kvIndex{entries: map[string]*models.SetValueLocal{}}
+	if info != nil {
+		if err = ix.load(logPath); err != nil {
+			return nil, err
+		}
+		ix.info = info
+	}
+	if err = ix.migrate(nsPath); err != nil {
+		return nil, err
+	}
+	kvIndexes.Store(nsPath, ix)
+	return ix, nil
+}
+
+// forgetKV drops the index of a namespace that was deleted.
+func forgetKV(nsPath string) {
+	kvIndexes.Delete(nsPath)
+}
+
+// load indexes the records of the log from ix.offset. A last line without a
+// newline is a write in progress and is left for the next call.
+func (ix *kvIndex) load(logPath string) error {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return fmt.Errorf("open %s failed: %v", logPath, err)
+	}
+	defer f.Close()
+	if _, err = f.Seek(ix.offset, io.SeekStart); err != nil {
+		return fmt.Errorf("seek %s failed: %v", logPath, err)
+	}
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read %s failed: %v", logPath, err)
+		}
+		ix.offset += int64(len(line))
+		var rec kvRecord
+		if err = json.Unmarshal(line, &rec); err != nil {
+			log.Warnf("skip corrupted record in %s: %v", logPath, err)
+			continue
+		}
+		ix.apply(rec)
+	}
+}
+
+func (ix *kvIndex) apply(rec kvRecord) {
+	ix.records++
+	if rec.Deleted {
+		delete(ix.entries, rec.Key)
+		return
+	}
+	entry := rec.SetValueLocal
+	ix.entries[rec.Key] = &entry
+}
+
+// get returns the entry of key, nil when it is missing or expired.
+func (ix *kvIndex) get(key string, now time.Time) *models.SetValueLocal {
+	entry := ix.entries[key]
+	if entry == nil || entry.ExpireAt.Before(now) {
+		return nil
+	}
+	return entry
+}
+
+// live returns the keys that have not expired, sorted.
+func (ix *kvIndex) live(now time.Time) []*models.SetValueLocal {
+	entries := make([]*models.SetValueLocal, 0, len(ix.entries))
+	for _, entry := range ix.entries {
+		if !entry.ExpireAt.Before(now) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// write appends recs to the log in a single write and indexes them.
+func (ix *kvIndex) write(nsPath string, recs ...kvRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range recs {
+		if err := enc.Encode(rec); err != nil {
+			return fmt.Errorf("json marshal failed: %s", err)
+		}
+	}
+	logPath := filepath.Join(nsPath, kvLogFile)
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open %s failed: %v", logPath, err)
+	}
+	_, err = f.Write(buf.Bytes())
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write %s failed: %v", logPath, err)
+	}
+	for _, rec := range recs {
+		ix.apply(rec)
+	}
+	if ix.info, err = os.Stat(logPath); err != nil {
+		return fmt.Errorf("stat %s failed: %v", logPath, err)
+	}
+	ix.offset = ix.info.Size()
+
+	if dead := ix.records - len(ix.entries); dead >= compactMinDead && dead > len(ix.entries) {
+		return ix.compact(nsPath, time.Now())
+	}
+	return nil
+}
+
+// compact rewrites the log with the keys alive at now.
+func (ix *kvIndex) compact(nsPath string, now time.Time) error {
+	live := ix.live(now)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, entry := range live {
+		if err := enc.Encode(kvRecord{SetValueLocal: *entry}); err != nil {
+			return fmt.Errorf("json marshal failed: %s", err)
+		}
+	}
+	logPath := filepath.Join(nsPath, kvLogFile)
+	tmp := logPath + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write file %s failed: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, logPath); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write file %s failed: %v", logPath, err)
+	}
+	info, err := os.Stat(logPath)
+	if err != nil {
+		return fmt.Errorf("stat %s failed: %v", logPath, err)
+	}
+	ix.info, ix.offset, ix.records = info, info.Size(), len(live)
+	ix.entries = make(map[string]*models.SetValueLocal, len(live))
+	for _, entry := range live {
+		ix.entries[entry.Key] = entry
+	}
+	return nil
+}
+
+// migrate moves the <key>.json files of older versions into the log.
+func (ix *kvIndex) migrate(nsPath string) error {
+	entries, err := os.ReadDir(nsPath)
+	if err != nil {
+		return fmt.Errorf("read dir failed: %v", err)
+	}
+	var (
+		recs  []kvRecord
+		files []string
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == metadataFile || name == inputJson || filepath.Ext(name) != ".json" {
+			continue
+		}
+		path := filepath.Join(nsPath, name)
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read file %s failed: %v", path, err)
+		}
+		var kv models.SetValueLocal
+		if err = json.Unmarshal(buf, &kv); err != nil {
+			log.Warnf("skip unreadable kv file %s: %v", path, err)
+			continue
+		}
+		if kv.Key == "" {
+			kv.Key = name[:len(name)-len(".json")]
+		}
+		// Keys written since the log exists are newer than the old files.
+		if _, ok := ix.entries[kv.Key]; !ok {
+			recs = append(recs, kvRecord{SetValueLocal: kv})
+		}
+		files = append(files, path)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	if len(recs) > 0 {
+		if err = ix.write(nsPath, recs...); err != nil {
+			return err
+		}
+	}
+	for _, path := range files {
+		if err = os.Remove(path); err != nil {
+			log.Warnf("remove migrated kv file %s failed: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// newKVRecord builds the record setting key, capping the lifetime at
+// MaxExpireTime; 0 also means MaxExpireTime.
+func newKVRecord(namespaceId, key, value string, expiration uint, now time.Time) kvRecord {
+	if expiration == 0 || expiration > MaxExpireTime {
+		expiration = MaxExpireTime
+	}
+	return kvRecord{SetValueLocal: models.SetValueLocal{
+		SetValue: models.SetValue{
+			Expiration:  expiration,
+			Key:         key,
+			Value:       value,
+			NamespaceId: namespaceId,
+		},
+		ExpireAt: now.Add(time.Duration(expiration) * time.Second),
+		Size:     len(value),
+	}}
+}
+
+// withKV runs fn with the index of a namespace while holding its lock.
+func withKV(namespaceId string, fn func(nsPath string, ix *kvIndex) error) error {
+	nsPath := filepath.Join(storageDir, keyValueDir, namespaceId)
+	if !isDirExists(nsPath) {
+		return ErrResourceNotFound
+	}
+	unlock, err := lockDir(nsPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	ix, err := openKV(nsPath)
+	if err != nil {
+		return err
+	}
+	return fn(nsPath, ix)
+}
+
+// kvStats counts the live keys of a namespace and their size.
+func kvStats(namespaceId string) models.Stats {
+	var stats models.Stats
+	err := withKV(namespaceId, func(nsPath string, ix *kvIndex) error {
+		for _, entry := range ix.live(time.Now()) {
+			stats.Count++
+			stats.Size += uint64(entry.Size)
+		}
+		if info, err := os.Stat(filepath.Join(nsPath, inputJson)); err == nil {
+			stats.Count++
+			stats.Size += uint64(info.Size())
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warnf("count keys of namespace %s failed: %v", namespaceId, err)
+	}
+	return stats
+}
+
+// sweepKV removes the expired keys of every namespace and caps lifetimes
+// longer than MaxExpireTime, by compacting their logs. Logs holding only
+// overwritten or deleted records are left to write, unless there are at
+// least compactMinDead of them.
+func sweepKV(now time.Time) error {
+	dirPath := filepath.Join(storageDir, keyValueDir)
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return fmt.Errorf("failed to read dir: %v", err)
+	}
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		err := withKV(entry.Name(), func(nsPath string, ix *kvIndex) error {
+			capped := false
+			limit := now.Add(MaxExpireTime * time.Second)
+			for _, e := range ix.entries {
+				if e.ExpireAt.After(limit) {
+					e.ExpireAt, e.Expiration = limit, MaxExpireTime
+					capped = true
+				}
+			}
+			expired := len(ix.live(now)) < len(ix.entries)
+			if capped || expired || ix.records-len(ix.entries) >= compactMinDead {
+				return ix.compact(nsPath, now)
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, ErrResourceNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runJanitor sweeps the KV namespaces every kvSweepInterval until stop is
+// closed.
+func runJanitor(stop <-chan struct{}) {
+	ticker := time.NewTicker(kvSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if err := sweepKV(now); err != nil {
+				log.Warnf("sweep expired kv keys failed: %v", err)
+			}
+		}
+	}
+}
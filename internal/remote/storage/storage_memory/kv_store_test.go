@@ -0,0 +1,175 @@
+package storage_memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+func createTestNamespace(t *testing.T) (string, string) {
+	t.Helper()
+	id, err := local.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{Name: "ns"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id, filepath.Join(storageDir, keyValueDir, id)
+}
+
+func logLines(t *testing.T, nsPath string) int {
+	t.Helper()
+	buf, err := os.ReadFile(filepath.Join(nsPath, kvLogFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Count(buf, []byte("\n"))
+}
+
+func TestKVMigratesKeyFiles(t *testing.T) {
+	useTempStorage(t)
+	id, nsPath := createTestNamespace(t)
+	now := time.Now()
+	for key, expireAt := range map[string]time.Time{"live": now.Add(time.Hour), "stale": now.Add(-time.Hour)} {
+		buf, _ := json.Marshal(models.SetValueLocal{
+			SetValue: models.SetValue{Key: key, Value: "v-" + key, NamespaceId: id},
+			ExpireAt: expireAt,
+			Size:     len("v-" + key),
+		})
+		if err := os.WriteFile(filepath.Join(nsPath, key+".json"), buf, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keys, err := local.ListKeys(ctx, &models.ListKeyInfo{NamespaceId: id, Page: 1, Size: 10})
+	if err != nil || keys.Total != 1 || keys.Items[0]["key"] != "live" {
+		t.Fatalf("keys after migration: %+v, %v", keys, err)
+	}
+	if _, err = os.Stat(filepath.Join(nsPath, "live.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("migrated key files should be removed")
+	}
+	if v, err := local.GetValue(ctx, id, "live"); err != nil || v != "v-live" {
+		t.Fatalf("migrated value: %q, %v", v, err)
+	}
+	if _, err = local.GetValue(ctx, id, "stale"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("expired value: %v", err)
+	}
+
+	input, err := local.ListKeys(ctx, &models.ListKeyInfo{NamespaceId: defaultDir, Page: 1, Size: 10})
+	if err != nil || input.Total != 1 || input.Items[0]["key"] != "INPUT" {
+		t.Fatalf("default namespace keys: %+v, %v", input, err)
+	}
+}
+
+func TestKVCompaction(t *testing.T) {
+	useTempStorage(t)
+	id, nsPath := createTestNamespace(t)
+	for i := 0; i < 3*compactMinDead; i++ {
+		if _, err := local.Increment(ctx, &models.Increment{NamespaceId: id, Key: "counter", Delta: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := local.SetValue(ctx, &models.SetValue{NamespaceId: id, Key: "other", Value: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := logLines(t, nsPath); n > compactMinDead+2 {
+		t.Fatalf("log was not compacted: %d records", n)
+	}
+	if v, err := local.GetValue(ctx, id, "counter"); err != nil || v != "768" {
+		t.Fatalf("counter: %q, %v", v, err)
+	}
+}
+
+func TestKVFollowsOtherWriters(t *testing.T) {
+	useTempStorage(t)
+	id, nsPath := createTestNamespace(t)
+	if _, err := local.SetValue(ctx, &models.SetValue{NamespaceId: id, Key: "a", Value: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process appends to the log.
+	f, err := os.OpenFile(filepath.Join(nsPath, kvLogFile), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = json.NewEncoder(f).Encode(newKVRecord(id, "b", "2", 0, time.Now()))
+	_ = json.NewEncoder(f).Encode(kvRecord{SetValueLocal: models.SetValueLocal{SetValue: models.SetValue{Key: "a"}}, Deleted: true})
+	_ = f.Close()
+
+	if v, err := local.GetValue(ctx, id, "b"); err != nil || v != "2" {
+		t.Fatalf("appended value: %q, %v", v, err)
+	}
+	if _, err = local.GetValue(ctx, id, "a"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("deleted value: %v", err)
+	}
+
+	// And then replaces it by compacting.
+	if err = os.WriteFile(filepath.Join(nsPath, kvLogFile+".new"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Rename(filepath.Join(nsPath, kvLogFile+".new"), filepath.Join(nsPath, kvLogFile)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = local.GetValue(ctx, id, "b"); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("value of a replaced log: %v", err)
+	}
+}
+
+func TestSweepKV(t *testing.T) {
+	useTempStorage(t)
+	id, nsPath := createTestNamespace(t)
+	now := time.Now()
+	far := newKVRecord(id, "far", "x", 0, now)
+	far.ExpireAt = now.Add(30 * 24 * time.Hour)
+	err := withKV(id, func(nsPath string, ix *kvIndex) error {
+		return ix.write(nsPath,
+			newKVRecord(id, "expired", "x", 1, now.Add(-time.Hour)),
+			newKVRecord(id, "live", "x", 60, now),
+			far,
+		)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = local.SetValue(ctx, &models.SetValue{NamespaceId: id, Key: "capped", Value: "x", Expiration: 10 * MaxExpireTime}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = sweepKV(now); err != nil {
+		t.Fatal(err)
+	}
+	if n := logLines(t, nsPath); n != 3 {
+		t.Fatalf("%d records after the sweep, want 3", n)
+	}
+	limit := now.Add(MaxExpireTime*time.Second + time.Second)
+	err = withKV(id, func(nsPath string, ix *kvIndex) error {
+		for _, key := range []string{"far", "capped"} {
+			if e := ix.get(key, now); e == nil || e.ExpireAt.After(limit) || e.Expiration != MaxExpireTime {
+				t.Errorf("%s was not capped: %+v", key, e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ns, err := local.GetNamespace(ctx, id)
+	if err != nil || ns.Stats.Count != 3 {
+		t.Fatalf("stats: %+v, %v", ns, err)
+	}
+
+	// A few overwritten records are not worth rewriting the log for.
+	if _, err = local.SetValue(ctx, &models.SetValue{NamespaceId: id, Key: "live", Value: "y", Expiration: 60}); err != nil {
+		t.Fatal(err)
+	}
+	if err = sweepKV(now); err != nil {
+		t.Fatal(err)
+	}
+	if n := logLines(t, nsPath); n != 4 {
+		t.Fatalf("%d records after sweeping an overwrite, want 4", n)
+	}
+}
@@ -3,19 +3,16 @@ package storage_memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
 	"testing"
 )
 
-func init() {
-	Init()
-}
-
 var (
 	datasetId   = "123456"
 	NamespaceId = "1245434234"
-	local       = LocalClient{}
+	local       = New()
 	ctx         = context.Background()
 )
 
@@ -282,3 +279,29 @@ func TestAckMsg(t *testing.T) {
 	}
 
 }
+
+func TestGetDatasetPages(t *testing.T) {
+	dataset, err := local.CreateDataset(ctx, &models.CreateDatasetRequest{Name: "test-pages"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.DelDataset(ctx, dataset.Id)
+	items := make([]map[string]any, 25)
+	for i := range items {
+		items[i] = map[string]any{"i": i}
+	}
+	if _, err = local.AddDatasetItem(ctx, dataset.Id, items); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := local.GetDataset(ctx, &models.GetDataset{DatasetId: dataset.Id, Page: 3, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 5 || page.Total != 25 || page.TotalPage != 3 || page.Items[0]["i"] != float64(20) {
+		t.Fatalf("unexpected page: %d items, total %d, %d pages", len(page.Items), page.Total, page.TotalPage)
+	}
+	if _, err = local.GetDataset(ctx, &models.GetDataset{DatasetId: "missing", Page: 1, PageSize: 10}); !errors.Is(err, ErrResourceNotFound) {
+		t.Fatalf("want not found, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+//go:build !unix
+
+package storage_memory
+
+import (
+	"os"
+	"time"
+)
+
+const (
+	lockRetryInterval = 10 * time.Millisecond
+	// A lock file older than this was left behind by a crashed process.
+	staleLockAge = 30 * time.Second
+)
+
+// lockFile creates path exclusively, waiting while another process holds it.
+// The lock is released by removing the file.
+func lockFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(path)
+			continue
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
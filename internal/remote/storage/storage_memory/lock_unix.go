@@ -0,0 +1,25 @@
+//go:build unix
+
+package storage_memory
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on path, blocking until it is free.
+// The lock is released by the returned function or when the process exits.
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
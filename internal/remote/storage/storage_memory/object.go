@@ -1,38 +1,514 @@
 package storage_memory
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// A bucket directory holds its metadata, the metadata of every object in
+// objectMetaDir, their content in objectDataDir and the parts of unfinished
+// multipart uploads in objectUploadDir.
+const (
+	objectMetaDir   = "objects"
+	objectDataDir   = "data"
+	objectUploadDir = "uploads"
+	uploadFile      = "upload.json"
 )
 
+func bucketPath(bucketId string) string {
+	return filepath.Join(storageDir, objectDir, bucketId)
+}
+
 func (c *LocalClient) ListBuckets(ctx context.Context, page, size int) (*models.Object, error) {
-	return nil, ErrLocalStorageUnimplemented
+	entries, err := os.ReadDir(filepath.Join(storageDir, objectDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %v", err)
+	}
+	var buckets []models.Bucket
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		bucket, err := readBucket(entry.Name())
+		if err != nil {
+			continue
+		}
+		buckets = append(buckets, *bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].CreatedAt < buckets[j].CreatedAt
+	})
+
+	total := int64(len(buckets))
+	start, end := pageRange(int64(page), int64(size), total)
+	return &models.Object{
+		Buckets:   buckets[start:end],
+		Total:     total,
+		TotalPage: totalPage(total, int64(size)),
+		Page:      int64(page),
+		PageSize:  int64(size),
+	}, nil
 }
 
 func (c *LocalClient) CreateBucket(ctx context.Context, req *models.CreateBucketRequest) (string, error) {
-	return "", ErrLocalStorageUnimplemented
+	list, err := c.ListBuckets(ctx, 1, 0)
+	if err != nil {
+		return "", err
+	}
+	for _, bucket := range list.Buckets {
+		if bucket.Name == req.Name {
+			return "", scerrors.Newf(scerrors.KindConflict, "bucket %s already exists", req.Name)
+		}
+	}
+
+	id := uuid.NewString()
+	if err = os.MkdirAll(bucketPath(id), os.ModePerm); err != nil {
+		return "", fmt.Errorf("create bucket failed, cause: %v", err)
+	}
+	now := time.Now().Format(time.RFC3339Nano)
+	bucket := models.Bucket{
+		Id:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	marshal, err := json.Marshal(bucket)
+	if err != nil {
+		return "", fmt.Errorf("json marshal failed: %s", err)
+	}
+	if err = os.WriteFile(filepath.Join(bucketPath(id), metadataFile), marshal, 0o644); err != nil {
+		return "", fmt.Errorf("create bucket failed, cause: %v", err)
+	}
+	return id, nil
 }
 
 func (c *LocalClient) DeleteBucket(ctx context.Context, bucketId string) (bool, error) {
-	return false, ErrLocalStorageUnimplemented
+	if !isDirExists(bucketPath(bucketId)) {
+		return false, ErrResourceNotFound
+	}
+	if err := os.RemoveAll(bucketPath(bucketId)); err != nil {
+		return false, fmt.Errorf("delete bucket failed, cause: %v", err)
+	}
+	return true, nil
 }
 
 func (c *LocalClient) GetBucket(ctx context.Context, bucketId string) (*models.Bucket, error) {
-	return nil, ErrLocalStorageUnimplemented
+	return readBucket(bucketId)
+}
+
+// readBucket reads the metadata of a bucket, with the size of its objects.
+func readBucket(bucketId string) (*models.Bucket, error) {
+	buf, err := os.ReadFile(filepath.Join(bucketPath(bucketId), metadataFile))
+	if os.IsNotExist(err) {
+		return nil, ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bucket %s failed: %v", bucketId, err)
+	}
+	var bucket models.Bucket
+	if err = json.Unmarshal(buf, &bucket); err != nil {
+		return nil, fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	objects, err := readObjects(bucketId)
+	if err != nil {
+		return nil, err
+	}
+	bucket.Size = 0
+	for _, object := range objects {
+		bucket.Size += object.Size
+	}
+	return &bucket, nil
 }
 
 func (c *LocalClient) ListObjects(ctx context.Context, req *models.ListObjectsRequest) (*models.ObjectList, error) {
-	return nil, ErrLocalStorageUnimplemented
+	if !isDirExists(bucketPath(req.BucketId)) {
+		return nil, ErrResourceNotFound
+	}
+	objects, err := readObjects(req.BucketId)
+	if err != nil {
+		return nil, err
+	}
+	matched := objects[:0]
+	for _, object := range objects {
+		if query.MatchObject(&object, req) {
+			matched = append(matched, object)
+		}
+	}
+
+	total := int64(len(matched))
+	start, end := pageRange(req.Page, req.PageSize, total)
+	return &models.ObjectList{
+		Objects:   matched[start:end],
+		Total:     total,
+		TotalPage: totalPage(total, req.PageSize),
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+	}, nil
+}
+
+// readObjects returns the objects of a bucket by creation time.
+func readObjects(bucketId string) ([]models.BucketObject, error) {
+	entries, err := os.ReadDir(filepath.Join(bucketPath(bucketId), objectMetaDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read dir failed: %v", err)
+	}
+	var objects []models.BucketObject
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		object, err := readObject(bucketId, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		objects = append(objects, *object)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].CreatedAt < objects[j].CreatedAt
+	})
+	return objects, nil
+}
+
+func readObject(bucketId, objectId string) (*models.BucketObject, error) {
+	buf, err := os.ReadFile(filepath.Join(bucketPath(bucketId), objectMetaDir, objectId+".json"))
+	if os.IsNotExist(err) {
+		return nil, ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read object %s failed: %v", objectId, err)
+	}
+	var object models.BucketObject
+	if err = json.Unmarshal(buf, &object); err != nil {
+		return nil, fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	return &object, nil
 }
 
 func (c *LocalClient) GetObject(ctx context.Context, req *models.ObjectRequest) ([]byte, error) {
-	return nil, ErrLocalStorageUnimplemented
+	if _, err := readObject(req.BucketId, req.ObjectId); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(bucketPath(req.BucketId), objectDataDir, req.ObjectId))
+	if err != nil {
+		return nil, fmt.Errorf("read object %s failed: %v", req.ObjectId, err)
+	}
+	return data, nil
 }
 
 func (c *LocalClient) DeleteObject(ctx context.Context, req *models.ObjectRequest) (bool, error) {
-	return false, ErrLocalStorageUnimplemented
+	metaPath := filepath.Join(bucketPath(req.BucketId), objectMetaDir, req.ObjectId+".json")
+	if !isFileExists(metaPath) {
+		return false, ErrResourceNotFound
+	}
+	if err := os.Remove(metaPath); err != nil {
+		return false, fmt.Errorf("delete object failed, cause: %v", err)
+	}
+	_ = os.Remove(filepath.Join(bucketPath(req.BucketId), objectDataDir, req.ObjectId))
+	return true, nil
 }
 
 func (c *LocalClient) PutObject(ctx context.Context, req *models.PutObjectRequest) (string, error) {
-	return "", ErrLocalStorageUnimplemented
+	return c.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: req.BucketId,
+		Filename: req.Filename,
+		Body:     bytes.NewReader(req.Data),
+		Size:     int64(len(req.Data)),
+		ActorId:  req.ActorId,
+		RunId:    req.RunId,
+
+		ObjectAttributes: req.ObjectAttributes,
+	})
+}
+
+// PutObjectStream copies the body to the bucket without holding it in
+// memory.
+func (c *LocalClient) PutObjectStream(ctx context.Context, req *models.PutObjectStreamRequest) (string, error) {
+	if !isDirExists(bucketPath(req.BucketId)) {
+		return "", ErrResourceNotFound
+	}
+	object := newObject(req.BucketId, req.Filename, req.ActorId, req.RunId, req.ObjectAttributes)
+	err := writeObjectData(object, func(w io.Writer) error {
+		n, err := io.Copy(w, req.Body)
+		if err == nil && req.Size >= 0 && n != req.Size {
+			err = scerrors.Newf(scerrors.KindInvalidArgument, "object %s has %d bytes, %d were announced", req.Filename, n, req.Size)
+		}
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return object.Id, nil
+}
+
+// GetObjectStream opens the file of the object, positioned at the start of
+// the range.
+func (c *LocalClient) GetObjectStream(ctx context.Context, req *models.GetObjectStreamRequest) (*models.ObjectStream, error) {
+	if _, err := readObject(req.BucketId, req.ObjectId); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(filepath.Join(bucketPath(req.BucketId), objectDataDir, req.ObjectId))
+	if err != nil {
+		return nil, fmt.Errorf("open object %s failed: %v", req.ObjectId, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("open object %s failed: %v", req.ObjectId, err)
+	}
+	total := info.Size()
+	offset := min(max(req.Offset, 0), total)
+	size := total - offset
+	if req.Length > 0 {
+		size = min(size, req.Length)
+	}
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("seek object %s failed: %v", req.ObjectId, err)
+	}
+	return &models.ObjectStream{
+		Body:      readCloser{Reader: io.LimitReader(file, size), Closer: file},
+		Size:      size,
+		TotalSize: total,
+	}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (c *LocalClient) CreateUpload(ctx context.Context, req *models.CreateUploadRequest) (string, error) {
+	if !isDirExists(bucketPath(req.BucketId)) {
+		return "", ErrResourceNotFound
+	}
+	id := uuid.NewString()
+	dir := filepath.Join(bucketPath(req.BucketId), objectUploadDir, id)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("create upload failed, cause: %v", err)
+	}
+	marshal, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("json marshal failed: %s", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, uploadFile), marshal, 0o644); err != nil {
+		return "", fmt.Errorf("create upload failed, cause: %v", err)
+	}
+	return id, nil
+}
+
+func (c *LocalClient) UploadPart(ctx context.Context, req *models.UploadPartRequest) (*models.UploadedPart, error) {
+	dir := filepath.Join(bucketPath(req.BucketId), objectUploadDir, req.UploadId)
+	if !isDirExists(dir) {
+		return nil, ErrResourceNotFound
+	}
+	if req.Number < 1 {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "invalid part number %d", req.Number)
+	}
+	sum := md5.Sum(req.Data)
+	digest := hex.EncodeToString(sum[:])
+	if req.MD5 != "" && !strings.EqualFold(req.MD5, digest) {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "part %d was corrupted: md5 %s, expected %s", req.Number, digest, req.MD5)
+	}
+	path := filepath.Join(dir, partFile(req.Number))
+	if err := os.WriteFile(path+".tmp", req.Data, 0o644); err != nil {
+		return nil, fmt.Errorf("write part failed: %v", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		_ = os.Remove(path + ".tmp")
+		return nil, fmt.Errorf("write part failed: %v", err)
+	}
+	return &models.UploadedPart{Number: req.Number, Size: int64(len(req.Data)), MD5: digest}, nil
+}
+
+func partFile(number int) string {
+	return fmt.Sprintf("part-%05d", number)
+}
+
+func (c *LocalClient) ListParts(ctx context.Context, bucketId string, uploadId string) ([]models.UploadedPart, error) {
+	dir := filepath.Join(bucketPath(bucketId), objectUploadDir, uploadId)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, ErrResourceNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read dir failed: %v", err)
+	}
+	var parts []models.UploadedPart
+	for _, entry := range entries {
+		var number int
+		if _, err := fmt.Sscanf(entry.Name(), "part-%05d", &number); err != nil || entry.Name() != partFile(number) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read part failed: %v", err)
+		}
+		sum := md5.Sum(data)
+		parts = append(parts, models.UploadedPart{Number: number, Size: int64(len(data)), MD5: hex.EncodeToString(sum[:])})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number })
+	return parts, nil
+}
+
+// CompleteUpload concatenates the parts into the object and checks the
+// digests of the whole content.
+func (c *LocalClient) CompleteUpload(ctx context.Context, req *models.CompleteUploadRequest) (string, error) {
+	dir := filepath.Join(bucketPath(req.BucketId), objectUploadDir, req.UploadId)
+	buf, err := os.ReadFile(filepath.Join(dir, uploadFile))
+	if os.IsNotExist(err) {
+		return "", ErrResourceNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("read upload failed: %v", err)
+	}
+	var upload models.CreateUploadRequest
+	if err = json.Unmarshal(buf, &upload); err != nil {
+		return "", fmt.Errorf("json unmarshal failed: %s", err)
+	}
+
+	object := newObject(req.BucketId, upload.Filename, upload.ActorId, upload.RunId, upload.ObjectAttributes)
+	err = writeObjectData(object, func(w io.Writer) error {
+		for i, part := range req.Parts {
+			if part.Number != i+1 {
+				return scerrors.Newf(scerrors.KindInvalidArgument, "part %d is missing", i+1)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, partFile(part.Number)))
+			if os.IsNotExist(err) {
+				return scerrors.Newf(scerrors.KindInvalidArgument, "part %d was not uploaded", part.Number)
+			}
+			if err != nil {
+				return fmt.Errorf("read part failed: %v", err)
+			}
+			sum := md5.Sum(data)
+			if part.MD5 != "" && !strings.EqualFold(part.MD5, hex.EncodeToString(sum[:])) {
+				return scerrors.Newf(scerrors.KindInvalidArgument, "part %d does not match the uploaded one", part.Number)
+			}
+			if _, err = w.Write(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func(object *models.BucketObject) error {
+		if req.MD5 != "" && !strings.EqualFold(req.MD5, object.MD5) ||
+			req.SHA256 != "" && !strings.EqualFold(req.SHA256, object.SHA256) {
+			return scerrors.Newf(scerrors.KindInvalidArgument, "object %s was corrupted: digests do not match", upload.Filename)
+		}
+		if upload.Size >= 0 && int64(object.Size) != upload.Size {
+			return scerrors.Newf(scerrors.KindInvalidArgument, "object %s has %d bytes, %d were announced", upload.Filename, object.Size, upload.Size)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	_ = os.RemoveAll(dir)
+	return object.Id, nil
+}
+
+func (c *LocalClient) AbortUpload(ctx context.Context, bucketId string, uploadId string) error {
+	dir := filepath.Join(bucketPath(bucketId), objectUploadDir, uploadId)
+	if !isDirExists(dir) {
+		return ErrResourceNotFound
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("abort upload failed, cause: %v", err)
+	}
+	return nil
+}
+
+func newObject(bucketId, filename, actorId, runId string, attrs models.ObjectAttributes) *models.BucketObject {
+	now := time.Now().Format(time.RFC3339Nano)
+	return &models.BucketObject{
+		Id:        uuid.NewString(),
+		Filename:  filename,
+		BucketId:  bucketId,
+		ActorId:   actorId,
+		RunId:     runId,
+		FileType:  strings.TrimPrefix(filepath.Ext(filename), "."),
+		CreatedAt: now,
+		UpdatedAt: now,
+
+		ObjectAttributes: attrs,
+	}
+}
+
+// writeObjectData stores the content written by write and then the metadata
+// of object, with the size and digests of the content. The checks run
+// before the object becomes visible.
+func writeObjectData(object *models.BucketObject, write func(w io.Writer) error, checks ...func(*models.BucketObject) error) error {
+	dataDir := filepath.Join(bucketPath(object.BucketId), objectDataDir)
+	metaDir := filepath.Join(bucketPath(object.BucketId), objectMetaDir)
+	for _, dir := range []string{dataDir, metaDir} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return fmt.Errorf("create dir failed: %v", err)
+		}
+	}
+	dataPath := filepath.Join(dataDir, object.Id)
+	file, err := os.Create(dataPath + ".tmp")
+	if err != nil {
+		return fmt.Errorf("write object failed: %v", err)
+	}
+	defer os.Remove(dataPath + ".tmp")
+
+	hashMD5, hashSHA256 := md5.New(), sha256.New()
+	counter := &countingWriter{}
+	err = write(io.MultiWriter(file, hashMD5, hashSHA256, counter))
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("write object failed: %v", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	object.Size = int(counter.n)
+	object.MD5 = hex.EncodeToString(hashMD5.Sum(nil))
+	object.SHA256 = hex.EncodeToString(hashSHA256.Sum(nil))
+	object.Path = dataPath
+	for _, check := range checks {
+		if err = check(object); err != nil {
+			return err
+		}
+	}
+
+	if err = os.Rename(dataPath+".tmp", dataPath); err != nil {
+		return fmt.Errorf("write object failed: %v", err)
+	}
+	marshal, err := json.Marshal(object)
+	if err != nil {
+		return fmt.Errorf("json marshal failed: %s", err)
+	}
+	metaPath := filepath.Join(metaDir, object.Id+".json")
+	if err = os.WriteFile(metaPath+".tmp", marshal, 0o644); err == nil {
+		err = os.Rename(metaPath+".tmp", metaPath)
+	}
+	if err != nil {
+		_ = os.Remove(dataPath)
+		return fmt.Errorf("write object failed: %v", err)
+	}
+	return nil
+}
+
+type countingWriter struct{ n int64 }
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
 }
@@ -3,17 +3,18 @@ package storage_memory
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
-	"io/fs"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"os"
 	"path/filepath"
 	"sort"
 	"time"
 )
 
+const defaultMsgTimeout = 60 * time.Second
+
 func (c *LocalClient) CreateQueue(ctx context.Context, req *models.CreateQueueRequest) (*models.CreateQueueResponse, error) {
 	id := uuid.NewString()
 	exists, err := isNameExists(filepath.Join(storageDir, queueDir), req.Name)
@@ -63,6 +64,7 @@ func (c *LocalClient) GetQueue(ctx context.Context, req *models.GetQueueRequest)
 		return nil, fmt.Errorf("json unmarshal failed: %s", err)
 	}
 
+	queue.Stats = queueStats(queuePath)
 	return &models.GetQueueResponse{
 		Queue: queue,
 	}, nil
@@ -96,6 +98,7 @@ func (c *LocalClient) GetQueues(ctx context.Context, req *models.GetQueuesReques
 			continue
 		}
 
+		meta.Stats = queueStats(filepath.Join(dirPath, name))
 		allNamespaces = append(allNamespaces, &meta)
 	}
 
@@ -110,14 +113,7 @@ func (c *LocalClient) GetQueues(ctx context.Context, req *models.GetQueuesReques
 	total := int64(len(allNamespaces))
 
 	// page
-	start := (req.Page - 1) * req.PageSize
-	if start > total {
-		start = total
-	}
-	end := start + req.PageSize
-	if end > total {
-		end = total
-	}
+	start, end := pageRange(req.Page, req.PageSize, total)
 
 	pagedItems := allNamespaces[start:end]
 
@@ -165,15 +161,21 @@ func (c *LocalClient) DelQueue(ctx context.Context, req *models.DelQueueRequest)
 
 func (c *LocalClient) CreateMsg(ctx context.Context, req *models.CreateMsgRequest) (*models.CreateMsgResponse, error) {
 	id := uuid.NewString()
-	if req.Deadline < time.Now().Unix()+300 {
+	now := time.Now()
+	if req.Deadline < now.Unix()+300 {
 		return nil, fmt.Errorf("deadline must after now + 300s")
 	}
 	queuePath := filepath.Join(storageDir, queueDir, req.QueueId)
 	if !isDirExists(queuePath) {
 		return nil, ErrResourceNotFound
 	}
-	msgPath := filepath.Join(storageDir, queueDir, req.QueueId, fmt.Sprintf("%s.json", id))
-	msg := models.MsgLocal{
+	unlock, err := lockDir(queuePath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	msg := &models.MsgLocal{
 		Msg: models.Msg{
 			ID:       id,
 			QueueID:  req.QueueId,
@@ -183,15 +185,9 @@ func (c *LocalClient) CreateMsg(ctx context.Context, req *models.CreateMsgReques
 			Retry:    req.Retry,
 			Timeout:  req.Timeout,
 		},
-		UpdateTime: time.Now(),
-	}
-
-	marshal, err := json.Marshal(msg)
-	if err != nil {
-		return nil, fmt.Errorf("json marshal failed: %s", err)
+		UpdateTime: now,
 	}
-
-	if err = os.WriteFile(msgPath, marshal, os.ModePerm); err != nil {
+	if err = writeMsg(queuePath, msg); err != nil {
 		return nil, err
 	}
 	return &models.CreateMsgResponse{
@@ -199,109 +195,207 @@ func (c *LocalClient) CreateMsg(ctx context.Context, req *models.CreateMsgReques
 	}, nil
 }
 
+// GetMsg leases up to req.Limit pending messages, oldest first. A leased
+// message is hidden for its Timeout; if it is not acked by then it is
+// delivered again, until it has been delivered Retry times (at least once).
+// Messages past their Deadline or out of deliveries are marked as failed.
 func (c *LocalClient) GetMsg(ctx context.Context, req *models.GetMsgRequest) (*models.GetMsgResponse, error) {
 	queuePath := filepath.Join(storageDir, queueDir, req.QueueId)
+	if !isDirExists(queuePath) {
+		return nil, ErrResourceNotFound
+	}
+	unlock, err := lockDir(queuePath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
 
-	msgs := make([]*models.MsgLocal, 0)
-	now := time.Now()
-	err := filepath.WalkDir(queuePath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() || d.Name() == metadataFile {
-			return nil
-		}
-		msgPath := filepath.Join(queuePath, d.Name())
-		buf, err := os.ReadFile(msgPath)
-		if err != nil {
-			return fmt.Errorf("read file %s failed: %v", path, err)
-		}
-		var msg models.MsgLocal
-		err = json.Unmarshal(buf, &msg)
-		if err != nil {
-			return fmt.Errorf("json unmarshal failed: %s", err)
-		}
-		// msg is finished
-		if msg.SuccessAt > 0 || msg.FailedAt > 0 || msg.Deadline < now.Unix() ||
-			(msg.ReenterTime.Before(now) && msg.Retried >= msg.Retry) {
-			_ = os.Remove(msgPath)
-			return nil
-		}
-		// msg is not reenter queue
-		if !msg.ReenterTime.Equal(time.Time{}) && msg.ReenterTime.After(now) {
-			return nil
-		}
-
-		msgs = append(msgs, &msg)
-
-		return nil
-	})
+	all, err := readMsgs(queuePath)
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	msgs := make([]*models.MsgLocal, 0)
+	for _, msg := range all {
+		switch msgState(msg, now) {
+		case msgPending:
+			msgs = append(msgs, msg)
+		case msgExpired:
+			msg.FailedAt = now.Unix()
+			msg.Desc = failedDesc(msg, now)
+			if err = writeMsg(queuePath, msg); err != nil {
+				return nil, err
+			}
+		}
+	}
 	sort.Slice(msgs, func(i, j int) bool {
 		return msgs[i].UpdateTime.Before(msgs[j].UpdateTime)
 	})
 	if len(msgs) > int(req.Limit) {
-		msgs = msgs[:req.Limit]
+		msgs = msgs[:max(req.Limit, 0)]
 	}
 
 	respMsg := make([]*models.Msg, 0, len(msgs))
 	for _, msg := range msgs {
-		msg.ReenterTime = now.Add(time.Duration(msg.Timeout) * time.Second)
+		msg.ReenterTime = now.Add(msgTimeout(msg))
 		msg.Retried++
-		msgPath := filepath.Join(queuePath, fmt.Sprintf("%s.json", msg.ID))
-		marshal, err := json.Marshal(msg)
-		if err != nil {
-			return nil, fmt.Errorf("json marshal failed: %s", err)
-		}
-		if err = os.WriteFile(msgPath, marshal, os.ModePerm); err != nil {
-			return nil, fmt.Errorf("write file %s failed: %v", msgPath, err)
+		if err = writeMsg(queuePath, msg); err != nil {
+			return nil, err
 		}
-
-		respMsg = append(respMsg, &models.Msg{
-			ID:        msg.ID,
-			QueueID:   msg.QueueID,
-			Name:      msg.Name,
-			Payload:   msg.Payload,
-			Timeout:   msg.Timeout,
-			Deadline:  msg.Deadline,
-			Retry:     msg.Retry,
-			Retried:   msg.Retried,
-			SuccessAt: msg.SuccessAt,
-			FailedAt:  msg.FailedAt,
-			Desc:      msg.Desc,
-		})
+		out := msg.Msg
+		respMsg = append(respMsg, &out)
 	}
 	resp := models.GetMsgResponse(respMsg)
 	return &resp, nil
 }
 
+// AckMsg marks a leased message as done. The message is kept with its
+// SuccessAt set, like the hosted queue does.
 func (c *LocalClient) AckMsg(ctx context.Context, req *models.AckMsgRequest) error {
-	msgPath := filepath.Join(storageDir, queueDir, req.QueueId, fmt.Sprintf("%s.json", req.MsgId))
+	queuePath := filepath.Join(storageDir, queueDir, req.QueueId)
+	msgPath := filepath.Join(queuePath, fmt.Sprintf("%s.json", req.MsgId))
 	if !isFileExists(msgPath) {
 		return ErrResourceNotFound
 	}
+	unlock, err := lockDir(queuePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
-	buf, err := os.ReadFile(msgPath)
+	msg, err := readMsg(msgPath)
 	if err != nil {
 		return err
 	}
-	var msg models.MsgLocal
-	err = json.Unmarshal(buf, &msg)
+	now := time.Now()
+	switch msgState(msg, now) {
+	case msgSucceeded:
+		return nil
+	case msgRunning:
+	case msgPending:
+		if msg.ReenterTime.IsZero() {
+			return scerrors.New(scerrors.KindInvalidArgument, "msg has not been pulled")
+		}
+		return scerrors.New(scerrors.KindConflict, "msg is timeout, you must ack within the timeout period")
+	default:
+		return scerrors.Newf(scerrors.KindConflict, "msg has failed: %s", failedDesc(msg, now))
+	}
+	msg.SuccessAt = now.Unix()
+	return writeMsg(queuePath, msg)
+}
+
+type msgStatus int
+
+const (
+	msgPending msgStatus = iota
+	msgRunning
+	msgSucceeded
+	msgFailed
+	msgExpired // Failed, but not marked yet
+)
+
+func msgState(msg *models.MsgLocal, now time.Time) msgStatus {
+	switch {
+	case msg.SuccessAt > 0:
+		return msgSucceeded
+	case msg.FailedAt > 0:
+		return msgFailed
+	case msg.ReenterTime.After(now):
+		return msgRunning
+	case msg.Deadline > 0 && msg.Deadline < now.Unix():
+		return msgExpired
+	case !msg.ReenterTime.IsZero() && msg.Retried >= max(msg.Retry, 1):
+		return msgExpired
+	}
+	return msgPending
+}
+
+func failedDesc(msg *models.MsgLocal, now time.Time) string {
+	if msg.Desc != "" {
+		return msg.Desc
+	}
+	if msg.Deadline > 0 && msg.Deadline < now.Unix() {
+		return "deadline exceeded"
+	}
+	return "retries exhausted"
+}
+
+func msgTimeout(msg *models.MsgLocal) time.Duration {
+	if msg.Timeout <= 0 {
+		return defaultMsgTimeout
+	}
+	return time.Duration(msg.Timeout) * time.Second
+}
+
+// queueStats counts the messages of a queue by state.
+func queueStats(queuePath string) models.QueueStats {
+	var stats models.QueueStats
+	msgs, err := readMsgs(queuePath)
 	if err != nil {
-		return fmt.Errorf("json unmarshal failed: %s", err)
+		return stats
 	}
-	if msg.ReenterTime.Equal(time.Time{}) {
-		return ErrResourceNotFound
+	now := time.Now()
+	for _, msg := range msgs {
+		switch msgState(msg, now) {
+		case msgPending:
+			stats.Pending++
+		case msgRunning:
+			stats.Running++
+		case msgSucceeded:
+			stats.Success++
+		default:
+			stats.Failed++
+		}
+	}
+	return stats
+}
+
+func readMsgs(queuePath string) ([]*models.MsgLocal, error) {
+	entries, err := os.ReadDir(queuePath)
+	if err != nil {
+		return nil, fmt.Errorf("read dir failed: %v", err)
+	}
+	msgs := make([]*models.MsgLocal, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == metadataFile || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		msg, err := readMsg(filepath.Join(queuePath, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
 	}
+	return msgs, nil
+}
 
-	if msg.ReenterTime.Before(time.Now()) {
-		return errors.New("msg is timeout, you must ack within the timeout period")
+func readMsg(path string) (*models.MsgLocal, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s failed: %v", path, err)
+	}
+	var msg models.MsgLocal
+	if err = json.Unmarshal(buf, &msg); err != nil {
+		return nil, fmt.Errorf("json unmarshal failed: %s", err)
 	}
-	err = os.Remove(msgPath)
+	return &msg, nil
+}
+
+// writeMsg replaces the file of msg atomically, so that readers never see a
+// partial message.
+func writeMsg(queuePath string, msg *models.MsgLocal) error {
+	marshal, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("delete file %s failed: %v", msgPath, err)
+		return fmt.Errorf("json marshal failed: %s", err)
+	}
+	path := filepath.Join(queuePath, fmt.Sprintf("%s.json", msg.ID))
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, marshal, 0o644); err != nil {
+		return fmt.Errorf("write file %s failed: %v", path, err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write file %s failed: %v", path, err)
 	}
 	return nil
 }
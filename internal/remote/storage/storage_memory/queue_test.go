@@ -0,0 +1,181 @@
+package storage_memory
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+func createTestQueue(t *testing.T, msgs int, retry int64) string {
+	t.Helper()
+	resp, err := local.CreateQueue(ctx, &models.CreateQueueRequest{Name: "queue"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < msgs; i++ {
+		_, err = local.CreateMsg(ctx, &models.CreateMsgRequest{
+			QueueId:  resp.Id,
+			Name:     "msg",
+			Retry:    retry,
+			Timeout:  60,
+			Deadline: time.Now().Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.Id
+}
+
+// expireLease makes a leased message visible again, as if its timeout passed.
+func expireLease(t *testing.T, queueId, msgId string) {
+	t.Helper()
+	queuePath := filepath.Join(storageDir, queueDir, queueId)
+	msg, err := readMsg(filepath.Join(queuePath, msgId+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.ReenterTime = time.Now().Add(-time.Second)
+	if err = writeMsg(queuePath, msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetMsgConcurrent(t *testing.T) {
+	useTempStorage(t)
+	queueId := createTestQueue(t, 50, 1)
+
+	var (
+		mu   sync.Mutex
+		seen = map[string]int{}
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				resp, err := local.GetMsg(ctx, &models.GetMsgRequest{QueueId: queueId, Limit: 3})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(*resp) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, msg := range *resp {
+					seen[msg.ID]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 50 {
+		t.Fatalf("delivered %d of 50 messages", len(seen))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Fatalf("message %s delivered %d times", id, n)
+		}
+	}
+}
+
+func TestMsgLease(t *testing.T) {
+	useTempStorage(t)
+	queueId := createTestQueue(t, 1, 2)
+
+	pull := func() []*models.Msg {
+		resp, err := local.GetMsg(ctx, &models.GetMsgRequest{QueueId: queueId, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *resp
+	}
+	msgs := pull()
+	if len(msgs) != 1 || msgs[0].Retried != 1 {
+		t.Fatalf("first delivery: %+v", msgs)
+	}
+	msgId := msgs[0].ID
+	if len(pull()) != 0 {
+		t.Fatal("a leased message must not be delivered again")
+	}
+
+	expireLease(t, queueId, msgId)
+	err := local.AckMsg(ctx, &models.AckMsgRequest{QueueId: queueId, MsgId: msgId})
+	if !errors.Is(err, scerrors.ErrConflict) {
+		t.Fatalf("ack after the lease expired: %v", err)
+	}
+	if msgs = pull(); len(msgs) != 1 || msgs[0].Retried != 2 {
+		t.Fatalf("redelivery: %+v", msgs)
+	}
+
+	expireLease(t, queueId, msgId)
+	if len(pull()) != 0 {
+		t.Fatal("a message out of retries must not be delivered")
+	}
+	queue, err := local.GetQueue(ctx, &models.GetQueueRequest{Id: queueId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queue.Stats.Failed != 1 {
+		t.Fatalf("unexpected stats %+v", queue.Stats)
+	}
+}
+
+func TestAckMsgKeepsMessage(t *testing.T) {
+	useTempStorage(t)
+	queueId := createTestQueue(t, 3, 1)
+
+	resp, err := local.GetMsg(ctx, &models.GetMsgRequest{QueueId: queueId, Limit: 1})
+	if err != nil || len(*resp) != 1 {
+		t.Fatalf("pull: %v, %v", resp, err)
+	}
+	msgId := (*resp)[0].ID
+	if err = local.AckMsg(ctx, &models.AckMsgRequest{QueueId: queueId, MsgId: msgId}); err != nil {
+		t.Fatal(err)
+	}
+	if err = local.AckMsg(ctx, &models.AckMsgRequest{QueueId: queueId, MsgId: msgId}); err != nil {
+		t.Fatalf("acking twice should be harmless: %v", err)
+	}
+	msg, err := readMsg(filepath.Join(storageDir, queueDir, queueId, msgId+".json"))
+	if err != nil || msg.SuccessAt == 0 {
+		t.Fatalf("acked message: %+v, %v", msg, err)
+	}
+
+	// Push a message past its deadline.
+	queuePath := filepath.Join(storageDir, queueDir, queueId)
+	msgs, err := readMsgs(queuePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range msgs {
+		if m.ID != msgId {
+			m.Deadline = time.Now().Add(-time.Minute).Unix()
+			if err = writeMsg(queuePath, m); err != nil {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+	if resp, err = local.GetMsg(ctx, &models.GetMsgRequest{QueueId: queueId, Limit: 10}); err != nil || len(*resp) != 1 {
+		t.Fatalf("pull: %v, %v", resp, err)
+	}
+
+	queues, err := local.GetQueues(ctx, &models.GetQueuesRequest{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.QueueStats{Running: 1, Success: 1, Failed: 1}
+	for _, q := range queues.Items {
+		if q.Id == queueId && q.Stats != want {
+			t.Fatalf("stats %+v, want %+v", q.Stats, want)
+		}
+	}
+}
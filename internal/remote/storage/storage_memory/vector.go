@@ -2,49 +2,558 @@ package storage_memory
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/query"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
+const (
+	MetricCosine     = "cosine"
+	MetricEuclidean  = "euclidean"
+	MetricDotProduct = "dotproduct"
+
+	docOpInsert = "insert"
+	docOpUpdate = "update"
+	docOpUpsert = "upsert"
+	docOpDelete = "delete"
+
+	docOpSuccess      int32 = 0
+	docOpFailed       int32 = 1
+	docOpSuccessMsg         = "Success"
+	maxQueryTopk            = 1024
+	defaultCollMetric       = MetricCosine
+)
+
+// vectorMu serializes writers of the vector store; readers share the lock so
+// concurrent queries do not observe half written documents.
+var vectorMu sync.RWMutex
+
+// docLocal is the on-disk representation of a vector document.
+type docLocal struct {
+	models.Doc
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
 func (c *LocalClient) ListCollections(ctx context.Context, req *models.ListCollectionsRequest) (*models.ListCollectionsResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	vectorMu.RLock()
+	defer vectorMu.RUnlock()
+
+	dirPath := filepath.Join(storageDir, vectorDir)
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dir: %v", err)
+	}
+
+	var allCollections []models.Collection
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		coll, err := readCollection(entry.Name())
+		if err != nil {
+			continue
+		}
+		if req.ActorId != nil && *req.ActorId != "" && coll.ActorId != *req.ActorId {
+			continue
+		}
+		if req.RunId != nil && *req.RunId != "" && coll.RunId != *req.RunId {
+			continue
+		}
+		allCollections = append(allCollections, *coll)
+	}
+
+	// sort
+	sort.Slice(allCollections, func(i, j int) bool {
+		if req.Desc {
+			return allCollections[i].CreatedAt.After(allCollections[j].CreatedAt)
+		}
+		return allCollections[i].CreatedAt.Before(allCollections[j].CreatedAt)
+	})
+
+	total := int64(len(allCollections))
+
+	// page
+	start, end := pageRange(req.Page, req.PageSize, total)
+
+	pagedItems := allCollections[start:end]
+	for i := range pagedItems {
+		pagedItems[i].Stats = collectionStats(pagedItems[i].Id)
+	}
+
+	return &models.ListCollectionsResponse{
+		Items:     pagedItems,
+		Total:     total,
+		Page:      req.Page,
+		PageSize:  req.PageSize,
+		TotalPage: totalPage(total, req.PageSize),
+	}, nil
 }
 
 func (c *LocalClient) CreateCollections(ctx context.Context, req *models.CreateCollectionRequest) (*models.CreateCollectionResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	if req.Dimension < 0 {
+		return nil, fmt.Errorf("invalid dimension %d", req.Dimension)
+	}
+	metric, err := normalizeMetric(req.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	vectorMu.Lock()
+	defer vectorMu.Unlock()
+
+	exists, err := isNameExists(filepath.Join(storageDir, vectorDir), req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("collection %s already exists", req.Name)
+	}
+
+	id := uuid.NewString()
+	path := filepath.Join(storageDir, vectorDir, id)
+	if err = os.MkdirAll(path, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("create collection failed, cause: %v", err)
+	}
+
+	now := time.Now()
+	coll := models.Collection{
+		Id:          id,
+		Name:        req.Name,
+		ActorId:     req.ActorId,
+		RunId:       req.RunId,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Dimension:   uint32(req.Dimension),
+		Metric:      metric,
+	}
+	if err = writeCollection(&coll); err != nil {
+		return nil, err
+	}
+	return &models.CreateCollectionResponse{
+		Coll: coll,
+	}, nil
 }
 
 func (c *LocalClient) UpdateCollection(ctx context.Context, req *models.UpdateCollectionRequest) error {
-	return ErrLocalStorageUnimplemented
+	vectorMu.Lock()
+	defer vectorMu.Unlock()
+
+	coll, err := readCollection(req.CollId)
+	if err != nil {
+		return err
+	}
+	if req.Name != "" {
+		coll.Name = req.Name
+	}
+	coll.Description = req.Description
+	coll.UpdatedAt = time.Now()
+	return writeCollection(coll)
 }
 
 func (c *LocalClient) DelCollection(ctx context.Context, collId string) error {
-	return ErrLocalStorageUnimplemented
+	vectorMu.Lock()
+	defer vectorMu.Unlock()
+
+	collPath := filepath.Join(storageDir, vectorDir, collId)
+	if !isDirExists(collPath) {
+		return ErrResourceNotFound
+	}
+	if err := os.RemoveAll(collPath); err != nil {
+		return fmt.Errorf("delete collection failed, cause: %v", err)
+	}
+	return nil
 }
 
 func (c *LocalClient) GetCollection(ctx context.Context, collId string) (*models.Collection, error) {
-	return nil, ErrLocalStorageUnimplemented
+	vectorMu.RLock()
+	defer vectorMu.RUnlock()
+
+	coll, err := readCollection(collId)
+	if err != nil {
+		return nil, err
+	}
+	coll.Stats = collectionStats(collId)
+	return coll, nil
 }
 
 func (c *LocalClient) CreateDocs(ctx context.Context, req *models.CreateDocsRequest) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	return writeDocs(req.CollId, req.Docs, docOpInsert)
 }
 
 func (c *LocalClient) UpdateDocs(ctx context.Context, req *models.UpdateDocsRequest) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	return writeDocs(req.CollId, req.Docs, docOpUpdate)
 }
 
 func (c *LocalClient) UpsertDocs(ctx context.Context, req *models.UpsertVectorDocsParam) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	return writeDocs(req.CollId, req.Docs, docOpUpsert)
 }
 
 func (c *LocalClient) DelDocs(ctx context.Context, req *models.DeleteDocsRequest) (*models.DocOpResponse, error) {
-	return nil, ErrLocalStorageUnimplemented
+	vectorMu.Lock()
+	defer vectorMu.Unlock()
+
+	if _, err := readCollection(req.CollId); err != nil {
+		return nil, err
+	}
+	output := make([]models.DocOpResult, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		result := models.DocOpResult{DocOp: docOpDelete, Id: id, Code: docOpSuccess, Message: docOpSuccessMsg}
+		path, err := docPath(req.CollId, id)
+		if err == nil && !isFileExists(path) {
+			err = fmt.Errorf("doc %s not found", id)
+		}
+		if err == nil {
+			if rmErr := os.Remove(path); rmErr != nil {
+				err = fmt.Errorf("delete file %s failed: %v", path, rmErr)
+			}
+		}
+		if err != nil {
+			result.Code = docOpFailed
+			result.Message = err.Error()
+		}
+		output = append(output, result)
+	}
+	return &models.DocOpResponse{
+		Output: output,
+	}, nil
 }
 
 func (c *LocalClient) QueryDocs(ctx context.Context, req *models.QueryVectorRequest) ([]*models.Doc, error) {
-	return nil, ErrLocalStorageUnimplemented
+	vectorMu.RLock()
+	defer vectorMu.RUnlock()
+
+	coll, err := readCollection(req.CollId)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Vector) == 0 && len(req.SparseVector) == 0 {
+		return nil, fmt.Errorf("query vector and sparse vector can't both be empty")
+	}
+	if len(req.Vector) > 0 && coll.Dimension > 0 && len(req.Vector) != int(coll.Dimension) {
+		return nil, fmt.Errorf("query vector dimension %d mismatches collection dimension %d", len(req.Vector), coll.Dimension)
+	}
+	var filter *query.Metadata
+	if req.Filter != nil {
+		if filter, err = query.NewMetadata(req.Filter); err != nil {
+			return nil, err
+		}
+	}
+	topk := int(req.Topk)
+	if topk < 1 {
+		topk = 1
+	}
+	if topk > maxQueryTopk {
+		topk = maxQueryTopk
+	}
+
+	docs, err := readDocs(req.CollId)
+	if err != nil {
+		return nil, err
+	}
+	scored := make([]*models.Doc, 0, len(docs))
+	for _, doc := range docs {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		if filter != nil && !filter.Match(doc.Metadata) {
+			continue
+		}
+		score, ok := scoreDoc(coll.Metric, req.Vector, req.SparseVector, &doc.Doc)
+		if !ok {
+			continue
+		}
+		result := projectDoc(&doc.Doc, req.IncludeVector, req.IncludeContent)
+		result.Score = score
+		scored = append(scored, result)
+	}
+
+	ascending := coll.Metric != MetricDotProduct
+	sort.SliceStable(scored, func(i, j int) bool {
+		if ascending {
+			return scored[i].Score < scored[j].Score
+		}
+		return scored[i].Score > scored[j].Score
+	})
+	if len(scored) > topk {
+		scored = scored[:topk]
+	}
+	return scored, nil
 }
 
 func (c *LocalClient) QueryDocsByIds(ctx context.Context, req *models.QueryDocsByIdsRequest) (map[string]*models.Doc, error) {
-	return nil, ErrLocalStorageUnimplemented
+	vectorMu.RLock()
+	defer vectorMu.RUnlock()
+
+	if _, err := readCollection(req.CollId); err != nil {
+		return nil, err
+	}
+	result := make(map[string]*models.Doc, len(req.Ids))
+	for _, id := range req.Ids {
+		doc, err := readDoc(req.CollId, id)
+		if err != nil {
+			continue
+		}
+		result[id] = projectDoc(&doc.Doc, true, true)
+	}
+	return result, nil
+}
+
+// writeDocs applies an insert, update or upsert to every doc and reports the
+// outcome per doc, mirroring how the remote service answers batch writes.
+func writeDocs(collId string, docs []models.Doc, op string) (*models.DocOpResponse, error) {
+	vectorMu.Lock()
+	defer vectorMu.Unlock()
+
+	coll, err := readCollection(collId)
+	if err != nil {
+		return nil, err
+	}
+	output := make([]models.DocOpResult, 0, len(docs))
+	now := time.Now()
+	for _, doc := range docs {
+		if doc.ID == "" && op != docOpUpdate {
+			doc.ID = uuid.NewString()
+		}
+		result := models.DocOpResult{DocOp: op, Id: doc.ID, Code: docOpSuccess, Message: docOpSuccessMsg}
+		if err := writeDoc(coll, &doc, op, now); err != nil {
+			result.Code = docOpFailed
+			result.Message = err.Error()
+		}
+		output = append(output, result)
+	}
+	return &models.DocOpResponse{
+		Output: output,
+	}, nil
+}
+
+func writeDoc(coll *models.Collection, doc *models.Doc, op string, now time.Time) error {
+	path, err := docPath(coll.Id, doc.ID)
+	if err != nil {
+		return err
+	}
+	if len(doc.Vector) == 0 && len(doc.SparseVector) == 0 {
+		return fmt.Errorf("doc %s has neither vector nor sparse vector", doc.ID)
+	}
+	if len(doc.Vector) > 0 && coll.Dimension > 0 && len(doc.Vector) != int(coll.Dimension) {
+		return fmt.Errorf("doc %s vector dimension %d mismatches collection dimension %d", doc.ID, len(doc.Vector), coll.Dimension)
+	}
+
+	exists := isFileExists(path)
+	switch {
+	case op == docOpInsert && exists:
+		return fmt.Errorf("doc %s already exists", doc.ID)
+	case op == docOpUpdate && !exists:
+		return fmt.Errorf("doc %s not found", doc.ID)
+	}
+
+	local := docLocal{
+		Doc:       *doc,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	local.Score = 0
+	if exists {
+		if old, err := readDoc(coll.Id, doc.ID); err == nil {
+			local.CreatedAt = old.CreatedAt
+		}
+	}
+	marshal, err := json.Marshal(local)
+	if err != nil {
+		return fmt.Errorf("json marshal failed: %s", err)
+	}
+	if err = os.WriteFile(path, marshal, os.ModePerm); err != nil {
+		return fmt.Errorf("write file %s failed: %v", path, err)
+	}
+	return nil
+}
+
+func readCollection(collId string) (*models.Collection, error) {
+	collPath := filepath.Join(storageDir, vectorDir, collId)
+	if collId == "" || !isDirExists(collPath) {
+		return nil, ErrResourceNotFound
+	}
+	metaPath := filepath.Join(collPath, metadataFile)
+	buf, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, fmt.Errorf("read file %s failed: %v", metaPath, err)
+	}
+	var coll models.Collection
+	if err = json.Unmarshal(buf, &coll); err != nil {
+		return nil, fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	if coll.Metric == "" {
+		coll.Metric = defaultCollMetric
+	}
+	return &coll, nil
+}
+
+func writeCollection(coll *models.Collection) error {
+	coll.Stats = models.Stats{}
+	path := filepath.Join(storageDir, vectorDir, coll.Id, metadataFile)
+	marshal, err := json.Marshal(coll)
+	if err != nil {
+		return fmt.Errorf("json marshal failed: %s", err)
+	}
+	return os.WriteFile(path, marshal, os.ModePerm)
+}
+
+func collectionStats(collId string) models.Stats {
+	stats := models.Stats{}
+	entries, err := os.ReadDir(filepath.Join(storageDir, vectorDir, collId))
+	if err != nil {
+		return stats
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == metadataFile || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		stats.Count++
+		stats.Size += uint64(info.Size())
+	}
+	return stats
+}
+
+func docPath(collId, docId string) (string, error) {
+	if docId == "" || docId == "." || docId == ".." || strings.ContainsAny(docId, `/\`) {
+		return "", fmt.Errorf("invalid doc id %q", docId)
+	}
+	file := fmt.Sprintf("%s.json", docId)
+	if file == metadataFile {
+		return "", fmt.Errorf("doc id can't use 'metadata'")
+	}
+	return filepath.Join(storageDir, vectorDir, collId, file), nil
+}
+
+func readDoc(collId, docId string) (*docLocal, error) {
+	path, err := docPath(collId, docId)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("read file %s failed: %v", path, err)
+	}
+	var doc docLocal
+	if err = json.Unmarshal(buf, &doc); err != nil {
+		return nil, fmt.Errorf("json unmarshal failed: %s", err)
+	}
+	return &doc, nil
+}
+
+func readDocs(collId string) ([]*docLocal, error) {
+	collPath := filepath.Join(storageDir, vectorDir, collId)
+	entries, err := os.ReadDir(collPath)
+	if err != nil {
+		return nil, fmt.Errorf("read dir failed: %v", err)
+	}
+	docs := make([]*docLocal, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == metadataFile || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		doc, err := readDoc(collId, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func projectDoc(doc *models.Doc, includeVector, includeContent bool) *models.Doc {
+	result := &models.Doc{ID: doc.ID, Metadata: doc.Metadata}
+	if includeVector {
+		result.Vector = doc.Vector
+		result.SparseVector = doc.SparseVector
+	}
+	if includeContent {
+		result.Content = doc.Content
+	}
+	return result
+}
+
+func normalizeMetric(metric string) (string, error) {
+	switch strings.ToLower(metric) {
+	case "", MetricCosine:
+		return MetricCosine, nil
+	case MetricEuclidean, "l2":
+		return MetricEuclidean, nil
+	case MetricDotProduct, "ip", "inner_product":
+		return MetricDotProduct, nil
+	default:
+		return "", fmt.Errorf("unsupported metric %q", metric)
+	}
+}
+
+// scoreDoc scores a doc against the query using the same conventions as the
+// hosted service: cosine reports 1 - cosine similarity and euclidean reports
+// the squared L2 distance, so smaller is closer for both; dotproduct reports
+// the raw inner product, where larger is closer. The sparse inner product is
+// blended in the direction that keeps "closer" consistent for each metric.
+// Docs that can't be compared with the query (dimension mismatch, zero norm
+// for cosine) are skipped.
+func scoreDoc(metric string, vector []float64, sparse map[string]float64, doc *models.Doc) (float64, bool) {
+	var score float64
+	if len(vector) > 0 {
+		if len(doc.Vector) != len(vector) {
+			return 0, false
+		}
+		switch metric {
+		case MetricEuclidean:
+			for i := range vector {
+				d := vector[i] - doc.Vector[i]
+				score += d * d
+			}
+		case MetricDotProduct:
+			score = dot(vector, doc.Vector)
+		default:
+			qn, dn := norm(vector), norm(doc.Vector)
+			if qn == 0 || dn == 0 {
+				return 0, false
+			}
+			score = 1 - dot(vector, doc.Vector)/(qn*dn)
+		}
+	}
+	if len(sparse) > 0 {
+		var sparseScore float64
+		for k, v := range sparse {
+			sparseScore += v * doc.SparseVector[k]
+		}
+		if metric == MetricDotProduct {
+			score += sparseScore
+		} else {
+			score -= sparseScore
+		}
+	}
+	return score, true
+}
+
+func dot(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+func norm(a []float64) float64 {
+	return math.Sqrt(dot(a, a))
 }
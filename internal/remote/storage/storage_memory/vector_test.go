@@ -0,0 +1,161 @@
+package storage_memory
+
+import (
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+)
+
+// useTempStorage points the local client at a fresh storage dir for the
+// duration of a test.
+func useTempStorage(t *testing.T) {
+	t.Helper()
+	old := storageDir
+	storageDir = t.TempDir()
+	if err := EnsureDir(storageDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storageDir = old })
+}
+
+func createTestCollection(t *testing.T, metric string) string {
+	t.Helper()
+	resp, err := local.CreateCollections(ctx, &models.CreateCollectionRequest{
+		Name:      "coll-" + metric,
+		Dimension: 2,
+		Metric:    metric,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []models.Doc{
+		{ID: "east", Vector: []float64{1, 0}, Content: "east", SparseVector: map[string]float64{"1": 1}},
+		{ID: "north", Vector: []float64{0, 1}, Content: "north"},
+		{ID: "north-east", Vector: []float64{2, 2}, Content: "north-east"},
+	}
+	out, err := local.CreateDocs(ctx, &models.CreateDocsRequest{CollId: resp.Coll.Id, Docs: docs})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range out.Output {
+		if r.Code != docOpSuccess {
+			t.Fatalf("create doc %s: %s", r.Id, r.Message)
+		}
+	}
+	return resp.Coll.Id
+}
+
+func queryIds(t *testing.T, req *models.QueryVectorRequest) []string {
+	t.Helper()
+	docs, err := local.QueryDocs(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+func TestQueryDocsMetrics(t *testing.T) {
+	useTempStorage(t)
+	cases := []struct {
+		metric string
+		want   []string
+	}{
+		{MetricCosine, []string{"east", "north-east", "north"}},
+		{MetricEuclidean, []string{"east", "north", "north-east"}},
+		{MetricDotProduct, []string{"north-east", "east", "north"}},
+	}
+	for _, tc := range cases {
+		collId := createTestCollection(t, tc.metric)
+		got := queryIds(t, &models.QueryVectorRequest{CollId: collId, Vector: []float64{1, 0.1}, Topk: 3})
+		if len(got) != len(tc.want) {
+			t.Fatalf("%s: got %v, want %v", tc.metric, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%s: got %v, want %v", tc.metric, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestQueryDocsSparseAndProjection(t *testing.T) {
+	useTempStorage(t)
+	collId := createTestCollection(t, MetricDotProduct)
+
+	docs, err := local.QueryDocs(ctx, &models.QueryVectorRequest{
+		CollId:       collId,
+		Vector:       []float64{0, 1},
+		SparseVector: map[string]float64{"1": 10},
+		Topk:         1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].ID != "east" || docs[0].Score != 10 {
+		t.Fatalf("sparse score not blended: %+v", docs)
+	}
+	if docs[0].Vector != nil || docs[0].Content != "" {
+		t.Fatalf("vector and content should be omitted: %+v", docs[0])
+	}
+
+	docs, err = local.QueryDocs(ctx, &models.QueryVectorRequest{
+		CollId: collId, Vector: []float64{0, 1}, Topk: 1, IncludeVector: true, IncludeContent: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs[0].Vector) != 2 || docs[0].Content == "" {
+		t.Fatalf("vector and content should be included: %+v", docs[0])
+	}
+}
+
+func TestDocOps(t *testing.T) {
+	useTempStorage(t)
+	collId := createTestCollection(t, MetricCosine)
+
+	out, err := local.CreateDocs(ctx, &models.CreateDocsRequest{CollId: collId, Docs: []models.Doc{
+		{ID: "east", Vector: []float64{1, 1}},
+		{ID: "bad", Vector: []float64{1, 1, 1}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Output[0].Code == docOpSuccess || out.Output[1].Code == docOpSuccess {
+		t.Fatalf("duplicate id and wrong dimension should fail: %+v", out.Output)
+	}
+
+	out, err = local.UpsertDocs(ctx, &models.UpsertVectorDocsParam{CollId: collId, Docs: []models.Doc{
+		{ID: "east", Vector: []float64{1, 1}, Content: "changed"},
+	}})
+	if err != nil || out.Output[0].Code != docOpSuccess {
+		t.Fatalf("upsert failed: %v %+v", err, out)
+	}
+
+	out, err = local.DelDocs(ctx, &models.DeleteDocsRequest{CollId: collId, Ids: []string{"north", "missing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Output[0].Code != docOpSuccess || out.Output[1].Code == docOpSuccess {
+		t.Fatalf("unexpected delete output: %+v", out.Output)
+	}
+
+	byIds, err := local.QueryDocsByIds(ctx, &models.QueryDocsByIdsRequest{CollId: collId, Ids: []string{"east", "north"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byIds) != 1 || byIds["east"].Content != "changed" {
+		t.Fatalf("unexpected docs by ids: %+v", byIds)
+	}
+
+	coll, err := local.GetCollection(ctx, collId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if coll.Stats.Count != 2 {
+		t.Fatalf("want 2 docs, got %d", coll.Stats.Count)
+	}
+}
@@ -17,6 +17,8 @@ func (c *Client) CreateTask(ctx context.Context, req *models.UniversalTaskReques
 		Url:     fmt.Sprintf("%s/api/v1/unlocker/request", c.BaseUrl),
 		Body:    string(body),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
 	if err != nil {
 		return nil, err
@@ -25,15 +27,17 @@ func (c *Client) CreateTask(ctx context.Context, req *models.UniversalTaskReques
 }
 
 func (c *Client) GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error) {
-	response, err := request2.Request(ctx, request2.ReqInfo{
+	response, err := request2.Do(ctx, request2.ReqInfo{
 		Method:  http.MethodGet,
 		Url:     fmt.Sprintf("%s/api/v1/result/%s", c.BaseUrl, taskIKd),
 		Headers: map[string]string{},
+		ApiKey:  c.ApiKey,
+		Client:  c.client,
 	})
-	log.Infof("get task result:%s", response)
 	if err != nil {
 		log.Errorf("get task result err:%v", err)
 		return nil, err
 	}
-	return []byte(response), nil
+	log.Infof("get task result:%s", response.Body)
+	return request2.TaskResult(response)
 }
@@ -2,26 +2,32 @@ package universal
 
 import (
 	"context"
+	request2 "github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	universal_http "github.com/scrapeless-ai/sdk-go/internal/remote/universal/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/universal/models"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
-type Browser interface {
+type Universal interface {
 	CreateTask(ctx context.Context, req *models.UniversalTaskRequest) ([]byte, error)
 	GetTaskResult(ctx context.Context, taskIKd string) ([]byte, error)
+	Close() error
 }
 
-var ClientInterface Browser
-
-func NewClient(serverMode, baseUrl string) {
+// NewClient returns the universal backend for serverMode, or nil when the mode
+// has no implementation yet.
+func NewClient(serverMode, baseUrl string, cfg request2.ClientConfig) Universal {
 	switch serverMode {
 	case "grpc":
 		log.Info("grpc...")
 	case "dev":
 		log.Info("dev...")
 	default:
-		universal_http.Init(baseUrl)
-		ClientInterface = universal_http.Default()
+		client, err := universal_http.New(baseUrl, cfg)
+		if err != nil {
+			panic(err)
+		}
+		return client
 	}
+	return nil
 }
@@ -3,9 +3,11 @@ package actor
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/embedding"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/schema"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/browser"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/captcha"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/httpserver"
@@ -14,7 +16,10 @@ import (
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
 
 	"github.com/tidwall/gjson"
+	"io"
+	"iter"
 	"reflect"
+	"sync"
 )
 
 type Actor struct {
@@ -24,7 +29,9 @@ type Actor struct {
 	storage      *storage.Storage
 	Server       *httpserver.Server
 	Router       *router.Router
+	closeMu      sync.Mutex
 	closeFun     []func() error
+	validating   bool // Save the validation report of the dataset on Close
 	datasetId    string
 	namespaceId  string
 	bucketId     string
@@ -37,6 +44,10 @@ const (
 	typeHttp = "http"
 )
 
+// ValidationReportKey is the key of the dataset validation report in the
+// default KV namespace.
+const ValidationReportKey = "VALIDATION_REPORT"
+
 // New creates a new Actor.
 func New() *Actor {
 	var actor = new(Actor)
@@ -47,19 +58,36 @@ func New() *Actor {
 	actor.Router = router.New(typeHttp)
 	actor.Server = httpserver.New()
 
-	actor.datasetId = env.Env.Actor.DatasetId
-	actor.namespaceId = env.Env.Actor.KvNamespaceId
-	actor.bucketId = env.Env.Actor.BucketId
-	actor.queueId = env.Env.Actor.QueueId
-	actor.collectionId = env.Env.Actor.CollectionId
+	actor.datasetId = env.Get().Actor.DatasetId
+	actor.namespaceId = env.Get().Actor.KvNamespaceId
+	actor.bucketId = env.Get().Actor.BucketId
+	actor.queueId = env.Get().Actor.QueueId
+	actor.collectionId = env.Get().Actor.CollectionId
 	return actor
 }
 
-// Close closes the actor.
+// Close closes the actor, flushing the dataset writers it created. When the
+// default dataset has a schema, its validation report is then saved to the
+// default KV namespace under ValidationReportKey.
 func (a *Actor) Close() {
-	for _, f := range a.closeFun {
+	a.closeMu.Lock()
+	closeFun := a.closeFun
+	a.closeFun = nil
+	validating := a.validating
+	a.validating = false
+	a.closeMu.Unlock()
+	for _, f := range closeFun {
 		_ = f()
 	}
+	if validating {
+		_ = a.saveValidationReport(context.Background())
+	}
+}
+
+func (a *Actor) onClose(f func() error) {
+	a.closeMu.Lock()
+	defer a.closeMu.Unlock()
+	a.closeFun = append(a.closeFun, f)
 }
 
 // Input get input data from env.
@@ -71,13 +99,13 @@ func (a *Actor) Input(data any) error {
 	input = gjson.Parse(input).String()
 	tf := reflect.TypeOf(data)
 	if tf.Kind() != reflect.Ptr {
-		return errors.New("data must be ptr")
+		return scerrors.New(scerrors.KindInvalidArgument, "data must be ptr")
 	}
 	return json.Unmarshal([]byte(input), data)
 }
 
 func (a *Actor) Start() error {
-	return a.Server.Start(fmt.Sprintf(":%s", env.Env.Actor.HttpPort))
+	return a.Server.Start(fmt.Sprintf(":%s", env.Get().Actor.HttpPort))
 }
 
 /**
@@ -114,6 +142,16 @@ func (a *Actor) ListKeys(ctx context.Context, page int, pageSize int) (*storage.
 	return a.storage.KV.ListKeys(ctx, a.namespaceId, int64(page), int64(pageSize))
 }
 
+// ScanKeys Scan the keys of the namespace matching a glob pattern
+func (a *Actor) ScanKeys(ctx context.Context, match string, cursor string, count int64) (*storage.ScanKeysResult, error) {
+	return a.storage.KV.ScanKeys(ctx, a.namespaceId, match, cursor, count)
+}
+
+// IterScan Iterate over the keys of the namespace matching a glob pattern
+func (a *Actor) IterScan(ctx context.Context, match string, count int64) iter.Seq2[storage.KeyInfo, error] {
+	return a.storage.KV.IterScan(ctx, a.namespaceId, match, count)
+}
+
 // SetValue Set a key-value pair in the default namespace (from environment variable)
 func (a *Actor) SetValue(ctx context.Context, key string, value string, expiration uint) (bool, error) {
 	return a.storage.KV.SetValue(ctx, a.namespaceId, key, value, expiration)
@@ -168,6 +206,47 @@ func (a *Actor) AddItems(ctx context.Context, items []map[string]any) (bool, err
 	return a.storage.Dataset.AddItems(ctx, a.datasetId, items)
 }
 
+// NewDatasetWriter Create a buffered writer for the default dataset (from environment variable), flushed when the actor closes
+func (a *Actor) NewDatasetWriter(opts ...storage.DatasetWriterOption) *storage.DatasetWriter {
+	w := a.storage.Dataset.NewWriter(a.datasetId, opts...)
+	a.onClose(w.Close)
+	return w
+}
+
+// QueryItems Query the items of the default dataset (from environment variable)
+func (a *Actor) QueryItems(ctx context.Context, query storage.DatasetQuery) (*storage.QueryResult, error) {
+	return a.storage.Dataset.Query(ctx, a.datasetId, query)
+}
+
+// SetDatasetSchema Validate the items added to the default dataset (from environment variable) against sch, the report is saved when the actor closes
+func (a *Actor) SetDatasetSchema(sch *schema.Schema, opts ...storage.SchemaOption) error {
+	if err := a.storage.Dataset.SetSchema(a.datasetId, sch, opts...); err != nil {
+		return err
+	}
+	a.closeMu.Lock()
+	defer a.closeMu.Unlock()
+	a.validating = true
+	return nil
+}
+
+// ValidationReport Get the validation report of the default dataset (from environment variable)
+func (a *Actor) ValidationReport() (storage.ValidationReport, bool) {
+	return a.storage.Dataset.ValidationReport(a.datasetId)
+}
+
+func (a *Actor) saveValidationReport(ctx context.Context) error {
+	report, ok := a.ValidationReport()
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return scerrors.From(err)
+	}
+	_, err = a.storage.KV.SetValue(ctx, a.namespaceId, ValidationReportKey, string(data), 0)
+	return err
+}
+
 // GetItems Get items from the default dataset (from environment variable)
 func (a *Actor) GetItems(ctx context.Context, page int, pageSize int, desc bool) (*storage.ItemsResponse, error) {
 	return a.storage.Dataset.GetItems(ctx, a.datasetId, page, pageSize, desc)
@@ -242,8 +321,8 @@ func (a *Actor) GetBucket(ctx context.Context) (*storage.Bucket, error) {
 }
 
 // List list objects in a bucket
-func (a *Actor) List(ctx context.Context, fuzzyFileName string, page int64, pageSize int64) (*storage.ListObjectsResponse, error) {
-	return a.storage.Object.ListObjects(ctx, a.bucketId, fuzzyFileName, page, pageSize)
+func (a *Actor) List(ctx context.Context, fuzzyFileName string, page int64, pageSize int64, filter ...storage.ObjectFilter) (*storage.ListObjectsResponse, error) {
+	return a.storage.Object.ListObjects(ctx, a.bucketId, fuzzyFileName, page, pageSize, filter...)
 }
 
 // GetObject Get an object from the default bucket (from environment variable)
@@ -252,8 +331,28 @@ func (a *Actor) GetObject(ctx context.Context, objectId string) ([]byte, error)
 }
 
 // PutObject Upload an object to the default bucket (from environment variable)
-func (a *Actor) PutObject(ctx context.Context, filename string, data []byte) (string, error) {
-	return a.storage.Object.PutObject(ctx, a.bucketId, filename, data)
+func (a *Actor) PutObject(ctx context.Context, filename string, data []byte, opts ...storage.ObjectOption) (string, error) {
+	return a.storage.Object.PutObject(ctx, a.bucketId, filename, data, opts...)
+}
+
+// PutObjectStream Upload the content of a reader to the default bucket (from environment variable), in parts when it is large
+func (a *Actor) PutObjectStream(ctx context.Context, filename string, r io.Reader, size int64, opts ...storage.ObjectOption) (*storage.PutObjectResult, error) {
+	return a.storage.Object.PutObjectStream(ctx, a.bucketId, filename, r, size, opts...)
+}
+
+// GetObjectStream Open an object of the default bucket (from environment variable) for reading
+func (a *Actor) GetObjectStream(ctx context.Context, objectId string, opts ...storage.ObjectOption) (io.ReadCloser, error) {
+	return a.storage.Object.GetObjectStream(ctx, a.bucketId, objectId, opts...)
+}
+
+// SyncDir Upload the files of a local directory to the default bucket (from environment variable), skipping unchanged ones
+func (a *Actor) SyncDir(ctx context.Context, localDir string, opts ...storage.SyncOption) (*storage.SyncResult, error) {
+	return a.storage.Object.SyncDir(ctx, a.bucketId, localDir, opts...)
+}
+
+// SyncDirFromBucket Download the objects of the default bucket (from environment variable) to a local directory, skipping unchanged files
+func (a *Actor) SyncDirFromBucket(ctx context.Context, localDir string, opts ...storage.SyncOption) (*storage.SyncResult, error) {
+	return a.storage.Object.SyncDirFromBucket(ctx, a.bucketId, localDir, opts...)
 }
 
 // DeleteObject Delete an object from a bucket
@@ -310,11 +409,26 @@ func (a *Actor) DelDocs(ctx context.Context, ids []string) (*storage.DocOpRespon
 	return a.storage.Vector.DelDocs(ctx, a.collectionId, ids)
 }
 
-// QueryDocs queries documents in the collection by vector.
+// SetEmbedder sets the embedder of the docs which only have content and of text queries.
+func (a *Actor) SetEmbedder(e embedding.Embedder) {
+	a.storage.Vector.SetEmbedder(e)
+}
+
+// SetSparseEncoder sets the sparse encoder of the docs with content and of hybrid text queries.
+func (a *Actor) SetSparseEncoder(e embedding.SparseEncoder) {
+	a.storage.Vector.SetSparseEncoder(e)
+}
+
+// QueryDocs queries documents in the collection by vector, or by text when an embedder is set.
 func (a *Actor) QueryDocs(ctx context.Context, query *storage.QueryVectorParam) ([]*storage.Doc, error) {
 	return a.storage.Vector.QueryDocs(ctx, a.collectionId, query)
 }
 
+// HybridQuery queries documents in the collection by a weighted sum of their dense and sparse scores.
+func (a *Actor) HybridQuery(ctx context.Context, query *storage.HybridQueryParam) ([]*storage.Doc, error) {
+	return a.storage.Vector.HybridQuery(ctx, a.collectionId, query)
+}
+
 // QueryDocsByIds queries documents in the collection by their IDs.
 func (a *Actor) QueryDocsByIds(ctx context.Context, ids []string) (map[string]*storage.Doc, error) {
 	return a.storage.Vector.QueryDocsByIds(ctx, a.collectionId, ids)
@@ -1,6 +1,7 @@
 package scrapeless
 
 import (
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/actor"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/browser"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/services/captcha"
@@ -29,14 +30,33 @@ type Client struct {
 	Crawl     *crawl.Crawl
 	Profile   *profile.Profile
 	CloseFun  []func() error
+
+	config    request.ClientConfig
+	transport *request.Config // Set when the client has its own transport
+	builders  []func()
 }
 
+// New creates a Client. Credentials and endpoints default to the environment
+// and can be overridden with WithAPIKey, WithBaseURL and friends; every
+// Client owns its backends, so clients with different settings can be used
+// side by side.
 func New(opts ...Option) *Client {
-	var client = new(Client)
+	var client = &Client{config: request.DefaultClientConfig()}
 	for _, opt := range opts {
 		opt.Apply(client)
 	}
-	client.Router = router.New(typeHttp)
+	if client.transport != nil {
+		t := request.NewTransport(*client.transport)
+		client.config.HTTPClient = t.Client()
+		client.CloseFun = append(client.CloseFun, func() error {
+			t.CloseIdleConnections()
+			return nil
+		})
+	}
+	client.Router = router.New(typeHttp, client.config)
+	for _, build := range client.builders {
+		build()
+	}
 	return client
 }
 
@@ -47,6 +67,12 @@ func (c *Client) Close() {
 	}
 }
 
+// service registers a service to create once every option has been applied,
+// so that options may be given in any order.
+func (c *Client) service(build func()) {
+	c.builders = append(c.builders, build)
+}
+
 const (
 	typeHttp = "http"
 	typeGrpc = "grpc"
@@ -61,8 +87,10 @@ type BrowserOption struct {
 }
 
 func (o *BrowserOption) Apply(c *Client) {
-	c.Browser = browser.NewBrowser(o.tp)
-	c.CloseFun = append(c.CloseFun, c.Browser.Close)
+	c.service(func() {
+		c.Browser = browser.NewBrowser(o.tp, c.config)
+		c.CloseFun = append(c.CloseFun, c.Browser.Close)
+	})
 }
 
 // WithBrowser choose browser type.
@@ -80,8 +108,10 @@ type ProxyOption struct {
 }
 
 func (o *ProxyOption) Apply(a *Client) {
-	a.Proxy = proxies.NewProxy(o.tp)
-	a.CloseFun = append(a.CloseFun, a.Proxy.Close)
+	a.service(func() {
+		a.Proxy = proxies.NewProxy(o.tp, a.config)
+		a.CloseFun = append(a.CloseFun, a.Proxy.Close)
+	})
 }
 
 // WithProxy choose proxies type.
@@ -99,8 +129,10 @@ type CaptchaOption struct {
 }
 
 func (o *CaptchaOption) Apply(a *Client) {
-	a.Captcha = captcha.NewCaptcha(o.tp)
-	a.CloseFun = append(a.CloseFun, a.Captcha.Close)
+	a.service(func() {
+		a.Captcha = captcha.NewCaptcha(o.tp, a.config)
+		a.CloseFun = append(a.CloseFun, a.Captcha.Close)
+	})
 }
 
 // WithCaptcha choose captcha type.
@@ -118,7 +150,9 @@ type StorageOption struct {
 }
 
 func (o *StorageOption) Apply(a *Client) {
-	a.Storage = storage.NewStorage(o.tp)
+	a.service(func() {
+		a.Storage = storage.NewStorage(o.tp, a.config)
+	})
 }
 
 // WithStorage choose storage type.
@@ -152,8 +186,10 @@ type DeepSerpOption struct {
 }
 
 func (d *DeepSerpOption) Apply(c *Client) {
-	c.DeepSerp = deepserp.NewDeepSerp(d.tp)
-	c.CloseFun = append(c.CloseFun, c.DeepSerp.Close)
+	c.service(func() {
+		c.DeepSerp = deepserp.NewDeepSerp(d.tp, c.config)
+		c.CloseFun = append(c.CloseFun, c.DeepSerp.Close)
+	})
 }
 
 // WithDeepSerp choose DeepSerp type.
@@ -171,8 +207,10 @@ type ScrapingOption struct {
 }
 
 func (s *ScrapingOption) Apply(c *Client) {
-	c.Scraping = scraping.New(s.tp)
-	c.CloseFun = append(c.CloseFun, c.Scraping.Close)
+	c.service(func() {
+		c.Scraping = scraping.New(s.tp, c.config)
+		c.CloseFun = append(c.CloseFun, c.Scraping.Close)
+	})
 }
 
 // WithScraping choose scraping type.
@@ -190,8 +228,10 @@ type UniversalOption struct {
 }
 
 func (s *UniversalOption) Apply(c *Client) {
-	c.Universal = universal.New(s.tp)
-	c.CloseFun = append(c.CloseFun, c.Universal.Close)
+	c.service(func() {
+		c.Universal = universal.New(s.tp, c.config)
+		c.CloseFun = append(c.CloseFun, c.Universal.Close)
+	})
 }
 
 // WithUniversal choose universal type.
@@ -209,8 +249,10 @@ type ActorOption struct {
 }
 
 func (s *ActorOption) Apply(c *Client) {
-	c.Actor = actor.NewActor(s.tp)
-	c.CloseFun = append(c.CloseFun, c.Actor.Close)
+	c.service(func() {
+		c.Actor = actor.NewActor(s.tp, c.config)
+		c.CloseFun = append(c.CloseFun, c.Actor.Close)
+	})
 }
 
 // WithActor choose Actor type.
@@ -228,8 +270,10 @@ type CrawlOption struct {
 }
 
 func (o *CrawlOption) Apply(c *Client) {
-	c.Crawl = crawl.New()
-	c.CloseFun = append(c.CloseFun, c.Crawl.Close)
+	c.service(func() {
+		c.Crawl = crawl.New(c.config)
+		c.CloseFun = append(c.CloseFun, c.Crawl.Close)
+	})
 }
 
 // WithCrawl choose crawl type.
@@ -247,8 +291,10 @@ type ProfileOption struct {
 }
 
 func (o *ProfileOption) Apply(c *Client) {
-	c.Profile = profile.New()
-	c.CloseFun = append(c.CloseFun, c.Profile.Close)
+	c.service(func() {
+		c.Profile = profile.New(c.config)
+		c.CloseFun = append(c.CloseFun, c.Profile.Close)
+	})
 }
 
 // WithProfile choose profile type.
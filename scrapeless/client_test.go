@@ -2,8 +2,13 @@ package scrapeless
 
 import (
 	"context"
-	"github.com/scrapeless-ai/sdk-go/scrapeless/services/proxies"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/proxies"
 )
 
 func TestNew(t *testing.T) {
@@ -61,3 +66,27 @@ func TestNew(t *testing.T) {
 	//}
 	//log.Infof("%v", captchaResult)
 }
+
+func TestClientsAreIsolated(t *testing.T) {
+	serve := func(name string) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, `{"name":%q,"profileId":%q}`, name, r.Header.Get(env.Get().HTTPHeader))
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+	a := New(WithProfile(), WithAPIKey("key-a"), WithBaseURL(serve("a").URL))
+	b := New(WithBaseURL(serve("b").URL), WithAPIKey("key-b"), WithProfile(), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	defer a.Close()
+	defer b.Close()
+
+	for client, want := range map[*Client]string{a: "a/key-a", b: "b/key-b"} {
+		info, err := client.Profile.GetProfile(context.Background(), "p")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Name + "/" + info.ProfileId; got != want {
+			t.Fatalf("got %s, want %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package scrapeless
+
+import (
+	"net/http"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+)
+
+// ConfigOption overrides the credentials or endpoints of a Client. Values
+// not overridden are read from the environment.
+type ConfigOption struct {
+	apply func(cfg *request.ClientConfig)
+}
+
+func (o *ConfigOption) Apply(c *Client) {
+	o.apply(&c.config)
+}
+
+// WithAPIKey sets the API key sent by every service of the client.
+func WithAPIKey(apiKey string) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) { c.ApiKey = apiKey }}
+}
+
+// WithBaseURL sets the endpoint of the scraping, universal, deepserp,
+// captcha, proxy and profile APIs.
+func WithBaseURL(url string) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) { c.BaseApiUrl = url }}
+}
+
+// WithStorageURL sets the endpoint of the storage API.
+func WithStorageURL(url string) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) { c.StorageUrl = url }}
+}
+
+// WithActorURL sets the endpoint of the actor and router APIs.
+func WithActorURL(url string) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) { c.ActorUrl = url }}
+}
+
+// WithBrowserURL sets the endpoint of the scraping browser.
+func WithBrowserURL(url string) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) { c.BrowserUrl = url }}
+}
+
+// WithCrawlURL sets the endpoint of the crawl API.
+func WithCrawlURL(url string) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) { c.CrawlUrl = url }}
+}
+
+// WithHTTPClient sets the http.Client used by every service of the client.
+// Transport options are ignored when it is set.
+func WithHTTPClient(client *http.Client) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) { c.HTTPClient = client }}
+}
+
+// Config holds the settings of the SDK, see env.Config.
+type Config = env.Config
+
+// LoadConfig reads a Config from the given .env, YAML or TOML files and the
+// environment, the environment taking precedence.
+func LoadConfig(files ...string) (*Config, error) {
+	return env.Load(files...)
+}
+
+// WithConfig takes the API key and endpoints of the client from cfg.
+// Options given after it still override them.
+func WithConfig(cfg *Config) Option {
+	return &ConfigOption{apply: func(c *request.ClientConfig) {
+		from := request.ClientConfigFrom(cfg)
+		from.HTTPClient = c.HTTPClient
+		*c = from
+	}}
+}
@@ -0,0 +1,284 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// Defaults of the BM25 parameters, see WithBM25Params.
+const (
+	DefaultBM25K1 = 1.2
+	DefaultBM25B  = 0.75
+)
+
+// SparseEncoder returns the sparse vectors of texts, keyed by term, for
+// the SparseVector of docs and queries.
+type SparseEncoder interface {
+	// EncodeDocs returns one sparse vector per document, in the order of
+	// texts. The documents are identified by ids, so that encoding a
+	// document again replaces it in the statistics of the encoder; an empty
+	// ID counts its text as a new document.
+	EncodeDocs(ctx context.Context, ids []string, texts []string) ([]map[string]float64, error)
+	// RemoveDocs takes the documents out of the statistics of the encoder.
+	RemoveDocs(ctx context.Context, ids []string) error
+	// EncodeQuery returns the sparse vector of a query, whose dot product
+	// with the vector of a document scores the document.
+	EncodeQuery(ctx context.Context, text string) (map[string]float64, error)
+}
+
+// VocabularyStore persists the vocabulary of a BM25 encoder. A *storage.KV
+// is one.
+type VocabularyStore interface {
+	GetValue(ctx context.Context, namespaceId string, key string) (string, error)
+	SetValue(ctx context.Context, namespaceId string, key string, value string, expiration uint) (bool, error)
+}
+
+// TokenizeKeywords is Tokenize, plus the words holding digits joined across
+// their separators, so that "XJ-9000/b" also yields "xj9000b". The joined
+// token is rare, which makes product codes and versions match precisely.
+func TokenizeKeywords(text string) []string {
+	tokens := Tokenize(text)
+	for _, word := range strings.Fields(text) {
+		parts := Tokenize(word)
+		if len(parts) > 1 && strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+			tokens = append(tokens, strings.Join(parts, ""))
+		}
+	}
+	return tokens
+}
+
+// BM25 is a SparseEncoder scoring documents with Okapi BM25. The vector of
+// a document weighs its terms by their frequency, normalized by the length
+// of the document; the vector of a query weighs its terms by their inverse
+// document frequency. Terms are keyed by their ID in the vocabulary, in
+// decimal, and the terms of a query missing from the vocabulary are dropped.
+//
+// The vocabulary keeps the length and terms of every document encoded with
+// an ID, so that encoding it again or removing it updates the statistics.
+// The encoder is safe for concurrent use.
+type BM25 struct {
+	k1, b    float64
+	tokenize func(string) []string
+
+	store       VocabularyStore
+	namespaceId string
+	key         string
+
+	mu      sync.RWMutex
+	vocab   vocabulary
+	changes int // Changes of the vocabulary, see Save
+	saved   int // Changes written by Save
+}
+
+// vocabulary is the state of a BM25 encoder, as persisted.
+type vocabulary struct {
+	Docs   int                 `json:"docs"`          // Documents encoded
+	Tokens int                 `json:"tokens"`        // Tokens of those documents
+	Terms  map[string][2]int   `json:"terms"`         // ID and document frequency of the terms
+	IDs    map[string]docTerms `json:"ids,omitempty"` // Documents encoded with an ID
+}
+
+// docTerms is what a document added to the statistics.
+type docTerms struct {
+	Tokens int      `json:"tokens"`
+	Terms  []string `json:"terms"` // Distinct terms
+}
+
+// BM25Option configures a BM25 encoder.
+type BM25Option func(*BM25)
+
+// WithBM25Params sets the saturation of term frequencies k1 and the length
+// normalization b, DefaultBM25K1 and DefaultBM25B by default.
+func WithBM25Params(k1, b float64) BM25Option {
+	return func(e *BM25) { e.k1, e.b = k1, b }
+}
+
+// WithTokenizer splits texts with tokenize instead of TokenizeKeywords. It
+// must not change once documents are encoded.
+func WithTokenizer(tokenize func(string) []string) BM25Option {
+	return func(e *BM25) { e.tokenize = tokenize }
+}
+
+// NewBM25 returns a BM25 encoder with an empty vocabulary kept in memory.
+func NewBM25(opts ...BM25Option) *BM25 {
+	e := &BM25{k1: DefaultBM25K1, b: DefaultBM25B, tokenize: TokenizeKeywords, vocab: vocabulary{Terms: map[string][2]int{}, IDs: map[string]docTerms{}}}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// LoadBM25 returns a BM25 encoder whose vocabulary is read from the key of
+// a KV namespace, empty when the key is missing, and written back there by
+// Save. Encoders sharing a key from several processes overwrite each other's
+// statistics; give each writer its own key.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	store: The KV storage.
+//	namespaceId: The namespace holding the vocabulary.
+//	key: The key of the vocabulary.
+func LoadBM25(ctx context.Context, store VocabularyStore, namespaceId, key string, opts ...BM25Option) (*BM25, error) {
+	e := NewBM25(opts...)
+	e.store, e.namespaceId, e.key = store, namespaceId, key
+	data, err := store.GetValue(ctx, namespaceId, key)
+	if err != nil && !errors.Is(err, scerrors.ErrNotFound) {
+		return nil, scerrors.From(err)
+	}
+	if data == "" {
+		return e, nil
+	}
+	if err = json.Unmarshal([]byte(data), &e.vocab); err != nil {
+		return nil, scerrors.Newf(scerrors.KindInternal, "decode BM25 vocabulary %q: %v", key, err)
+	}
+	if e.vocab.Terms == nil {
+		e.vocab.Terms = map[string][2]int{}
+	}
+	if e.vocab.IDs == nil {
+		e.vocab.IDs = map[string]docTerms{}
+	}
+	return e, nil
+}
+
+// Save writes the vocabulary to the key it was loaded from, when it changed
+// since the last Save. It does nothing for the encoders of NewBM25.
+// Parameters:
+//
+//	ctx: The context for the request.
+func (e *BM25) Save(ctx context.Context) error {
+	if e.store == nil {
+		return nil
+	}
+	e.mu.RLock()
+	changes := e.changes
+	if changes == e.saved {
+		e.mu.RUnlock()
+		return nil
+	}
+	data, err := json.Marshal(e.vocab)
+	e.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if _, err = e.store.SetValue(ctx, e.namespaceId, e.key, string(data), 0); err != nil {
+		return scerrors.From(err)
+	}
+	e.mu.Lock()
+	e.saved = max(e.saved, changes)
+	e.mu.Unlock()
+	return nil
+}
+
+// Len returns the number of terms in the vocabulary.
+func (e *BM25) Len() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.vocab.Terms)
+}
+
+func (e *BM25) EncodeDocs(ctx context.Context, ids []string, texts []string) ([]map[string]float64, error) {
+	if len(ids) != len(texts) {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "%d IDs for %d texts", len(ids), len(texts))
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	counts := make([]map[string]int, len(texts))
+	lengths := make([]int, len(texts))
+	for i, text := range texts {
+		tokens := e.tokenize(text)
+		counts[i] = make(map[string]int, len(tokens))
+		for _, token := range tokens {
+			counts[i][token]++
+		}
+		lengths[i] = len(tokens)
+		e.remove(ids[i])
+		e.vocab.Docs++
+		e.vocab.Tokens += len(tokens)
+		terms := make([]string, 0, len(counts[i]))
+		for token := range counts[i] {
+			t, ok := e.vocab.Terms[token]
+			if !ok {
+				t[0] = len(e.vocab.Terms)
+			}
+			t[1]++
+			e.vocab.Terms[token] = t
+			terms = append(terms, token)
+		}
+		if ids[i] != "" {
+			slices.Sort(terms)
+			e.vocab.IDs[ids[i]] = docTerms{Tokens: len(tokens), Terms: terms}
+		}
+	}
+	e.changes++
+
+	avgLength := float64(e.vocab.Tokens) / float64(max(e.vocab.Docs, 1))
+	vectors := make([]map[string]float64, len(texts))
+	for i := range texts {
+		norm := e.k1 * (1 - e.b + e.b*float64(lengths[i])/max(avgLength, 1))
+		vectors[i] = make(map[string]float64, len(counts[i]))
+		for token, n := range counts[i] {
+			tf := float64(n)
+			vectors[i][termKey(e.vocab.Terms[token])] = tf * (e.k1 + 1) / (tf + norm)
+		}
+	}
+	return vectors, nil
+}
+
+func (e *BM25) RemoveDocs(ctx context.Context, ids []string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, id := range ids {
+		e.remove(id)
+	}
+	return nil
+}
+
+// remove takes the document out of the statistics, if it was encoded. The
+// terms keep their ID, which the stored vectors refer to. It must be called
+// with e.mu held.
+func (e *BM25) remove(id string) {
+	doc, ok := e.vocab.IDs[id]
+	if id == "" || !ok {
+		return
+	}
+	delete(e.vocab.IDs, id)
+	e.vocab.Docs--
+	e.vocab.Tokens -= doc.Tokens
+	for _, token := range doc.Terms {
+		t := e.vocab.Terms[token]
+		t[1] = max(t[1]-1, 0)
+		e.vocab.Terms[token] = t
+	}
+	e.changes++
+}
+
+func (e *BM25) EncodeQuery(ctx context.Context, text string) (map[string]float64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	vector := map[string]float64{}
+	n := float64(e.vocab.Docs)
+	for _, token := range e.tokenize(text) {
+		t, ok := e.vocab.Terms[token]
+		if !ok {
+			continue
+		}
+		df := float64(t[1])
+		vector[termKey(t)] += math.Log(1 + (n-df+0.5)/(df+0.5))
+	}
+	return vector, nil
+}
+
+func termKey(t [2]int) string {
+	return strconv.Itoa(t[0])
+}
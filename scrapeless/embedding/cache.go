@@ -0,0 +1,93 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// cache keeps the vectors of the most recently embedded texts.
+type cache struct {
+	embedder Embedder
+	size     int
+
+	mu      sync.Mutex
+	order   *list.List // Of *cacheEntry, most recently used first
+	entries map[textKey]*list.Element
+}
+
+type cacheEntry struct {
+	key    textKey
+	vector []float64
+}
+
+// Cache remembers the vectors of up to size texts, the least recently used
+// being forgotten first. Only the texts missing from the cache are passed to
+// e, once each even when repeated. The returned vectors are shared: they must
+// not be modified.
+func Cache(e Embedder, size int) Embedder {
+	if size < 1 {
+		size = 1
+	}
+	return &cache{embedder: e, size: size, order: list.New(), entries: map[textKey]*list.Element{}}
+}
+
+func (c *cache) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	keys := make([]textKey, len(texts))
+	var (
+		missing []string
+		pending = map[textKey][]int{} // Indexes of the texts to embed
+	)
+	c.mu.Lock()
+	for i, text := range texts {
+		keys[i] = keyOf(text)
+		if elem, ok := c.entries[keys[i]]; ok {
+			c.order.MoveToFront(elem)
+			vectors[i] = elem.Value.(*cacheEntry).vector
+			continue
+		}
+		if _, ok := pending[keys[i]]; !ok {
+			missing = append(missing, text)
+		}
+		pending[keys[i]] = append(pending[keys[i]], i)
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	embedded, err := c.embedder.Embed(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	if len(embedded) != len(missing) {
+		return nil, scerrors.Newf(scerrors.KindInternal, "embedder returned %d vectors for %d texts", len(embedded), len(missing))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, text := range missing {
+		key := keyOf(text)
+		for _, index := range pending[key] {
+			vectors[index] = embedded[i]
+		}
+		c.add(key, embedded[i])
+	}
+	return vectors, nil
+}
+
+func (c *cache) add(key textKey, vector []float64) {
+	if elem, ok := c.entries[key]; ok {
+		c.order.MoveToFront(elem)
+		elem.Value.(*cacheEntry).vector = vector
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, vector: vector})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
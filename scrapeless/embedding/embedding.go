@@ -0,0 +1,71 @@
+// Package embedding turns texts into vectors for the vector storage.
+//
+// An Embedder is either computed locally, see NewHashing, or requested from
+// an OpenAI-compatible API, see NewOpenAI. Batch and Cache wrap any
+// embedder to bound the texts sent at once and to reuse vectors:
+//
+//	e := embedding.Cache(embedding.Batch(embedding.NewOpenAI("", key, "text-embedding-3-small"), 256), 10000)
+//	vectors, err := e.Embed(ctx, []string{"first text", "second text"})
+//
+// A SparseEncoder, like the BM25 encoder of NewBM25 and LoadBM25, computes
+// the sparse vectors of keyword search instead.
+package embedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"strings"
+	"unicode"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// Embedder returns the vectors of texts.
+type Embedder interface {
+	// Embed returns one vector per text, in the order of texts. The vectors
+	// of an embedder all have the same dimension.
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface.
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float64, error)
+
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return f(ctx, texts)
+}
+
+// Tokenize splits a text into lower-case words made of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Batch splits the calls to e into calls of at most size texts.
+func Batch(e Embedder, size int) Embedder {
+	if size < 1 {
+		size = 1
+	}
+	return EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		vectors := make([][]float64, 0, len(texts))
+		for start := 0; start < len(texts); start += size {
+			batch := texts[start:min(start+size, len(texts))]
+			embedded, err := e.Embed(ctx, batch)
+			if err != nil {
+				return nil, err
+			}
+			if len(embedded) != len(batch) {
+				return nil, scerrors.Newf(scerrors.KindInternal, "embedder returned %d vectors for %d texts", len(embedded), len(batch))
+			}
+			vectors = append(vectors, embedded...)
+		}
+		return vectors, nil
+	})
+}
+
+// textKey identifies a text in a cache without keeping it.
+type textKey [sha256.Size]byte
+
+func keyOf(text string) textKey {
+	return sha256.Sum256([]byte(text))
+}
@@ -0,0 +1,252 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+func cosine(a, b []float64) float64 {
+	var dot float64
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot
+}
+
+func TestHashing(t *testing.T) {
+	h := NewHashing(128)
+	ctx := context.Background()
+	vectors, err := h.Embed(ctx, []string{
+		"Cheap flights to Paris in spring",
+		"cheap FLIGHTS to paris, in spring!",
+		"Paris flights are cheap in spring",
+		"Chocolate cake recipe",
+		"",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vectors[:4] {
+		if len(v) != 128 || math.Abs(cosine(v, v)-1) > 1e-9 {
+			t.Fatalf("vector of norm %v", cosine(v, v))
+		}
+	}
+	if !reflect.DeepEqual(vectors[0], vectors[1]) {
+		t.Fatal("case and punctuation changed the vector")
+	}
+	if near, far := cosine(vectors[0], vectors[2]), cosine(vectors[0], vectors[3]); near <= far || near < 0.3 {
+		t.Fatalf("similar texts %.2f, different texts %.2f", near, far)
+	}
+	if cosine(vectors[4], vectors[4]) != 0 {
+		t.Fatal("an empty text has a vector")
+	}
+	again, _ := NewHashing(128).Embed(ctx, []string{"Cheap flights to Paris in spring"})
+	if !reflect.DeepEqual(again[0], vectors[0]) {
+		t.Fatal("hashing is not deterministic")
+	}
+}
+
+// countingEmbedder records the texts it is asked to embed.
+type countingEmbedder struct {
+	calls [][]string
+}
+
+func (c *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	c.calls = append(c.calls, texts)
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = []float64{float64(len(text))}
+	}
+	return vectors, nil
+}
+
+func TestBatchAndCache(t *testing.T) {
+	inner := &countingEmbedder{}
+	e := Cache(Batch(inner, 2), 3)
+	ctx := context.Background()
+
+	vectors, err := e.Embed(ctx, []string{"a", "bb", "a", "ccc", "dddd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vectors, [][]float64{{1}, {2}, {1}, {3}, {4}}) {
+		t.Fatalf("vectors %v", vectors)
+	}
+	if !reflect.DeepEqual(inner.calls, [][]string{{"a", "bb"}, {"ccc", "dddd"}}) {
+		t.Fatalf("calls %v", inner.calls)
+	}
+
+	// "a" was the least recently used of the 4 texts and was forgotten.
+	inner.calls = nil
+	if _, err = e.Embed(ctx, []string{"dddd", "bb", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(inner.calls, [][]string{{"a"}}) {
+		t.Fatalf("calls %v", inner.calls)
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`))
+			return
+		}
+		var req openAIRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "small" || req.Dimensions != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var data []map[string]any
+		// Answer out of order, the index tells which text a vector is for.
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, map[string]any{"index": i, "embedding": []float64{float64(len(req.Input[i])), 0}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	e := NewOpenAI(srv.URL+"/v1/", "secret", "small", WithDimensions(2))
+	vectors, err := Batch(e, 2).Embed(ctx, []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vectors, [][]float64{{1, 0}, {2, 0}, {3, 0}}) || requests.Load() != 2 {
+		t.Fatalf("vectors %v in %d requests", vectors, requests.Load())
+	}
+
+	_, err = NewOpenAI(srv.URL+"/v1", "wrong", "small").Embed(ctx, []string{"a"})
+	if !errors.Is(err, scerrors.ErrUnauthorized) || !strings.Contains(err.Error(), "Incorrect API key") {
+		t.Fatalf("want an unauthorized error, got %v", err)
+	}
+}
+
+// mapStore is a VocabularyStore in memory counting its writes.
+type mapStore struct {
+	values map[string]string
+	writes int
+}
+
+func (m *mapStore) GetValue(ctx context.Context, namespaceId string, key string) (string, error) {
+	value, ok := m.values[namespaceId+"/"+key]
+	if !ok {
+		return "", scerrors.ErrNotFound
+	}
+	return value, nil
+}
+
+func (m *mapStore) SetValue(ctx context.Context, namespaceId string, key string, value string, expiration uint) (bool, error) {
+	m.values[namespaceId+"/"+key] = value
+	m.writes++
+	return true, nil
+}
+
+func sparseDot(a, b map[string]float64) float64 {
+	var dot float64
+	for k, v := range a {
+		dot += v * b[k]
+	}
+	return dot
+}
+
+func TestTokenizeKeywords(t *testing.T) {
+	got := TokenizeKeywords("Case for XJ-9000/b, black")
+	want := []string{"case", "for", "xj", "9000", "b", "black", "xj9000b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("tokens %q, want %q", got, want)
+	}
+}
+
+func TestBM25(t *testing.T) {
+	ctx := context.Background()
+	store := &mapStore{values: map[string]string{}}
+	e, err := LoadBM25(ctx, store, "ns", "vocab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []string{
+		"Phone case XJ-9000-B in black",
+		"Phone case XJ-9000-A in blue",
+		"A phone charger for the phone",
+		"Laptop stand in black aluminium, sturdy, light and foldable for travel",
+	}
+	vectors, err := e.EncodeDocs(ctx, []string{"b", "a", "charger", "stand"}, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query, _ := e.EncodeQuery(ctx, "xj9000b")
+	if s0, s1 := sparseDot(query, vectors[0]), sparseDot(query, vectors[1]); s0 <= 0 || s1 != 0 {
+		t.Fatalf("code query scores %.2f and %.2f", s0, s1)
+	}
+	// Rare terms weigh more than common ones, and a term repeated in a
+	// short document more than in a long one.
+	query, _ = e.EncodeQuery(ctx, "blue phone")
+	if blue, phone := sparseDot(query, vectors[1]), sparseDot(query, vectors[2]); blue <= phone {
+		t.Fatalf("rare term %.2f, common term %.2f", blue, phone)
+	}
+	query, _ = e.EncodeQuery(ctx, "black")
+	if short, long := sparseDot(query, vectors[0]), sparseDot(query, vectors[3]); short <= long {
+		t.Fatalf("short document %.2f, long document %.2f", short, long)
+	}
+	if query, _ = e.EncodeQuery(ctx, "unknown words"); len(query) != 0 {
+		t.Fatalf("unknown terms encoded: %v", query)
+	}
+
+	// Encoding documents again replaces them in the statistics, removing
+	// them takes them out.
+	before, _ := e.EncodeQuery(ctx, "black phone case")
+	again, err := e.EncodeDocs(ctx, []string{"b", "a"}, docs[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, _ := e.EncodeQuery(ctx, "black phone case")
+	if e.vocab.Docs != 4 || !reflect.DeepEqual(before, after) || !reflect.DeepEqual(again[0], vectors[0]) {
+		t.Fatalf("re-encoding changed the statistics: %v, %v", before, after)
+	}
+	if err = e.RemoveDocs(ctx, []string{"stand", "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	query, _ = e.EncodeQuery(ctx, "black")
+	if idf := sparseDot(query, vectors[0]) / sparseDot(before, vectors[0]); idf >= 1 {
+		t.Fatalf("black is in 2 of 3 documents, its weight did not drop: %v", query)
+	}
+
+	if err = e.Save(ctx); err != nil || store.writes != 1 {
+		t.Fatalf("save: %v, %d writes", err, store.writes)
+	}
+	if err = e.Save(ctx); err != nil || store.writes != 1 {
+		t.Fatalf("unchanged vocabulary saved again: %v, %d writes", err, store.writes)
+	}
+	loaded, err := LoadBM25(ctx, store, "ns", "vocab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q1, _ := e.EncodeQuery(ctx, "black phone case")
+	q2, _ := loaded.EncodeQuery(ctx, "black phone case")
+	if !reflect.DeepEqual(q1, q2) || loaded.Len() != e.Len() {
+		t.Fatalf("loaded vocabulary differs: %v, %v", q1, q2)
+	}
+	// The loaded encoder still knows the documents.
+	if err = loaded.RemoveDocs(ctx, []string{"a", "b", "charger"}); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.vocab.Docs != 0 || loaded.vocab.Tokens != 0 || loaded.vocab.Terms["phone"][1] != 0 {
+		t.Fatalf("statistics left after removing every document: %+v", loaded.vocab)
+	}
+}
@@ -0,0 +1,79 @@
+package embedding
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+)
+
+// DefaultHashingDimension is the dimension of NewHashing vectors when none
+// is given.
+const DefaultHashingDimension = 256
+
+// Hashing embeds texts offline by hashing their words and pairs of adjacent
+// words into a fixed number of dimensions. Texts sharing words get close
+// vectors: it is deterministic and fast, which suits tests and keyword-like
+// search, but it knows nothing of meaning.
+type Hashing struct {
+	dim int
+}
+
+// NewHashing returns a Hashing embedder of vectors of dim dimensions.
+func NewHashing(dim int) *Hashing {
+	if dim < 1 {
+		dim = DefaultHashingDimension
+	}
+	return &Hashing{dim: dim}
+}
+
+// Dimension returns the length of the vectors.
+func (h *Hashing) Dimension() int {
+	return h.dim
+}
+
+// Embed returns the normalized vectors of texts. A text without words gets
+// a zero vector.
+func (h *Hashing) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		vectors[i] = h.embed(text)
+	}
+	return vectors, nil
+}
+
+func (h *Hashing) embed(text string) []float64 {
+	counts := map[string]int{}
+	tokens := Tokenize(text)
+	for i, token := range tokens {
+		counts[token]++
+		if i > 0 {
+			counts[tokens[i-1]+" "+token]++
+		}
+	}
+	vector := make([]float64, h.dim)
+	for feature, n := range counts {
+		sum := fnv.New64a()
+		_, _ = sum.Write([]byte(feature))
+		hash := sum.Sum64()
+		// The sign bit spreads the collisions of features around zero.
+		weight := 1 + math.Log(float64(n))
+		if hash>>63 == 1 {
+			weight = -weight
+		}
+		vector[hash%uint64(h.dim)] += weight
+	}
+	var norm float64
+	for _, v := range vector {
+		norm += v * v
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+	return vector
+}
@@ -0,0 +1,120 @@
+package embedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// DefaultOpenAIURL is the API of NewOpenAI embedders without a base URL.
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// OpenAI requests embeddings from an OpenAI-compatible API, like OpenAI,
+// Azure OpenAI, Ollama or vLLM. Wrap it with Batch to respect the limit of
+// inputs per request of the API.
+type OpenAI struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	client     *http.Client
+}
+
+// OpenAIOption configures an OpenAI embedder.
+type OpenAIOption func(*OpenAI)
+
+// WithDimensions asks models which support it, like text-embedding-3, for
+// vectors of n dimensions.
+func WithDimensions(n int) OpenAIOption {
+	return func(o *OpenAI) { o.dimensions = n }
+}
+
+// WithHTTPClient sends the requests with client instead of the SDK
+// transport.
+func WithHTTPClient(client *http.Client) OpenAIOption {
+	return func(o *OpenAI) { o.client = client }
+}
+
+// NewOpenAI returns an embedder calling the /embeddings endpoint of the API.
+// Parameters:
+//
+//	baseURL: The URL of the API, like "http://localhost:11434/v1", DefaultOpenAIURL when empty.
+//	apiKey: The bearer token, none when empty.
+//	model: The embedding model, like "text-embedding-3-small".
+func NewOpenAI(baseURL, apiKey, model string, opts ...OpenAIOption) *OpenAI {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIURL
+	}
+	o := &OpenAI{baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, model: model}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.client == nil {
+		o.client = request.Default().Client()
+	}
+	return o
+}
+
+type openAIRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+type openAIResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (o *OpenAI) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(openAIRequest{Model: o.model, Input: texts, Dimensions: o.dimensions, EncodingFormat: "float"})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, scerrors.From(err)
+	}
+	defer resp.Body.Close()
+	all, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, scerrors.From(err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, scerrors.FromHTTP(resp.StatusCode, resp.Header, all)
+	}
+	var result openAIResponse
+	if err = json.Unmarshal(all, &result); err != nil {
+		return nil, scerrors.Newf(scerrors.KindInternal, "decode embeddings: %v", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, scerrors.Newf(scerrors.KindInternal, "embeddings API returned %d vectors for %d texts", len(result.Data), len(texts))
+	}
+	sort.Slice(result.Data, func(i, j int) bool { return result.Data[i].Index < result.Data[j].Index })
+	vectors := make([][]float64, len(texts))
+	for i, data := range result.Data {
+		vectors[i] = data.Embedding
+	}
+	return vectors, nil
+}
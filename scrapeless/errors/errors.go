@@ -0,0 +1,353 @@
+// Package errors defines the error type returned by every Scrapeless service.
+//
+// Errors carry the HTTP status, the API code and the request ID of the call
+// that failed, and are classified into a Kind so callers can react without
+// parsing messages:
+//
+//	if errors.Is(err, scerrors.ErrNotFound) { ... }
+//
+//	var apiErr *scerrors.Error
+//	if errors.As(err, &apiErr) && apiErr.Retryable { ... }
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Kind classifies an error.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindInvalidArgument
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindQuotaExceeded
+	KindRateLimited
+	KindTimeout
+	KindCanceled
+	KindUnavailable
+	KindInternal
+	KindNotImplemented
+)
+
+var kindNames = map[Kind]string{
+	KindUnknown:         "unknown",
+	KindInvalidArgument: "invalid argument",
+	KindUnauthorized:    "unauthorized",
+	KindForbidden:       "forbidden",
+	KindNotFound:        "not found",
+	KindConflict:        "conflict",
+	KindQuotaExceeded:   "quota exceeded",
+	KindRateLimited:     "rate limited",
+	KindTimeout:         "timeout",
+	KindCanceled:        "canceled",
+	KindUnavailable:     "unavailable",
+	KindInternal:        "internal error",
+	KindNotImplemented:  "not implemented",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", int(k))
+}
+
+// Retryable reports whether errors of this kind are usually transient.
+func (k Kind) Retryable() bool {
+	switch k {
+	case KindRateLimited, KindTimeout, KindUnavailable:
+		return true
+	}
+	return false
+}
+
+// Sentinels to compare against with errors.Is. Any *Error of the same Kind
+// matches them.
+var (
+	ErrInvalidArgument = &Error{Kind: KindInvalidArgument}
+	ErrUnauthorized    = &Error{Kind: KindUnauthorized}
+	ErrForbidden       = &Error{Kind: KindForbidden}
+	ErrNotFound        = &Error{Kind: KindNotFound}
+	ErrConflict        = &Error{Kind: KindConflict}
+	ErrQuotaExceeded   = &Error{Kind: KindQuotaExceeded}
+	ErrRateLimited     = &Error{Kind: KindRateLimited}
+	ErrTimeout         = &Error{Kind: KindTimeout}
+	ErrCanceled        = &Error{Kind: KindCanceled}
+	ErrUnavailable     = &Error{Kind: KindUnavailable}
+	ErrInternal        = &Error{Kind: KindInternal}
+	ErrNotImplemented  = &Error{Kind: KindNotImplemented}
+)
+
+// Error is the error returned by Scrapeless services.
+type Error struct {
+	Kind       Kind   // Classification of the failure
+	HTTPStatus int    // HTTP status of the response, 0 when no response was received
+	Code       int    // Code reported by the API, 0 when absent
+	Message    string // Message reported by the API or describing the failure
+	RequestID  string // Request ID echoed by the API, useful when contacting support
+	Retryable  bool   // Whether repeating the call may succeed
+	Err        error  // Underlying cause, if any
+}
+
+// New returns an error of the given kind.
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message, Retryable: kind.Retryable()}
+}
+
+// Newf returns an error of the given kind with a formatted message.
+func Newf(kind Kind, format string, args ...any) *Error {
+	return New(kind, fmt.Sprintf(format, args...))
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Kind.String())
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	} else if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+	var details []string
+	if e.HTTPStatus != 0 {
+		details = append(details, fmt.Sprintf("status %d", e.HTTPStatus))
+	}
+	if e.Code != 0 {
+		details = append(details, fmt.Sprintf("code %d", e.Code))
+	}
+	if e.RequestID != "" {
+		details = append(details, "request id "+e.RequestID)
+	}
+	if len(details) > 0 {
+		b.WriteString(" (")
+		b.WriteString(strings.Join(details, ", "))
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches sentinel errors of the same kind.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Kind == e.Kind && t.Message == "" && t.HTTPStatus == 0 && t.Code == 0 && t.Err == nil
+}
+
+// KindOf returns the kind of err, or KindUnknown if it is not an *Error.
+func KindOf(err error) Kind {
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e.Kind
+	}
+	return KindUnknown
+}
+
+// IsRetryable reports whether repeating the call that returned err may succeed.
+func IsRetryable(err error) bool {
+	var e *Error
+	if !stderrors.As(From(err), &e) {
+		return false
+	}
+	return e.Retryable
+}
+
+// API codes returned by the Scrapeless backend.
+const (
+	apiCodeNotFound        = 200005
+	apiCodeAlreadyExists   = 200006
+	apiCodeInvalidArgument = 400403
+	apiCodeSystem          = 500000
+	apiCodeUnavailable     = 500014
+	apiCodeUnauthorized    = 500401
+)
+
+// FromResponse builds an error from an API failure. status may be 200 when
+// the API reports the failure in the response envelope.
+func FromResponse(status, code int, message, requestID string) *Error {
+	kind := kindFromCode(code)
+	if kind == KindUnknown {
+		kind = kindFromStatus(status)
+	}
+	if kind == KindUnknown || kind == KindForbidden || kind == KindInvalidArgument {
+		if isQuotaMessage(message) {
+			kind = KindQuotaExceeded
+		}
+	}
+	if message == "" && status != 0 {
+		message = http.StatusText(status)
+	}
+	return &Error{
+		Kind:       kind,
+		HTTPStatus: status,
+		Code:       code,
+		Message:    message,
+		RequestID:  requestID,
+		Retryable:  kind.Retryable() || status == http.StatusInternalServerError,
+	}
+}
+
+// FromHTTP builds an error from a failed HTTP response, reading the message
+// and code from the usual body shapes of the API.
+func FromHTTP(status int, header http.Header, body []byte) *Error {
+	message, code := "", 0
+	if gjson.ValidBytes(body) {
+		result := gjson.ParseBytes(body)
+		for _, key := range []string{"msg", "message", "error", "error.message"} {
+			if v := result.Get(key); v.Type == gjson.String && v.String() != "" {
+				message = v.String()
+				break
+			}
+		}
+		code = int(result.Get("code").Int())
+	} else if len(body) > 0 && len(body) <= 512 {
+		message = strings.TrimSpace(string(body))
+	}
+	return FromResponse(status, code, message, RequestID(header))
+}
+
+// RequestID returns the request ID echoed in the response headers.
+func RequestID(header http.Header) string {
+	for _, key := range []string{"X-Request-Id", "X-Trace-Id", "Trace-Id"} {
+		if v := header.Get(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// From converts any error into an *Error, keeping errors that already are
+// one untouched. Context, network and gRPC status errors are classified;
+// everything else becomes KindUnknown wrapping the original error.
+func From(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if stderrors.As(err, &e) {
+		return err
+	}
+	switch {
+	case stderrors.Is(err, context.Canceled):
+		return &Error{Kind: KindCanceled, Err: err}
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: KindTimeout, Err: err, Retryable: true}
+	}
+	var netErr net.Error
+	if stderrors.As(err, &netErr) {
+		kind := KindUnavailable
+		if netErr.Timeout() {
+			kind = KindTimeout
+		}
+		return &Error{Kind: kind, Err: err, Retryable: true}
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		kind := kindFromGRPC(s.Code())
+		return &Error{Kind: kind, Message: s.Message(), Err: err, Retryable: kind.Retryable()}
+	}
+	return &Error{Kind: KindUnknown, Err: err}
+}
+
+func kindFromStatus(status int) Kind {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return KindInvalidArgument
+	case http.StatusUnauthorized:
+		return KindUnauthorized
+	case http.StatusPaymentRequired:
+		return KindQuotaExceeded
+	case http.StatusForbidden:
+		return KindForbidden
+	case http.StatusNotFound, http.StatusGone:
+		return KindNotFound
+	case http.StatusConflict:
+		return KindConflict
+	case http.StatusTooManyRequests:
+		return KindRateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return KindTimeout
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return KindUnavailable
+	case http.StatusNotImplemented:
+		return KindNotImplemented
+	}
+	if status >= http.StatusInternalServerError {
+		return KindInternal
+	}
+	return KindUnknown
+}
+
+func kindFromCode(code int) Kind {
+	switch code {
+	case apiCodeNotFound:
+		return KindNotFound
+	case apiCodeAlreadyExists:
+		return KindConflict
+	case apiCodeInvalidArgument:
+		return KindInvalidArgument
+	case apiCodeUnauthorized:
+		return KindUnauthorized
+	case apiCodeUnavailable:
+		return KindUnavailable
+	case apiCodeSystem:
+		return KindInternal
+	}
+	return KindUnknown
+}
+
+func kindFromGRPC(c codes.Code) Kind {
+	switch c {
+	case codes.InvalidArgument, codes.OutOfRange, codes.FailedPrecondition:
+		return KindInvalidArgument
+	case codes.Unauthenticated:
+		return KindUnauthorized
+	case codes.PermissionDenied:
+		return KindForbidden
+	case codes.NotFound:
+		return KindNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return KindConflict
+	case codes.ResourceExhausted:
+		return KindRateLimited
+	case codes.DeadlineExceeded:
+		return KindTimeout
+	case codes.Canceled:
+		return KindCanceled
+	case codes.Unavailable:
+		return KindUnavailable
+	case codes.Unimplemented:
+		return KindNotImplemented
+	case codes.Internal, codes.DataLoss:
+		return KindInternal
+	}
+	return kindFromCode(int(c))
+}
+
+func isQuotaMessage(message string) bool {
+	m := strings.ToLower(message)
+	for _, hint := range []string{"quota", "insufficient balance", "insufficient credit", "out of credit", "exceeded your"} {
+		if strings.Contains(m, hint) {
+			return true
+		}
+	}
+	return false
+}
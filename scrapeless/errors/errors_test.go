@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFromHTTP(t *testing.T) {
+	header := http.Header{"X-Request-Id": {"req-1"}}
+	tests := []struct {
+		status    int
+		body      string
+		kind      Kind
+		code      int
+		message   string
+		retryable bool
+	}{
+		{http.StatusNotFound, `{"msg":"dataset missing","code":200005}`, KindNotFound, 200005, "dataset missing", false},
+		{http.StatusUnauthorized, `{"message":"bad token"}`, KindUnauthorized, 0, "bad token", false},
+		{http.StatusForbidden, `{"error":"quota exceeded for plan"}`, KindQuotaExceeded, 0, "quota exceeded for plan", false},
+		{http.StatusTooManyRequests, ``, KindRateLimited, 0, "Too Many Requests", true},
+		{http.StatusInternalServerError, `boom`, KindInternal, 0, "boom", true},
+		{http.StatusBadRequest, `{"msg":"unavailable","code":500014}`, KindUnavailable, 500014, "unavailable", true},
+	}
+	for _, tt := range tests {
+		err := FromHTTP(tt.status, header, []byte(tt.body))
+		if err.Kind != tt.kind || err.Code != tt.code || err.Message != tt.message || err.Retryable != tt.retryable {
+			t.Errorf("%d %s: got %+v", tt.status, tt.body, err)
+		}
+		if err.HTTPStatus != tt.status || err.RequestID != "req-1" {
+			t.Errorf("%d %s: status or request id lost: %+v", tt.status, tt.body, err)
+		}
+	}
+}
+
+func TestIsAndAs(t *testing.T) {
+	err := fmt.Errorf("get dataset: %w", FromResponse(0, 200005, "dataset missing", ""))
+	if !stderrors.Is(err, ErrNotFound) {
+		t.Fatal("wrapped not found error should match ErrNotFound")
+	}
+	if stderrors.Is(err, ErrConflict) {
+		t.Fatal("not found error should not match ErrConflict")
+	}
+	var apiErr *Error
+	if !stderrors.As(err, &apiErr) || apiErr.Code != 200005 {
+		t.Fatalf("As failed: %v", apiErr)
+	}
+	if KindOf(err) != KindNotFound {
+		t.Fatalf("KindOf: %v", KindOf(err))
+	}
+
+	specific := New(KindNotFound, "a")
+	if stderrors.Is(New(KindNotFound, "b"), specific) {
+		t.Fatal("only bare sentinels match by kind")
+	}
+}
+
+func TestFrom(t *testing.T) {
+	typed := New(KindConflict, "exists")
+	if From(typed) != typed {
+		t.Fatal("typed errors must be returned untouched")
+	}
+	if From(nil) != nil {
+		t.Fatal("nil must stay nil")
+	}
+	if IsRetryable(nil) || KindOf(nil) != KindUnknown {
+		t.Fatal("nil must be neither retryable nor of a kind")
+	}
+
+	cause := &net.OpError{Op: "dial", Err: stderrors.New("connection refused")}
+	tests := []struct {
+		err       error
+		kind      Kind
+		retryable bool
+	}{
+		{context.Canceled, KindCanceled, false},
+		{fmt.Errorf("poll: %w", context.DeadlineExceeded), KindTimeout, true},
+		{cause, KindUnavailable, true},
+		{status.Error(codes.NotFound, "missing"), KindNotFound, false},
+		{status.Error(codes.Unavailable, "down"), KindUnavailable, true},
+		{status.Error(200006, "duplicated"), KindConflict, false},
+		{stderrors.New("something else"), KindUnknown, false},
+	}
+	for _, tt := range tests {
+		err := From(tt.err)
+		if KindOf(err) != tt.kind || IsRetryable(err) != tt.retryable {
+			t.Errorf("%v: got kind %v retryable %v", tt.err, KindOf(err), IsRetryable(err))
+		}
+		if !stderrors.Is(err, tt.err) {
+			t.Errorf("%v: cause is not preserved", tt.err)
+		}
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	err := FromResponse(http.StatusNotFound, 200005, "dataset missing", "abc")
+	want := "not found: dataset missing (status 404, code 200005, request id abc)"
+	if err.Error() != want {
+		t.Fatalf("got %q, want %q", err.Error(), want)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -39,11 +40,13 @@ const (
 )
 
 var (
-	logger zerolog.Logger
+	// logger is created on first use, so that importing the package neither
+	// reads the config nor touches the file system.
+	logger = sync.OnceValue(newLogger)
 	lj     *lumberjack.Logger
 )
 
-func init() {
+func newLogger() *zerolog.Logger {
 	logDir := helper.Coalesce(env.GetLogEnv().LogRootDir, logRootDir)
 
 	filename := fmt.Sprintf("%s/%s", logDir, fileName)
@@ -60,13 +63,13 @@ func init() {
 	multi := getWriter(filename)
 
 	// New logger
-	logger = zerolog.New(multi).
+	l := zerolog.New(multi).
 		With().
 		Timestamp().
 		Caller().
-		Logger()
-
-	logger = logger.Hook(tracingHook{})
+		Logger().
+		Hook(tracingHook{})
+	return &l
 }
 
 type tracingHook struct{}
@@ -82,6 +85,7 @@ func (t tracingHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
 }
 
 func archiveCurrentLog() error {
+	logger()
 	logPath := lj.Filename
 	if _, err := os.Stat(logPath); os.IsNotExist(err) {
 		fmt.Printf("Log file does not exist: %s\n", logPath)
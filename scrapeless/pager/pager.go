@@ -0,0 +1,180 @@
+// Package pager turns paginated list calls into Go iterators.
+//
+// An iterator fetches the first page, then keeps up to Prefetch further pages
+// in flight while the caller consumes items, and yields them in order:
+//
+//	for item, err := range pager.Seq(ctx, fetch) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// Breaking out of the loop or cancelling the context stops the fetches that
+// are still running.
+package pager
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+const (
+	DefaultPageSize = 10
+	defaultPrefetch = 2
+)
+
+// Fetch returns the items of a page, pages starting at 1, and the total
+// number of items, or 0 when the API does not report it.
+type Fetch[T any] func(ctx context.Context, page, pageSize int64) (items []T, total int64, err error)
+
+// Options controls how pages are fetched.
+type Options struct {
+	PageSize  int64 // Items per page
+	StartPage int64 // First page to fetch
+	Prefetch  int   // Pages fetched ahead of the consumer; 0 fetches one page at a time
+	Limit     int64 // Maximum number of items to yield; 0 means all
+}
+
+// Option configures an iterator.
+type Option func(*Options)
+
+// WithPageSize sets the number of items requested per page.
+func WithPageSize(n int64) Option {
+	return func(o *Options) { o.PageSize = n }
+}
+
+// WithStartPage starts the iteration at the given page.
+func WithStartPage(page int64) Option {
+	return func(o *Options) { o.StartPage = page }
+}
+
+// WithPrefetch sets how many pages are fetched concurrently ahead of the
+// consumer.
+func WithPrefetch(n int) Option {
+	return func(o *Options) { o.Prefetch = n }
+}
+
+// WithLimit stops the iteration after n items.
+func WithLimit(n int64) Option {
+	return func(o *Options) { o.Limit = n }
+}
+
+func newOptions(opts []Option) Options {
+	o := Options{PageSize: DefaultPageSize, StartPage: 1, Prefetch: defaultPrefetch}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.PageSize < 1 {
+		o.PageSize = DefaultPageSize
+	}
+	if o.StartPage < 1 {
+		o.StartPage = 1
+	}
+	o.Prefetch = max(o.Prefetch, 0)
+	return o
+}
+
+type page[T any] struct {
+	items []T
+	total int64
+	err   error
+}
+
+// Seq returns an iterator over the items of every page returned by fetch.
+// The iteration ends after the last page, which is known from the total
+// when reported and otherwise is the first page shorter than the page size.
+// An error is yielded once, with the zero value, and ends the iteration.
+func Seq[T any](ctx context.Context, fetch Fetch[T], opts ...Option) iter.Seq2[T, error] {
+	o := newOptions(opts)
+	return func(yield func(T, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		var (
+			next    = o.StartPage
+			last    = int64(-1) // Last page, -1 while unknown
+			pending []chan page[T]
+			yielded int64
+		)
+		more := func() bool {
+			return last < 0 || next <= last
+		}
+		start := func() {
+			ch := make(chan page[T], 1)
+			pending = append(pending, ch)
+			wg.Add(1)
+			go func(p int64) {
+				defer wg.Done()
+				items, total, err := fetch(ctx, p, o.PageSize)
+				ch <- page[T]{items: items, total: total, err: err}
+			}(next)
+			next++
+		}
+		fail := func(err error) {
+			var zero T
+			yield(zero, scerrors.From(err))
+		}
+
+		start()
+		for len(pending) > 0 {
+			var p page[T]
+			select {
+			case p = <-pending[0]:
+			case <-ctx.Done():
+				fail(ctx.Err())
+				return
+			}
+			pending = pending[1:]
+			if p.err != nil {
+				fail(p.err)
+				return
+			}
+			if last < 0 && p.total > 0 {
+				last = (p.total + o.PageSize - 1) / o.PageSize
+			}
+			full := int64(len(p.items)) >= o.PageSize
+			// Keep fetching ahead while the current page is handed out.
+			for full && len(pending) < o.Prefetch && more() {
+				start()
+			}
+			for _, item := range p.items {
+				if !yield(item, nil) {
+					return
+				}
+				if yielded++; o.Limit > 0 && yielded >= o.Limit {
+					return
+				}
+			}
+			if !full {
+				return
+			}
+			if err := ctx.Err(); err != nil {
+				fail(err)
+				return
+			}
+			if len(pending) == 0 && more() {
+				start()
+			}
+		}
+	}
+}
+
+// Collect gathers every item of seq, stopping at the first error.
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
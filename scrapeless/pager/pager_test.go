@@ -0,0 +1,150 @@
+package pager
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// source serves n integers and records the calls made to it.
+type source struct {
+	n        int64
+	noTotal  bool
+	delay    time.Duration
+	mu       sync.Mutex
+	pages    []int64
+	inFlight atomic.Int32
+	maxSeen  atomic.Int32
+}
+
+func (s *source) fetch(ctx context.Context, page, pageSize int64) ([]int64, int64, error) {
+	cur := s.inFlight.Add(1)
+	defer s.inFlight.Add(-1)
+	for {
+		seen := s.maxSeen.Load()
+		if cur <= seen || s.maxSeen.CompareAndSwap(seen, cur) {
+			break
+		}
+	}
+	s.mu.Lock()
+	s.pages = append(s.pages, page)
+	s.mu.Unlock()
+	if s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+	var items []int64
+	for i := (page - 1) * pageSize; i < min(page*pageSize, s.n); i++ {
+		items = append(items, i)
+	}
+	if s.noTotal {
+		return items, 0, nil
+	}
+	return items, s.n, nil
+}
+
+func TestSeqOrderAndEnd(t *testing.T) {
+	for _, noTotal := range []bool{false, true} {
+		src := &source{n: 23, noTotal: noTotal, delay: time.Millisecond}
+		items, err := Collect(Seq(context.Background(), src.fetch, WithPageSize(5), WithPrefetch(3)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 23 {
+			t.Fatalf("noTotal=%v: got %d items", noTotal, len(items))
+		}
+		for i, v := range items {
+			if v != int64(i) {
+				t.Fatalf("noTotal=%v: out of order at %d: %v", noTotal, i, items)
+			}
+		}
+		if !noTotal && len(src.pages) != 5 {
+			t.Fatalf("the total bounds the pages, fetched %v", src.pages)
+		}
+		if src.maxSeen.Load() > 3 {
+			t.Fatalf("prefetch exceeded: %d pages in flight", src.maxSeen.Load())
+		}
+	}
+}
+
+func TestSeqPrefetchesConcurrently(t *testing.T) {
+	src := &source{n: 100, delay: 20 * time.Millisecond}
+	if _, err := Collect(Seq(context.Background(), src.fetch, WithPageSize(10), WithPrefetch(4))); err != nil {
+		t.Fatal(err)
+	}
+	if src.maxSeen.Load() < 2 {
+		t.Fatal("pages were not fetched concurrently")
+	}
+
+	src = &source{n: 30}
+	if _, err := Collect(Seq(context.Background(), src.fetch, WithPageSize(10), WithPrefetch(0))); err != nil {
+		t.Fatal(err)
+	}
+	if src.maxSeen.Load() != 1 {
+		t.Fatalf("prefetch 0 should fetch one page at a time, saw %d", src.maxSeen.Load())
+	}
+}
+
+func TestSeqBreakStopsFetching(t *testing.T) {
+	src := &source{n: 1000, noTotal: true, delay: 5 * time.Millisecond}
+	var got int
+	for _, err := range Seq(context.Background(), src.fetch, WithPageSize(10), WithPrefetch(2)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got++; got == 15 {
+			break
+		}
+	}
+	if src.inFlight.Load() != 0 {
+		t.Fatal("fetches still running after break")
+	}
+	if len(src.pages) > 4 {
+		t.Fatalf("fetched too far ahead: %v", src.pages)
+	}
+
+	items, err := Collect(Seq(context.Background(), src.fetch, WithLimit(7)))
+	if err != nil || len(items) != 7 {
+		t.Fatalf("limit: %d items, %v", len(items), err)
+	}
+}
+
+func TestSeqContextAndErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	src := &source{n: 1000, delay: 10 * time.Millisecond}
+	var errs []error
+	var got int
+	for _, err := range Seq(ctx, src.fetch, WithPageSize(10)) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if got++; got == 10 {
+			cancel()
+		}
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], scerrors.ErrCanceled) || !errors.Is(errs[0], context.Canceled) {
+		t.Fatalf("want a single cancellation error, got %v", errs)
+	}
+
+	boom := scerrors.New(scerrors.KindUnavailable, "boom")
+	failing := func(ctx context.Context, page, pageSize int64) ([]int, int64, error) {
+		if page == 2 {
+			return nil, 0, boom
+		}
+		return make([]int, pageSize), 100, nil
+	}
+	items, err := Collect(Seq(context.Background(), failing, WithPageSize(10)))
+	if !errors.Is(err, boom) || len(items) != 10 {
+		t.Fatalf("got %d items, %v", len(items), err)
+	}
+}
@@ -0,0 +1,253 @@
+// Package poller waits for asynchronous Scrapeless tasks to finish.
+//
+// A poll repeatedly calls a check function until it reports a result, a
+// terminal error, or the context is done. Between calls it sleeps with
+// exponential backoff and jitter, never past the context deadline.
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	defaultInitialInterval = 500 * time.Millisecond
+	defaultMaxInterval     = 10 * time.Second
+	defaultMultiplier      = 1.5
+	defaultJitter          = 0.2
+	defaultMaxErrors       = 5
+)
+
+// ErrPending reports that a task has not finished yet. Check functions return
+// it (or an error wrapping it, see Pending) to ask for another round.
+var ErrPending = errors.New("task pending")
+
+// Status values reported through Progress.
+const (
+	StatusPending  = "pending"
+	StatusRetrying = "retrying"
+)
+
+// Progress describes the state of a poll after each unsuccessful check.
+type Progress struct {
+	Attempt   int           // Number of checks made so far
+	Elapsed   time.Duration // Time since the poll started
+	NextDelay time.Duration // Time until the next check
+	Status    string        // StatusPending, StatusRetrying or the status reported by Pending
+	Err       error         // The transient error behind StatusRetrying
+}
+
+// Options controls how a poll backs off between checks.
+type Options struct {
+	InitialInterval time.Duration  // Delay before the second check
+	MaxInterval     time.Duration  // Upper bound of the delay between checks
+	Multiplier      float64        // Growth factor applied to the delay after each check
+	Jitter          float64        // Random spread applied to each delay, 0.2 means ±20%
+	Timeout         time.Duration  // Overall limit, in addition to the context deadline; 0 means none
+	MaxErrors       int            // Consecutive transient errors tolerated before giving up; negative means unlimited
+	OnProgress      func(Progress) // Called after every check that did not finish the poll
+}
+
+// Option configures a poll.
+type Option func(*Options)
+
+// WithInitialInterval sets the delay before the second check.
+func WithInitialInterval(d time.Duration) Option {
+	return func(o *Options) { o.InitialInterval = d }
+}
+
+// WithMaxInterval caps the delay between two checks.
+func WithMaxInterval(d time.Duration) Option {
+	return func(o *Options) { o.MaxInterval = d }
+}
+
+// WithMultiplier sets how fast the delay grows.
+func WithMultiplier(m float64) Option {
+	return func(o *Options) { o.Multiplier = m }
+}
+
+// WithJitter sets the random spread applied to each delay, between 0 and 1.
+func WithJitter(j float64) Option {
+	return func(o *Options) { o.Jitter = j }
+}
+
+// WithTimeout bounds the whole poll.
+func WithTimeout(d time.Duration) Option {
+	return func(o *Options) { o.Timeout = d }
+}
+
+// WithMaxErrors sets how many consecutive transient errors are tolerated.
+func WithMaxErrors(n int) Option {
+	return func(o *Options) { o.MaxErrors = n }
+}
+
+// WithProgress registers a callback invoked after every unfinished check.
+func WithProgress(fn func(Progress)) Option {
+	return func(o *Options) { o.OnProgress = fn }
+}
+
+// WithOptions replaces all settings at once.
+func WithOptions(opts Options) Option {
+	return func(o *Options) { *o = opts }
+}
+
+func newOptions(opts []Option) Options {
+	o := Options{
+		InitialInterval: defaultInitialInterval,
+		MaxInterval:     defaultMaxInterval,
+		Multiplier:      defaultMultiplier,
+		Jitter:          defaultJitter,
+		MaxErrors:       defaultMaxErrors,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.InitialInterval <= 0 {
+		o.InitialInterval = defaultInitialInterval
+	}
+	if o.MaxInterval < o.InitialInterval {
+		o.MaxInterval = o.InitialInterval
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1
+	}
+	o.Jitter = min(max(o.Jitter, 0), 1)
+	return o
+}
+
+type pendingError struct {
+	status string
+}
+
+func (e *pendingError) Error() string {
+	return fmt.Sprintf("task pending: %s", e.status)
+}
+
+func (e *pendingError) Is(target error) bool {
+	return target == ErrPending
+}
+
+// Pending returns an error matching ErrPending that carries the status
+// reported by the service, so it shows up in Progress.Status.
+func Pending(status string) error {
+	return &pendingError{status: status}
+}
+
+type terminalError struct {
+	err error
+}
+
+func (e *terminalError) Error() string {
+	return e.err.Error()
+}
+
+func (e *terminalError) Unwrap() error {
+	return e.err
+}
+
+// Terminal marks err as final: the poll stops and returns it unchanged.
+func Terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &terminalError{err: err}
+}
+
+// IsTerminal reports whether err was marked with Terminal.
+func IsTerminal(err error) bool {
+	var t *terminalError
+	return errors.As(err, &t)
+}
+
+// Poll calls check until it returns a nil error, a terminal error, or the
+// context ends. A check returning ErrPending is polled again after the next
+// backoff delay; any other error is treated as transient and retried until
+// MaxErrors consecutive failures have been seen.
+func Poll[T any](ctx context.Context, check func(ctx context.Context) (T, error), opts ...Option) (T, error) {
+	o := newOptions(opts)
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	var zero T
+	start := time.Now()
+	delay := o.InitialInterval
+	errCount := 0
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return zero, stopError(err, lastErr)
+		}
+		result, err := check(ctx)
+		if err == nil {
+			return result, nil
+		}
+		if IsTerminal(err) {
+			var t *terminalError
+			errors.As(err, &t)
+			return zero, t.err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return zero, stopError(ctxErr, err)
+		}
+
+		progress := Progress{Attempt: attempt, Status: StatusPending}
+		var pending *pendingError
+		switch {
+		case errors.As(err, &pending):
+			progress.Status = pending.status
+			errCount = 0
+		case errors.Is(err, ErrPending):
+			errCount = 0
+		default:
+			errCount++
+			if o.MaxErrors >= 0 && errCount > o.MaxErrors {
+				return zero, fmt.Errorf("poll gave up after %d consecutive errors: %w", errCount, err)
+			}
+			progress.Status = StatusRetrying
+			progress.Err = err
+		}
+		lastErr = err
+
+		wait := jitter(delay, o.Jitter)
+		if deadline, ok := ctx.Deadline(); ok {
+			wait = min(wait, max(time.Until(deadline), 0))
+		}
+		progress.Elapsed = time.Since(start)
+		progress.NextDelay = wait
+		if o.OnProgress != nil {
+			o.OnProgress(progress)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, stopError(ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+		delay = min(time.Duration(float64(delay)*o.Multiplier), o.MaxInterval)
+	}
+}
+
+func jitter(d time.Duration, factor float64) time.Duration {
+	if factor == 0 {
+		return d
+	}
+	spread := float64(d) * factor
+	return time.Duration(float64(d) - spread + rand.Float64()*2*spread)
+}
+
+// stopError wraps the context error so callers can still match it with
+// errors.Is, while keeping the last state seen for diagnostics.
+func stopError(ctxErr, last error) error {
+	if last == nil {
+		return ctxErr
+	}
+	return fmt.Errorf("%w (last state: %v)", ctxErr, last)
+}
@@ -0,0 +1,105 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func fast() Option {
+	return WithOptions(Options{InitialInterval: time.Millisecond, MaxInterval: 4 * time.Millisecond, Multiplier: 2, MaxErrors: 2})
+}
+
+func TestPollUntilDone(t *testing.T) {
+	var progress []Progress
+	calls := 0
+	got, err := Poll(context.Background(), func(ctx context.Context) (string, error) {
+		calls++
+		switch calls {
+		case 1:
+			return "", Pending("queued")
+		case 2:
+			return "", errors.New("connection reset")
+		case 3:
+			return "", ErrPending
+		}
+		return "done", nil
+	}, fast(), WithProgress(func(p Progress) { progress = append(progress, p) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "done" || calls != 4 {
+		t.Fatalf("got %q after %d calls", got, calls)
+	}
+	want := []string{"queued", StatusRetrying, StatusPending}
+	if len(progress) != len(want) {
+		t.Fatalf("got %d progress reports, want %d", len(progress), len(want))
+	}
+	for i, p := range progress {
+		if p.Status != want[i] || p.Attempt != i+1 {
+			t.Fatalf("progress %d: %+v", i, p)
+		}
+	}
+	if progress[1].Err == nil {
+		t.Fatal("retrying progress should carry the error")
+	}
+}
+
+func TestPollTerminal(t *testing.T) {
+	cause := errors.New("task failed")
+	calls := 0
+	_, err := Poll(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		return 0, Terminal(cause)
+	}, fast())
+	if err != cause || calls != 1 {
+		t.Fatalf("got %v after %d calls", err, calls)
+	}
+}
+
+func TestPollMaxErrors(t *testing.T) {
+	calls := 0
+	_, err := Poll(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		return 0, errors.New("boom")
+	}, fast())
+	if err == nil || calls != 3 {
+		t.Fatalf("got %v after %d calls", err, calls)
+	}
+}
+
+func TestPollDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := Poll(ctx, func(ctx context.Context) (int, error) {
+		return 0, ErrPending
+	}, WithInitialInterval(time.Hour))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("poll slept past the context deadline")
+	}
+}
+
+func TestPollCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := Poll(ctx, func(ctx context.Context) (int, error) {
+		cancel()
+		return 0, ErrPending
+	}, fast())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want canceled, got %v", err)
+	}
+}
+
+func TestJitterBounds(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(100*time.Millisecond, 0.2)
+		if d < 80*time.Millisecond || d > 120*time.Millisecond {
+			t.Fatalf("jitter out of bounds: %v", d)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package rag
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Defaults of ChunkOptions.
+const (
+	DefaultMaxTokens = 256
+	DefaultOverlap   = 32
+)
+
+// ChunkOptions sizes the chunks of a document. Tokens are runs of
+// non-space characters, which is close to the words of the text.
+type ChunkOptions struct {
+	MaxTokens int // Tokens of a chunk at most, DefaultMaxTokens when < 1
+	Overlap   int // Tokens repeated from the end of the previous chunk of a section, DefaultOverlap when 0, none when < 0
+}
+
+func (o ChunkOptions) normalize() ChunkOptions {
+	if o.MaxTokens < 1 {
+		o.MaxTokens = DefaultMaxTokens
+	}
+	switch {
+	case o.Overlap == 0:
+		o.Overlap = DefaultOverlap
+	case o.Overlap < 0:
+		o.Overlap = 0
+	}
+	// Every chunk moves forward.
+	o.Overlap = min(o.Overlap, o.MaxTokens-1)
+	return o
+}
+
+// Chunk is a piece of a markdown document.
+type Chunk struct {
+	Index   int    // Position of the chunk in the document, from 0
+	Heading string // Headings above the chunk, like "Install > Linux"
+	Text    string // The markdown of the chunk, its first heading included
+}
+
+var (
+	headingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	fenceRe   = regexp.MustCompile("^ {0,3}(```|~~~)")
+	tokenRe   = regexp.MustCompile(`\S+`)
+)
+
+// section is the text under a heading, up to the next one.
+type section struct {
+	headings []string
+	text     string
+}
+
+// ChunkMarkdown splits a markdown document into chunks. The document is cut
+// at its ATX headings ("# Title") outside of code blocks, then the sections
+// longer than MaxTokens are cut into windows of MaxTokens tokens, each one
+// starting with the last Overlap tokens of the previous one. Chunks keep the
+// formatting of the markdown.
+func ChunkMarkdown(markdown string, opts ChunkOptions) []Chunk {
+	opts = opts.normalize()
+	var chunks []Chunk
+	for _, sec := range splitSections(markdown) {
+		heading := strings.Join(sec.headings, " > ")
+		for _, text := range windows(sec.text, opts) {
+			chunks = append(chunks, Chunk{Index: len(chunks), Heading: heading, Text: text})
+		}
+	}
+	return chunks
+}
+
+func splitSections(markdown string) []section {
+	var (
+		sections []section
+		headings []string
+		levels   []int
+		lines    []string
+		fence    string
+	)
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(lines, "\n"))
+		if text != "" {
+			sections = append(sections, section{headings: append([]string(nil), headings...), text: text})
+		}
+		lines = lines[:0]
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		if m := fenceRe.FindStringSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = m[1]
+			case m[1]:
+				fence = ""
+			}
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil && fence == "" {
+			flush()
+			level := len(m[1])
+			for len(levels) > 0 && levels[len(levels)-1] >= level {
+				levels = levels[:len(levels)-1]
+				headings = headings[:len(headings)-1]
+			}
+			levels = append(levels, level)
+			headings = append(headings, strings.TrimSpace(m[2]))
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return sections
+}
+
+// windows cuts text into windows of tokens overlapping by opts.Overlap.
+func windows(text string, opts ChunkOptions) []string {
+	spans := tokenRe.FindAllStringIndex(text, -1)
+	if len(spans) <= opts.MaxTokens {
+		return []string{text}
+	}
+	var out []string
+	for start := 0; ; start += opts.MaxTokens - opts.Overlap {
+		end := min(start+opts.MaxTokens, len(spans))
+		out = append(out, text[spans[start][0]:spans[end-1][1]])
+		if end == len(spans) {
+			return out
+		}
+	}
+}
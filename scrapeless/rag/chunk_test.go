@@ -0,0 +1,60 @@
+package rag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunkMarkdownSections(t *testing.T) {
+	markdown := strings.Join([]string{
+		"Intro text.",
+		"# Install",
+		"Get the SDK.",
+		"## Linux",
+		"Run the script:",
+		"```sh",
+		"# not a heading",
+		"make install",
+		"```",
+		"## macOS ##",
+		"Use brew.",
+		"# Usage",
+		"Call it.",
+	}, "\n")
+	var got [][2]string
+	for _, chunk := range ChunkMarkdown(markdown, ChunkOptions{}) {
+		got = append(got, [2]string{chunk.Heading, chunk.Text})
+	}
+	want := [][2]string{
+		{"", "Intro text."},
+		{"Install", "# Install\nGet the SDK."},
+		{"Install > Linux", "## Linux\nRun the script:\n```sh\n# not a heading\nmake install\n```"},
+		{"Install > macOS", "## macOS ##\nUse brew."},
+		{"Usage", "# Usage\nCall it."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("chunks\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestChunkMarkdownWindows(t *testing.T) {
+	chunks := ChunkMarkdown("# Numbers\none two three four five six seven", ChunkOptions{MaxTokens: 4, Overlap: 1})
+	var texts []string
+	for i, chunk := range chunks {
+		if chunk.Index != i || chunk.Heading != "Numbers" {
+			t.Fatalf("chunk %d: %+v", i, chunk)
+		}
+		texts = append(texts, chunk.Text)
+	}
+	// The heading counts as two tokens.
+	want := []string{"# Numbers\none two", "two three four five", "five six seven"}
+	if !reflect.DeepEqual(texts, want) {
+		t.Fatalf("windows %q, want %q", texts, want)
+	}
+
+	chunks = ChunkMarkdown("one two three four five", ChunkOptions{MaxTokens: 2, Overlap: -1})
+	if len(chunks) != 3 || chunks[1].Text != "three four" {
+		t.Fatalf("windows without overlap: %+v", chunks)
+	}
+}
@@ -0,0 +1,250 @@
+// Package rag feeds crawled pages to a vector collection for retrieval
+// augmented generation.
+//
+// An Ingester chunks the markdown of pages, embeds the chunks and upserts
+// them with IDs derived from the page URL, so that ingesting a page again
+// replaces its chunks:
+//
+//	ing := rag.NewIngester(storage.Vector, collId, rag.WithEmbedder(embedding.NewHashing(256)))
+//	result, err := ing.Ingest(ctx, page.Data)
+package rag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/embedding"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/crawl"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+)
+
+// Metadata keys of the ingested chunks.
+const (
+	MetaSourceURL = "sourceURL"
+	MetaTitle     = "title"
+	MetaHeading   = "heading"
+	MetaChunk     = "chunk"  // Index of the chunk in its page
+	MetaChunks    = "chunks" // Number of chunks of the page
+)
+
+// probeSize is the number of chunk IDs looked up at once when deleting the
+// chunks left from a previous ingestion.
+const probeSize = 64
+
+// Options configures an Ingester.
+type Options struct {
+	Chunk     ChunkOptions
+	Embedder  embedding.Embedder // Embeds the chunks, else the embedder of the Vector is used
+	BatchSize int                // Chunks upserted per request, 64 by default
+	Metadata  map[string]any     // Added to the metadata of every chunk
+}
+
+// Option configures an Ingester.
+type Option func(*Options)
+
+// WithChunking sets the size and overlap of the chunks.
+func WithChunking(opts ChunkOptions) Option {
+	return func(o *Options) { o.Chunk = opts }
+}
+
+// WithEmbedder embeds the chunks with e rather than with the embedder set on
+// the Vector.
+func WithEmbedder(e embedding.Embedder) Option {
+	return func(o *Options) { o.Embedder = e }
+}
+
+// WithBatchSize sets the number of chunks upserted per request.
+func WithBatchSize(n int) Option {
+	return func(o *Options) { o.BatchSize = n }
+}
+
+// WithMetadata adds entries to the metadata of every chunk.
+func WithMetadata(metadata map[string]any) Option {
+	return func(o *Options) {
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]any, len(metadata))
+		}
+		for key, value := range metadata {
+			o.Metadata[key] = value
+		}
+	}
+}
+
+// Ingester stores the chunks of crawled pages in a vector collection.
+type Ingester struct {
+	vector *storage.Vector
+	collId string
+	opts   Options
+}
+
+// NewIngester returns an Ingester writing to the collection.
+// Parameters:
+//
+//	vector: The vector storage.
+//	collId: The collection receiving the chunks, its dimension must be the one of the embedder.
+//	opts: Chunk sizes, embedder, batch size and extra metadata.
+func NewIngester(vector *storage.Vector, collId string, opts ...Option) *Ingester {
+	o := Options{BatchSize: 64}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.BatchSize < 1 {
+		o.BatchSize = 64
+	}
+	return &Ingester{vector: vector, collId: collId, opts: o}
+}
+
+// IngestResult counts the work of Ingest.
+type IngestResult struct {
+	Pages   int      `json:"pages"`   // Pages ingested
+	Chunks  int      `json:"chunks"`  // Chunks upserted
+	Deleted int      `json:"deleted"` // Chunks of previous ingestions deleted
+	Skipped []string `json:"skipped"` // URLs of the pages which failed to be scraped
+}
+
+// ChunkID returns the ID of the chunk of a page: a hash of the URL followed
+// by the index of the chunk, like "3f79bb7b435b0532-0007".
+func ChunkID(url string, index int) string {
+	sum := sha256.Sum256([]byte(url))
+	return fmt.Sprintf("%s-%04d", hex.EncodeToString(sum[:8]), index)
+}
+
+// Ingest chunks, embeds and upserts the pages, then deletes the chunks of
+// their previous ingestions which are past their new last chunk. Pages
+// whose scrape failed are skipped and keep their chunks. A failed page does
+// not stop the others; the failures are joined in the returned error.
+// Parameters:
+//
+//	ctx: The context for the requests.
+//	docs: The crawled pages, with markdown.
+func (ing *Ingester) Ingest(ctx context.Context, docs ...crawl.ScrapingCrawlDocument) (*IngestResult, error) {
+	result := &IngestResult{}
+	var errs []error
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		url := doc.Metadata.SourceURL
+		if url == "" {
+			url = doc.Metadata.OgURL
+		}
+		if url == "" {
+			errs = append(errs, scerrors.New(scerrors.KindInvalidArgument, "page has no source URL"))
+			continue
+		}
+		if doc.Metadata.Error != "" || doc.Metadata.StatusCode >= http.StatusBadRequest {
+			result.Skipped = append(result.Skipped, url)
+			continue
+		}
+		chunks, deleted, err := ing.ingestPage(ctx, url, &doc)
+		result.Chunks += chunks
+		result.Deleted += deleted
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ingest %s: %w", url, err))
+			continue
+		}
+		result.Pages++
+	}
+	return result, errors.Join(errs...)
+}
+
+func (ing *Ingester) ingestPage(ctx context.Context, url string, doc *crawl.ScrapingCrawlDocument) (int, int, error) {
+	title := doc.Metadata.Title
+	if title == "" {
+		title = doc.Metadata.OgTitle
+	}
+	chunks := ChunkMarkdown(doc.Markdown, ing.opts.Chunk)
+	docs := make([]*storage.Doc, len(chunks))
+	for i, chunk := range chunks {
+		metadata := make(map[string]any, len(ing.opts.Metadata)+5)
+		for key, value := range ing.opts.Metadata {
+			metadata[key] = value
+		}
+		metadata[MetaSourceURL] = url
+		metadata[MetaTitle] = title
+		metadata[MetaHeading] = chunk.Heading
+		metadata[MetaChunk] = chunk.Index
+		metadata[MetaChunks] = len(chunks)
+		docs[i] = &storage.Doc{ID: ChunkID(url, chunk.Index), Content: chunk.Text, Metadata: metadata}
+	}
+
+	upserted := 0
+	for start := 0; start < len(docs); start += ing.opts.BatchSize {
+		batch := docs[start:min(start+ing.opts.BatchSize, len(docs))]
+		if err := ing.embed(ctx, batch); err != nil {
+			return upserted, 0, err
+		}
+		resp, err := ing.vector.UpsertDocs(ctx, ing.collId, batch)
+		if err != nil {
+			return upserted, 0, err
+		}
+		for _, out := range resp.Output {
+			if out.Code != 0 {
+				return upserted, 0, scerrors.Newf(scerrors.KindInternal, "upsert chunk %s: %s", out.Id, out.Message)
+			}
+		}
+		upserted += len(batch)
+	}
+	deleted, err := ing.deleteFrom(ctx, url, len(chunks))
+	return upserted, deleted, err
+}
+
+func (ing *Ingester) embed(ctx context.Context, docs []*storage.Doc) error {
+	if ing.opts.Embedder == nil {
+		return nil
+	}
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Content
+	}
+	vectors, err := ing.opts.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return scerrors.From(err)
+	}
+	if len(vectors) != len(docs) {
+		return scerrors.Newf(scerrors.KindInternal, "embedder returned %d vectors for %d chunks", len(vectors), len(docs))
+	}
+	for i, doc := range docs {
+		doc.Vector = vectors[i]
+	}
+	return nil
+}
+
+// deleteFrom deletes the chunks of the page from index on. The chunks of a
+// page are numbered without gaps, so the lookup stops at the first missing
+// one.
+func (ing *Ingester) deleteFrom(ctx context.Context, url string, index int) (int, error) {
+	deleted := 0
+	for {
+		ids := make([]string, probeSize)
+		for i := range ids {
+			ids[i] = ChunkID(url, index+i)
+		}
+		found, err := ing.vector.QueryDocsByIds(ctx, ing.collId, ids)
+		if err != nil {
+			return deleted, err
+		}
+		var stale []string
+		for _, id := range ids {
+			if found[id] != nil {
+				stale = append(stale, id)
+			}
+		}
+		if len(stale) > 0 {
+			if _, err = ing.vector.DelDocs(ctx, ing.collId, stale); err != nil {
+				return deleted, err
+			}
+			deleted += len(stale)
+		}
+		if len(stale) < probeSize {
+			return deleted, nil
+		}
+		index += probeSize
+	}
+}
@@ -0,0 +1,112 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/scrapeless/embedding"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/crawl"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/services/storage"
+)
+
+func localCollection(t *testing.T, dimension int) (*storage.Vector, string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	s := storage.NewStorage("http")
+	t.Cleanup(func() { _ = s.Close() })
+	resp, err := s.Vector.CreateCollections(context.Background(), &storage.CreateCollectionRequest{Name: "pages", Dimension: dimension})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.Vector, resp.Coll.Id
+}
+
+func page(url, title, markdown string) crawl.ScrapingCrawlDocument {
+	doc := crawl.ScrapingCrawlDocument{Markdown: markdown}
+	doc.Metadata.SourceURL = url
+	doc.Metadata.Title = title
+	doc.Metadata.StatusCode = 200
+	return doc
+}
+
+func sections(n int) string {
+	var b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&b, "# Part %d\nText of part %d.\n", i, i)
+	}
+	return b.String()
+}
+
+func TestIngest(t *testing.T) {
+	vector, collId := localCollection(t, 64)
+	ctx := context.Background()
+	ing := NewIngester(vector, collId,
+		WithEmbedder(embedding.NewHashing(64)),
+		WithBatchSize(2),
+		WithMetadata(map[string]any{"site": "docs"}),
+	)
+
+	failed := page("https://example.com/gone", "", "Not found")
+	failed.Metadata.StatusCode = 404
+	result, err := ing.Ingest(ctx,
+		page("https://example.com/a", "Page A", sections(5)),
+		page("https://example.com/b", "Page B", "# Cake\nA chocolate cake recipe with dark chocolate."),
+		failed,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pages != 2 || result.Chunks != 6 || result.Deleted != 0 || len(result.Skipped) != 1 {
+		t.Fatalf("result %+v", result)
+	}
+
+	docs, err := vector.QueryDocs(ctx, collId, &storage.QueryVectorParam{Vector: mustEmbed(t, "chocolate cake"), Topk: 1, IncludeContent: true})
+	if err != nil || len(docs) != 1 {
+		t.Fatalf("query: %v, %v", docs, err)
+	}
+	got := docs[0]
+	if got.ID != ChunkID("https://example.com/b", 0) || got.Metadata[MetaTitle] != "Page B" ||
+		got.Metadata[MetaHeading] != "Cake" || got.Metadata["site"] != "docs" {
+		t.Fatalf("doc %+v", got)
+	}
+
+	// The page lost parts: its first chunks are replaced and the others deleted.
+	result, err = ing.Ingest(ctx, page("https://example.com/a", "Page A", sections(2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pages != 1 || result.Chunks != 2 || result.Deleted != 3 {
+		t.Fatalf("result %+v", result)
+	}
+	ids := make([]string, 5)
+	for i := range ids {
+		ids[i] = ChunkID("https://example.com/a", i)
+	}
+	stored, err := vector.QueryDocsByIds(ctx, collId, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 || stored[ids[0]] == nil || stored[ids[1]] == nil {
+		t.Fatalf("stored chunks %v", stored)
+	}
+}
+
+func TestIngestWithoutURL(t *testing.T) {
+	vector, collId := localCollection(t, 8)
+	result, err := NewIngester(vector, collId, WithEmbedder(embedding.NewHashing(8))).
+		Ingest(context.Background(), crawl.ScrapingCrawlDocument{Markdown: "orphan"}, page("https://example.com", "", "kept"))
+	if err == nil || result.Pages != 1 {
+		t.Fatalf("result %+v, err %v", result, err)
+	}
+}
+
+func mustEmbed(t *testing.T, text string) []float64 {
+	t.Helper()
+	vectors, err := embedding.NewHashing(64).Embed(context.Background(), []string{text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vectors[0]
+}
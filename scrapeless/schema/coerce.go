@@ -0,0 +1,173 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Coerce repairs the common defects of scraped values so that they may
+// satisfy the schema: numbers and booleans written as strings, numbers and
+// booleans where a string is expected, a single value where an array is
+// expected, missing or null properties that have a default and properties
+// forbidden by additionalProperties. v is a decoded JSON value, see
+// Normalize, and is not modified. Coerce reports whether the result differs
+// from v; the result still has to be validated.
+func (s *Schema) Coerce(v any) (any, bool) {
+	return s.coerce(v)
+}
+
+func (s *Schema) coerce(v any) (any, bool) {
+	s = s.target()
+	if s.never {
+		return v, false
+	}
+	changed := false
+	if v == nil && s.Default != nil && !hasType(s.Type, nil) {
+		if d, err := Normalize(s.Default); err == nil {
+			v, changed = d, true
+		}
+	}
+	if len(s.Type) > 0 && !hasType(s.Type, v) {
+		for _, t := range s.Type {
+			if c, ok := s.convert(t, v); ok {
+				v, changed = c, true
+				break
+			}
+		}
+	}
+
+	switch value := v.(type) {
+	case map[string]any:
+		if c, ok := s.coerceObject(value); ok {
+			v, changed = c, true
+		}
+	case []any:
+		if s.Items != nil {
+			var out []any
+			for i, item := range value {
+				c, ok := s.Items.coerce(item)
+				if !ok {
+					continue
+				}
+				if out == nil {
+					out = append([]any(nil), value...)
+				}
+				out[i] = c
+			}
+			if out != nil {
+				v, changed = out, true
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		if c, ok := sub.coerce(v); ok {
+			v, changed = c, true
+		}
+	}
+	for _, list := range [][]*Schema{s.AnyOf, s.OneOf} {
+		if len(list) == 0 || s.matching(list, v) > 0 {
+			continue
+		}
+		// Take the first alternative the value can be repaired for.
+		for _, sub := range list {
+			if c, ok := sub.coerce(v); ok && len(sub.Validate(c)) == 0 {
+				v, changed = c, true
+				break
+			}
+		}
+	}
+	return v, changed
+}
+
+func (s *Schema) coerceObject(v map[string]any) (map[string]any, bool) {
+	var out map[string]any
+	set := func(key string, value any, keep bool) {
+		if out == nil {
+			out = make(map[string]any, len(v))
+			for k, e := range v {
+				out[k] = e
+			}
+		}
+		if keep {
+			out[key] = value
+		} else {
+			delete(out, key)
+		}
+	}
+
+	for name, sub := range s.Properties {
+		if _, ok := v[name]; ok {
+			continue
+		}
+		if def := sub.target().Default; def != nil {
+			if d, err := Normalize(def); err == nil {
+				set(name, d, true)
+			}
+		}
+	}
+	for key, value := range v {
+		sub, ok := s.Properties[key]
+		if !ok {
+			sub = s.AdditionalProperties
+		}
+		switch {
+		case sub == nil:
+		case sub.target().never:
+			set(key, nil, false)
+		default:
+			if c, ok := sub.coerce(value); ok {
+				set(key, c, true)
+			}
+		}
+	}
+	return out, out != nil
+}
+
+// convert turns v into a value of type t, when it has an obvious reading.
+func (s *Schema) convert(t string, v any) (any, bool) {
+	switch t {
+	case "number", "integer":
+		var f float64
+		switch v := v.(type) {
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, false
+			}
+			f = n
+		case float64:
+			f = v
+		default:
+			return nil, false
+		}
+		if t == "integer" && typeName(f) != "integer" {
+			return nil, false
+		}
+		return f, true
+	case "boolean":
+		switch v := v.(type) {
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			return b, err == nil
+		case float64:
+			return v != 0, v == 0 || v == 1
+		}
+	case "string":
+		switch v := v.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(v), true
+		}
+	case "array":
+		if v == nil {
+			return nil, false
+		}
+		if s.Items != nil {
+			v, _ = s.Items.coerce(v)
+		}
+		return []any{v}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,207 @@
+// Package schema validates JSON values against a JSON Schema.
+//
+// The supported keywords are those used to describe scraped records: type,
+// properties, required, additionalProperties, items, enum, minimum,
+// maximum, exclusiveMinimum, exclusiveMaximum, minLength, maxLength,
+// pattern, format, minItems, maxItems, allOf, anyOf, oneOf, not, default and
+// local $ref to $defs or definitions. Other keywords are ignored.
+//
+// A schema is parsed from JSON with Parse or derived from a Go struct with
+// For:
+//
+//	s, err := schema.For(Product{})
+//	if errs := s.Validate(item); len(errs) > 0 {
+//		...
+//	}
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// maxRefHops bounds the chains of references, which may loop.
+const maxRefHops = 32
+
+// Schema is a JSON Schema. Build it with Parse or For, or compile a literal
+// with Compile before use.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Defs        map[string]*Schema `json:"$defs,omitempty"`
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Default     any                `json:"default,omitempty"`
+
+	Type                 Types              `json:"type,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Format               string             `json:"format,omitempty"`
+
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	Not   *Schema   `json:"not,omitempty"`
+
+	root    *Schema
+	pattern *regexp.Regexp
+	never   bool // The false schema
+}
+
+// Types is the "type" keyword, a single type or a list of them.
+type Types []string
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Types{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON also accepts the boolean schemas true and false.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	switch strings.TrimSpace(string(data)) {
+	case "true":
+		*s = Schema{}
+		return nil
+	case "false":
+		*s = Schema{never: true}
+		return nil
+	}
+	type plain Schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	if s.never {
+		return []byte("false"), nil
+	}
+	type plain Schema
+	return json.Marshal((*plain)(s))
+}
+
+// Parse reads and compiles a JSON Schema.
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "parse schema: %v", err)
+	}
+	if err := s.Compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Compile checks the patterns and references of s and prepares it for
+// validation. Parse and For call it.
+func (s *Schema) Compile() error {
+	return s.compile(s, "")
+}
+
+func (s *Schema) compile(root *Schema, path string) error {
+	s.root = root
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return scerrors.Newf(scerrors.KindInvalidArgument, "schema%s: invalid pattern: %v", path, err)
+		}
+		s.pattern = re
+	}
+	if s.Ref != "" {
+		if _, err := root.resolve(s.Ref); err != nil {
+			return scerrors.Newf(scerrors.KindInvalidArgument, "schema%s: %v", path, err)
+		}
+	}
+	for _, t := range s.Type {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return scerrors.Newf(scerrors.KindInvalidArgument, "schema%s: unknown type %q", path, t)
+		}
+	}
+	children := map[string]*Schema{"/additionalProperties": s.AdditionalProperties, "/items": s.Items, "/not": s.Not}
+	for name, sub := range s.Properties {
+		children["/properties/"+name] = sub
+	}
+	for name, sub := range s.Defs {
+		children["/$defs/"+name] = sub
+	}
+	for name, sub := range s.Definitions {
+		children["/definitions/"+name] = sub
+	}
+	for keyword, list := range map[string][]*Schema{"allOf": s.AllOf, "anyOf": s.AnyOf, "oneOf": s.OneOf} {
+		for i, sub := range list {
+			children[fmt.Sprintf("/%s/%d", keyword, i)] = sub
+		}
+	}
+	for name, sub := range children {
+		if sub == nil {
+			continue
+		}
+		if err := sub.compile(root, path+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve returns the schema a local reference points to.
+func (s *Schema) resolve(ref string) (*Schema, error) {
+	switch {
+	case ref == "#":
+		return s, nil
+	case strings.HasPrefix(ref, "#/$defs/"):
+		if def := s.Defs[strings.TrimPrefix(ref, "#/$defs/")]; def != nil {
+			return def, nil
+		}
+	case strings.HasPrefix(ref, "#/definitions/"):
+		if def := s.Definitions[strings.TrimPrefix(ref, "#/definitions/")]; def != nil {
+			return def, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported reference %q", ref)
+	}
+	return nil, fmt.Errorf("unresolved reference %q", ref)
+}
+
+// target follows the reference of s, if any.
+func (s *Schema) target() *Schema {
+	for hops := 0; s.Ref != "" && s.root != nil && hops < maxRefHops; hops++ {
+		next, err := s.root.resolve(s.Ref)
+		if err != nil || next == s {
+			return s
+		}
+		s = next
+	}
+	return s
+}
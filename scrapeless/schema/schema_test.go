@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustParse(t *testing.T, src string) *Schema {
+	t.Helper()
+	s, err := Parse([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func decode(t *testing.T, src string) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal([]byte(src), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func paths(errs []ValidationError) []string {
+	var out []string
+	for _, e := range errs {
+		out = append(out, e.Path)
+	}
+	return out
+}
+
+func TestValidate(t *testing.T) {
+	s := mustParse(t, `{
+		"type": "object",
+		"required": ["title", "price"],
+		"additionalProperties": false,
+		"properties": {
+			"title": {"type": "string", "minLength": 1, "pattern": "^[a-z ]*$"},
+			"price": {"type": "number", "exclusiveMinimum": 0},
+			"stock": {"type": ["integer", "null"], "minimum": 0},
+			"state": {"enum": ["new", "used"]},
+			"url":   {"type": "string", "format": "uri"},
+			"tags":  {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+			"seller": {"$ref": "#/$defs/seller"}
+		},
+		"$defs": {
+			"seller": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}
+		}
+	}`)
+
+	valid := decode(t, `{"title": "desk lamp", "price": 12.5, "stock": null, "state": "new", "url": "https://example.com", "tags": ["a"], "seller": {"name": "x"}}`)
+	if errs := s.Validate(valid); len(errs) != 0 {
+		t.Fatalf("valid item: %v", errs)
+	}
+
+	cases := []struct {
+		item string
+		path string
+	}{
+		{`{"price": 1}`, "/title"},
+		{`{"title": "", "price": 1}`, "/title"},
+		{`{"title": "Lamp", "price": 1}`, "/title"},
+		{`{"title": "lamp", "price": 0}`, "/price"},
+		{`{"title": "lamp", "price": "12"}`, "/price"},
+		{`{"title": "lamp", "price": 1, "stock": 1.5}`, "/stock"},
+		{`{"title": "lamp", "price": 1, "state": "broken"}`, "/state"},
+		{`{"title": "lamp", "price": 1, "url": "example"}`, "/url"},
+		{`{"title": "lamp", "price": 1, "tags": ["a", 2]}`, "/tags/1"},
+		{`{"title": "lamp", "price": 1, "tags": ["a", "b", "c"]}`, "/tags"},
+		{`{"title": "lamp", "price": 1, "seller": {}}`, "/seller/name"},
+		{`{"title": "lamp", "price": 1, "a/b": 1}`, "/a~1b"},
+	}
+	for _, c := range cases {
+		errs := s.Validate(decode(t, c.item))
+		if got := paths(errs); len(got) != 1 || got[0] != c.path {
+			t.Errorf("%s: errors %v", c.item, errs)
+		}
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	s := mustParse(t, `{
+		"oneOf": [{"type": "integer"}, {"type": "string", "maxLength": 3}],
+		"not": {"enum": [7]}
+	}`)
+	for src, ok := range map[string]bool{`3`: true, `"abc"`: true, `"abcd"`: false, `7`: false, `1.5`: false} {
+		if got := len(s.Validate(decode(t, src))) == 0; got != ok {
+			t.Errorf("%s: valid %v", src, got)
+		}
+	}
+	if len(mustParse(t, `{"anyOf": [false, {"type": "null"}]}`).Validate(nil)) != 0 {
+		t.Error("null rejected by anyOf")
+	}
+	if _, err := Parse([]byte(`{"$ref": "#/$defs/missing"}`)); err == nil {
+		t.Error("unresolved reference accepted")
+	}
+	if _, err := Parse([]byte(`{"pattern": "("}`)); err == nil {
+		t.Error("invalid pattern accepted")
+	}
+}
+
+type seller struct {
+	Name string `json:"name"`
+}
+
+type category struct {
+	Name     string      `json:"name"`
+	Children []*category `json:"children,omitempty"`
+}
+
+type product struct {
+	Title     string            `json:"title" jsonschema:"minLength=1"`
+	Price     float64           `json:"price" jsonschema:"minimum=0"`
+	Stock     uint              `json:"stock,omitempty"`
+	State     string            `json:"state,omitempty" jsonschema:"enum=new|used,default=new"`
+	URL       string            `json:"url" jsonschema:"format=uri"`
+	Tags      []string          `json:"tags,omitempty" jsonschema:"maxItems=2"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+	Seller    *seller           `json:"seller"`
+	Category  category          `json:"category,omitempty"`
+	Seen      time.Time         `json:"seen"`
+	Image     []byte            `json:"image,omitempty"`
+	Related   []product         `json:"related,omitempty"`
+	internal  int
+	Ignored   string `json:"-"`
+	Available bool   `json:"available" jsonschema:"optional"`
+}
+
+func TestFor(t *testing.T) {
+	s, err := For(&product{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"title", "price", "url", "seen"}; !reflect.DeepEqual(s.Required, want) {
+		t.Fatalf("required %v", s.Required)
+	}
+	if _, ok := s.Properties["Ignored"]; ok {
+		t.Fatal("json:\"-\" field in the schema")
+	}
+	if s.Properties["related"].Items.Ref != "#" || s.Defs["category"] == nil {
+		t.Fatalf("recursion: related %+v, defs %v", s.Properties["related"].Items, s.Defs)
+	}
+
+	item := product{Title: "lamp", URL: "https://example.com", Seen: time.Now(), Seller: &seller{Name: "x"},
+		Category: category{Name: "home", Children: []*category{{Name: "lights"}}}, Related: []product{{Title: "bulb", URL: "https://example.com/b"}}}
+	v, err := Normalize(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := s.Validate(v); len(errs) != 0 {
+		t.Fatalf("item of the struct: %v", errs)
+	}
+
+	item.Title, item.Price, item.State = "", -1, "broken"
+	v, _ = Normalize(item)
+	if got := paths(s.Validate(v)); !reflect.DeepEqual(got, []string{"/price", "/state", "/title"}) {
+		t.Fatalf("errors at %v", got)
+	}
+
+	if _, err := For(struct {
+		F int `jsonschema:"minimum=x"`
+	}{}); err == nil || !strings.Contains(err.Error(), "F") {
+		t.Fatalf("bad tag: %v", err)
+	}
+	if _, err := For(3); err == nil {
+		t.Fatal("non-struct accepted")
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	s := mustParse(t, `{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"price": {"type": "number"},
+			"stock": {"type": "integer"},
+			"sold":  {"type": "boolean"},
+			"sku":   {"type": "string"},
+			"tags":  {"type": "array", "items": {"type": "string"}},
+			"state": {"type": "string", "default": "new"},
+			"rank":  {"type": "integer", "default": 0}
+		}
+	}`)
+	in := decode(t, `{"price": " 12.5", "stock": "3", "sold": "true", "sku": 1234, "tags": "one", "rank": null, "extra": 1}`)
+	out, changed := s.Coerce(in)
+	want := decode(t, `{"price": 12.5, "stock": 3, "sold": true, "sku": "1234", "tags": ["one"], "state": "new", "rank": 0}`)
+	if !changed || !reflect.DeepEqual(out, want) {
+		t.Fatalf("coerced %v", out)
+	}
+	if errs := s.Validate(out); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	if in.(map[string]any)["price"] != " 12.5" {
+		t.Fatal("the input was modified")
+	}
+
+	// Values without an obvious reading are left alone.
+	out, _ = s.Coerce(decode(t, `{"stock": "3.5", "sold": "maybe"}`))
+	if m := out.(map[string]any); m["stock"] != "3.5" || m["sold"] != "maybe" {
+		t.Fatalf("coerced %v", out)
+	}
+	if _, changed := s.Coerce(want); changed {
+		t.Fatal("valid value changed")
+	}
+}
@@ -0,0 +1,278 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+var (
+	timeType      = reflect.TypeFor[time.Time]()
+	marshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// For derives the schema of the JSON encoding of v, a struct or a pointer to
+// one. Properties are named after their json tag. A property is required
+// unless its tag has omitempty or its field is a pointer. The jsonschema tag
+// adds constraints, separated by commas:
+//
+//	type Product struct {
+//		Title string   `json:"title" jsonschema:"minLength=1"`
+//		Price float64  `json:"price" jsonschema:"minimum=0"`
+//		State string   `json:"state,omitempty" jsonschema:"enum=new|used"`
+//		URL   string   `json:"url" jsonschema:"format=uri"`
+//		Tags  []string `json:"tags,omitempty" jsonschema:"maxItems=10"`
+//	}
+//
+// The keys are required, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, minLength, maxLength, pattern, format, enum (values
+// separated by '|'), minItems, maxItems, default and description; a pattern
+// cannot hold a comma. Types implementing json.Marshaler are left
+// unconstrained, except time.Time which is a date-time string.
+func For(v any) (*Schema, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "schema.For needs a struct, got %T", v)
+	}
+	g := &generator{root: t, building: map[reflect.Type]bool{}, recursive: map[reflect.Type]bool{}, defs: map[string]*Schema{}}
+	s, err := g.schemaOf(t)
+	if err != nil {
+		return nil, err
+	}
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	if err = s.Compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// generator builds the schema of a type. Structs met again while being
+// built are recursive: the root is referenced as "#" and the others are
+// moved to $defs.
+type generator struct {
+	root      reflect.Type
+	building  map[reflect.Type]bool
+	recursive map[reflect.Type]bool
+	defs      map[string]*Schema
+}
+
+func (g *generator) schemaOf(t reflect.Type) (*Schema, error) {
+	if t == timeType {
+		return &Schema{Type: Types{"string"}, Format: "date-time"}, nil
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) {
+		return &Schema{}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: Types{"integer"}}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: Types{"integer"}, Minimum: &zero}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}, nil
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Pointer:
+		s, err := g.schemaOf(t.Elem())
+		switch {
+		case err != nil || len(s.Type) == 0 && s.Ref == "":
+			return s, err
+		case s.Ref != "":
+			return &Schema{AnyOf: []*Schema{s, {Type: Types{"null"}}}}, nil
+		}
+		s.Type = append(s.Type, "null")
+		return s, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Encoded as base64 by encoding/json.
+			return &Schema{Type: Types{"string"}}, nil
+		}
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		types := Types{"array"}
+		if t.Kind() == reflect.Slice {
+			types = append(types, "null")
+		}
+		return &Schema{Type: types, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, scerrors.Newf(scerrors.KindInvalidArgument, "schema.For: map key of %s is not a string", t)
+		}
+		values, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return g.structSchema(t)
+	}
+	return nil, scerrors.Newf(scerrors.KindInvalidArgument, "schema.For: unsupported type %s", t)
+}
+
+func (g *generator) structSchema(t reflect.Type) (*Schema, error) {
+	if g.building[t] {
+		if t == g.root {
+			return &Schema{Ref: "#"}, nil
+		}
+		g.recursive[t] = true
+		return &Schema{Ref: "#/$defs/" + t.Name()}, nil
+	}
+	g.building[t] = true
+	defer delete(g.building, t)
+
+	s := &Schema{Type: Types{"object"}, Properties: map[string]*Schema{}}
+	if err := g.addFields(s, t); err != nil {
+		return nil, err
+	}
+	if g.recursive[t] {
+		g.defs[t.Name()] = s
+		return &Schema{Ref: "#/$defs/" + t.Name()}, nil
+	}
+	return s, nil
+}
+
+func (g *generator) addFields(s *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := g.addFields(s, ft); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		prop, err := g.schemaOf(field.Type)
+		if err != nil {
+			return err
+		}
+		required := !strings.Contains(","+opts+",", ",omitempty,") && field.Type.Kind() != reflect.Pointer
+		if tag := field.Tag.Get("jsonschema"); tag != "" {
+			if prop, required, err = applyTag(prop, tag, required); err != nil {
+				return scerrors.Newf(scerrors.KindInvalidArgument, "schema.For: field %s.%s: %v", t.Name(), field.Name, err)
+			}
+		}
+		s.Properties[name] = prop
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// applyTag adds the constraints of a jsonschema tag to s.
+func applyTag(s *Schema, tag string, required bool) (*Schema, bool, error) {
+	if s.Ref != "" {
+		// Constraints cannot sit next to a reference.
+		s = &Schema{AllOf: []*Schema{s}}
+	}
+	for _, part := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		var err error
+		switch key {
+		case "":
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "minimum":
+			s.Minimum, err = parseFloat(value)
+		case "maximum":
+			s.Maximum, err = parseFloat(value)
+		case "exclusiveMinimum":
+			s.ExclusiveMinimum, err = parseFloat(value)
+		case "exclusiveMaximum":
+			s.ExclusiveMaximum, err = parseFloat(value)
+		case "minLength":
+			s.MinLength, err = parseInt(value)
+		case "maxLength":
+			s.MaxLength, err = parseInt(value)
+		case "minItems":
+			s.MinItems, err = parseInt(value)
+		case "maxItems":
+			s.MaxItems, err = parseInt(value)
+		case "pattern":
+			s.Pattern = value
+		case "format":
+			s.Format = value
+		case "description":
+			s.Description = value
+		case "enum":
+			for _, e := range strings.Split(value, "|") {
+				s.Enum = append(s.Enum, tagValue(s, e))
+			}
+		case "default":
+			s.Default = tagValue(s, value)
+		default:
+			err = scerrors.Newf(scerrors.KindInvalidArgument, "unknown jsonschema key %q", key)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return s, required, nil
+}
+
+// tagValue parses a value written in a tag according to the type of s.
+func tagValue(s *Schema, value string) any {
+	for _, t := range s.Type {
+		switch t {
+		case "integer", "number":
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				return f
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(value); err == nil {
+				return b
+			}
+		}
+	}
+	return value
+}
+
+func parseFloat(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parseInt(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
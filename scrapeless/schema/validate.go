@@ -0,0 +1,240 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError is a value that does not satisfy the schema.
+type ValidationError struct {
+	Path    string `json:"path"` // JSON Pointer of the value, "" for the root
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Normalize converts v to the values encoding/json decodes into: maps,
+// slices, float64, string, bool and nil. Validate expects such values.
+func Normalize(v any) (any, error) {
+	switch v.(type) {
+	case nil, bool, string, float64:
+		return v, nil
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(buf, &out)
+	return out, err
+}
+
+// Validate returns the errors of v, nil when it is valid. v is a decoded
+// JSON value, see Normalize.
+func (s *Schema) Validate(v any) []ValidationError {
+	var errs []ValidationError
+	s.validate(v, "", &errs)
+	return errs
+}
+
+func (s *Schema) validate(v any, path string, errs *[]ValidationError) {
+	s = s.target()
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if s.never {
+		fail("no value is allowed")
+		return
+	}
+
+	if len(s.Type) > 0 && !hasType(s.Type, v) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), typeName(v))
+		return
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, v) {
+		fail("value %s is not one of %s", encode(v), encode(s.Enum))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		s.validateObject(v, path, errs)
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("expected at least %d items, got %d", *s.MinItems, len(v))
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("expected at most %d items, got %d", *s.MaxItems, len(v))
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, path+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("expected at least %d characters, got %d", *s.MinLength, n)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("expected at most %d characters, got %d", *s.MaxLength, n)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("%q does not match %s", v, s.Pattern)
+		}
+		if s.Format != "" && !checkFormat(s.Format, v) {
+			fail("%q is not a valid %s", v, s.Format)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("%v is less than %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("%v is greater than %v", v, *s.Maximum)
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			fail("%v is not greater than %v", v, *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil && v >= *s.ExclusiveMaximum {
+			fail("%v is not less than %v", v, *s.ExclusiveMaximum)
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(v, path, errs)
+	}
+	if len(s.AnyOf) > 0 && s.matching(s.AnyOf, v) == 0 {
+		fail("value matches none of anyOf")
+	}
+	if len(s.OneOf) > 0 {
+		if n := s.matching(s.OneOf, v); n != 1 {
+			fail("value matches %d schemas of oneOf instead of 1", n)
+		}
+	}
+	if s.Not != nil && len(s.Not.Validate(v)) == 0 {
+		fail("value matches the schema of not")
+	}
+}
+
+func (s *Schema) validateObject(v map[string]any, path string, errs *[]ValidationError) {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			*errs = append(*errs, ValidationError{Path: path + "/" + escape(name), Message: "required property is missing"})
+		}
+	}
+	keys := make([]string, 0, len(v))
+	for key := range v {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		sub, ok := s.Properties[key]
+		if !ok {
+			sub = s.AdditionalProperties
+		}
+		if sub != nil {
+			sub.validate(v[key], path+"/"+escape(key), errs)
+		}
+	}
+}
+
+func (s *Schema) matching(list []*Schema, v any) int {
+	n := 0
+	for _, sub := range list {
+		if len(sub.Validate(v)) == 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func hasType(types Types, v any) bool {
+	for _, t := range types {
+		if typeName(v) == t || t == "number" && typeName(v) == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if reflect.DeepEqual(e, v) {
+			return true
+		}
+		// Enums of schemas built from Go values may hold ints.
+		if n, err := Normalize(e); err == nil && reflect.DeepEqual(n, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkFormat(format, v string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", v)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	case "uri", "url":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	}
+	// Unknown formats are annotations only.
+	return true
+}
+
+// escape escapes a property name for a JSON Pointer.
+func escape(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func encode(v any) string {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(buf)
+}
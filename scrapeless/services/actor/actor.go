@@ -2,27 +2,29 @@ package actor
 
 import (
 	"context"
-	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/actor"
-	actor_http "github.com/scrapeless-ai/sdk-go/internal/remote/actor/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/actor/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
-func NewActor(serverMode string) *ActorService {
+// NewActor creates the actor service. cfg overrides the credentials and
+// endpoints read from the environment.
+func NewActor(serverMode string, cfg ...request.ClientConfig) *ActorService {
 	log.Info("Actor init")
-	actor.NewClient(serverMode, env.Env.ScrapelessActorUrl)
-	return &ActorService{}
+	c := request.ClientConfigOf(cfg...)
+	return &ActorService{client: actor.NewClient(serverMode, c.ActorUrl, c)}
 }
 
 type ActorService struct {
+	client actor.Actor
 }
 
 // Run starts an actor run with the provided context and request data.
 // Returns the run ID or an error.
 func (ah *ActorService) Run(ctx context.Context, req IRunActorData) (string, error) {
-	runId, err := actor_http.Default().Run(ctx, &models.IRunActorData{
+	runId, err := ah.client.Run(ctx, &models.IRunActorData{
 		ActorId: req.ActorId,
 		Input:   req.Input,
 		RunOptions: models.RunOptions{
@@ -32,16 +34,16 @@ func (ah *ActorService) Run(ctx context.Context, req IRunActorData) (string, err
 			Version: req.RunOptions.Version,
 		},
 	})
-	return runId, code.Format(err)
+	return runId, scerrors.From(err)
 }
 
 // GetRunInfo retrieves information about a specific actor run by run ID.
 // Returns a pointer to RunInfo or an error.
 func (ah *ActorService) GetRunInfo(ctx context.Context, runId string) (*RunInfo, error) {
-	runInfo, err := actor_http.Default().GetRunInfo(ctx, runId)
+	runInfo, err := ah.client.GetRunInfo(ctx, runId)
 	if err != nil {
 		log.Errorf("get runInfo err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	info := &RunInfo{
 		ActorID:     runInfo.ActorID,
@@ -72,24 +74,24 @@ func (ah *ActorService) GetRunInfo(ctx context.Context, runId string) (*RunInfo,
 // AbortRun aborts a running actor by actor ID and run ID.
 // Returns true if successful and an error otherwise.
 func (ah *ActorService) AbortRun(ctx context.Context, actorId, runId string) (bool, error) {
-	success, err := actor_http.Default().AbortRun(ctx, actorId, runId)
-	return success, code.Format(err)
+	success, err := ah.client.AbortRun(ctx, actorId, runId)
+	return success, scerrors.From(err)
 }
 
 // Build triggers a build process for the specified actor and version.
 // Returns the build ID or an error.
 func (ah *ActorService) Build(ctx context.Context, actorId string, version string) (string, error) {
-	buildId, err := actor_http.Default().Build(ctx, actorId, version)
-	return buildId, code.Format(err)
+	buildId, err := ah.client.Build(ctx, actorId, version)
+	return buildId, scerrors.From(err)
 }
 
 // GetBuildStatus retrieves the status of a build by actor ID and build ID.
 // Returns a pointer to BuildInfo or an error.
 func (ah *ActorService) GetBuildStatus(ctx context.Context, actorId string, buildId string) (*BuildInfo, error) {
-	success, err := actor_http.Default().GetBuildStatus(ctx, actorId, buildId)
+	success, err := ah.client.GetBuildStatus(ctx, actorId, buildId)
 	if err != nil {
 		log.Errorf("get build status err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	buildInfo := &BuildInfo{
 		ActorID:    success.ActorID,
@@ -103,27 +105,27 @@ func (ah *ActorService) GetBuildStatus(ctx context.Context, actorId string, buil
 		TeamID:     success.TeamID,
 		Version:    success.Version,
 	}
-	return buildInfo, code.Format(err)
+	return buildInfo, scerrors.From(err)
 }
 
 // AbortBuild aborts an ongoing build process by actor ID and build ID.
 // Returns true if successful and an error otherwise.
 func (ah *ActorService) AbortBuild(ctx context.Context, actorId string, buildId string) (bool, error) {
-	success, err := actor_http.Default().AbortBuild(ctx, actorId, buildId)
-	return success, code.Format(err)
+	success, err := ah.client.AbortBuild(ctx, actorId, buildId)
+	return success, scerrors.From(err)
 }
 
 // GetRunList retrieves a list of actor runs with pagination.
 // Returns a slice of Payload containing run data or an error.
 func (ah *ActorService) GetRunList(ctx context.Context, paginationParams *IPaginationParams) ([]Payload, error) {
-	runList, err := actor_http.Default().GetRunList(ctx, &models.IPaginationParams{
+	runList, err := ah.client.GetRunList(ctx, &models.IPaginationParams{
 		Page:     paginationParams.Page,
 		PageSize: paginationParams.PageSize,
 		Desc:     paginationParams.Desc,
 	})
 	if err != nil {
 		log.Errorf("get run list err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	var runListArray []Payload
 	for _, run := range runList {
@@ -159,5 +161,5 @@ func (ah *ActorService) GetRunList(ctx context.Context, paginationParams *IPagin
 }
 
 func (ah *ActorService) Close() error {
-	return actor_http.Default().Close()
+	return ah.client.Close()
 }
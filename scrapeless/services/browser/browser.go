@@ -3,32 +3,37 @@ package browser
 import (
 	"context"
 	"fmt"
-	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/browser"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/browser/http"
 	remote_brwoser "github.com/scrapeless-ai/sdk-go/internal/remote/browser/models"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/extension"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"net/url"
 	"strconv"
 	"strings"
 )
 
 type Browser struct {
+	client    browser.Browser
+	extension extension.Extension
+	cfg       request.ClientConfig
 }
 
-func NewBrowser(serverMode string) *Browser {
+// NewBrowser creates the browser service. cfg overrides the credentials and
+// endpoints read from the environment.
+func NewBrowser(serverMode string, cfg ...request.ClientConfig) *Browser {
 	log.Info("browser init")
-	browser.NewClient(serverMode, env.Env.ScrapelessBrowserUrl)
-	extension.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &Browser{}
+	c := request.ClientConfigOf(cfg...)
+	return &Browser{
+		client:    browser.NewClient(serverMode, c.BrowserUrl, c),
+		extension: extension.NewClient(serverMode, c.BaseApiUrl, c),
+		cfg:       c,
+	}
 }
 func (b *Browser) Create(ctx context.Context, req Actor) (*CreateResp, error) {
-	create, err := browser.ClientInterface.ScrapingBrowserCreate(ctx, &remote_brwoser.CreateBrowserRequest{
-		ApiKey: env.GetActorEnv().ApiKey,
+	create, err := b.client.ScrapingBrowserCreate(ctx, &remote_brwoser.CreateBrowserRequest{
+		ApiKey: b.cfg.ApiKey,
 		Input: map[string]string{
 			"session_ttl":     req.Input.SessionTtl,
 			"profile_persist": strconv.FormatBool(req.ProfilePersist),
@@ -45,7 +50,7 @@ func (b *Browser) Create(ctx context.Context, req Actor) (*CreateResp, error) {
 	})
 	if err != nil {
 		log.Errorf("scraping browser create err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	if create != nil {
 		return &CreateResp{
@@ -57,13 +62,13 @@ func (b *Browser) Create(ctx context.Context, req Actor) (*CreateResp, error) {
 }
 
 func (b *Browser) CreateOnce(ctx context.Context, req ActorOnce) (*CreateResp, error) {
-	u, err := url.Parse(env.Env.ScrapelessBrowserUrl)
+	u, err := url.Parse(b.cfg.BrowserUrl)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "parse url error: %s", err.Error())
+		return nil, scerrors.Newf(scerrors.KindInvalidArgument, "parse browser url: %v", err)
 	}
 	devtoolsUrl := fmt.Sprintf("wss://%s/browser", u.Host)
 	value := &url.Values{}
-	value.Set("token", env.GetActorEnv().ApiKey)
+	value.Set("token", b.cfg.ApiKey)
 	value.Set("session_ttl", req.Input.SessionTtl)
 	value.Set("proxy_country", strings.ToUpper(req.ProxyCountry))
 	return &CreateResp{
@@ -73,9 +78,9 @@ func (b *Browser) CreateOnce(ctx context.Context, req ActorOnce) (*CreateResp, e
 
 // Upload upload extension
 func (b *Browser) Upload(ctx context.Context, filePath, pluginName string) (uploadExtension *UploadExtensionResponse, err error) {
-	upload, err := extension.ClientInterface.Upload(ctx, filePath, pluginName)
+	upload, err := b.extension.Upload(ctx, filePath, pluginName)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	return &UploadExtensionResponse{
 		ExtensionID: upload.ExtensionID,
@@ -87,14 +92,15 @@ func (b *Browser) Upload(ctx context.Context, filePath, pluginName string) (uplo
 
 // Update update extension
 func (b *Browser) Update(ctx context.Context, extensionId, filePath, pluginName string) (success bool, err error) {
-	return extension.ClientInterface.Update(ctx, extensionId, filePath, pluginName)
+	success, err = b.extension.Update(ctx, extensionId, filePath, pluginName)
+	return success, scerrors.From(err)
 }
 
 // Get get extension detail by extensionId
 func (b *Browser) Get(ctx context.Context, extensionId string) (extensionDetail *ExtensionDetail, err error) {
-	detail, err := extension.ClientInterface.Get(ctx, extensionId)
+	detail, err := b.extension.Get(ctx, extensionId)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	return &ExtensionDetail{
 		ExtensionID:  detail.ExtensionID,
@@ -110,9 +116,9 @@ func (b *Browser) Get(ctx context.Context, extensionId string) (extensionDetail
 
 // List list extension
 func (b *Browser) List(ctx context.Context) (extensionList []ExtensionListItem, err error) {
-	list, err := extension.ClientInterface.List(ctx)
+	list, err := b.extension.List(ctx)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	for _, item := range list {
 		extensionList = append(extensionList, ExtensionListItem{
@@ -128,9 +134,13 @@ func (b *Browser) List(ctx context.Context) (extensionList []ExtensionListItem,
 
 // Delete delete extension by extensionId
 func (b *Browser) Delete(ctx context.Context, extensionId string) (success bool, err error) {
-	return extension.ClientInterface.Delete(ctx, extensionId)
+	success, err = b.extension.Delete(ctx, extensionId)
+	return success, scerrors.From(err)
 }
 
 func (b *Browser) Close() error {
-	return http.Default().Close()
+	if err := b.extension.Close(); err != nil {
+		return err
+	}
+	return b.client.Close()
 }
@@ -3,25 +3,28 @@ package captcha
 import (
 	"context"
 	"encoding/json"
-	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/captcha/http"
 	gateway_captcha "github.com/scrapeless-ai/sdk-go/internal/remote/captcha/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 	"github.com/tidwall/gjson"
 )
 
 type Captcha struct {
+	client captcha.Captcha
+	cfg    request.ClientConfig
 }
 
-func NewCaptcha(serverMode string) *Captcha {
+// NewCaptcha creates the captcha service. cfg overrides the credentials and
+// endpoints read from the environment.
+func NewCaptcha(serverMode string, cfg ...request.ClientConfig) *Captcha {
 	log.Info("captcha init")
-	captcha.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	if http.Default() == nil {
-		http.Init(env.Env.ScrapelessBaseApiUrl)
+	c := request.ClientConfigOf(cfg...)
+	return &Captcha{
+		client: captcha.NewClient(serverMode, c.BaseApiUrl, c),
+		cfg:    c,
 	}
-	return &Captcha{}
 }
 
 // Solver solves the captcha task by submitting it to the captcha solving service
@@ -39,8 +42,8 @@ func (c *Captcha) Solver(ctx context.Context, req *CaptchaSolverReq) (*CaptchaSo
 	_ = json.Unmarshal(input, &inputMap)
 
 	// Submit the captcha solving task to the remote service with provided parameters
-	response, err := captcha.ClientInterface.CaptchaSolverSolverTask(ctx, &gateway_captcha.CreateTaskRequest{
-		ApiKey: env.GetActorEnv().ApiKey,
+	response, err := c.client.CaptchaSolverSolverTask(ctx, &gateway_captcha.CreateTaskRequest{
+		ApiKey: c.cfg.ApiKey,
 		Actor:  req.Actor,
 		Input:  inputMap,
 		Proxy: &gateway_captcha.ProxyParams{
@@ -55,7 +58,7 @@ func (c *Captcha) Solver(ctx context.Context, req *CaptchaSolverReq) (*CaptchaSo
 	})
 	if err != nil {
 		log.Errorf("captcha solver err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	// Marshal the API response into JSON format and extract the 'token' field from the result
 	marshal, _ := json.Marshal(response)
@@ -78,8 +81,8 @@ func (c *Captcha) Create(ctx context.Context, req *CaptchaSolverReq) (string, er
 	_ = json.Unmarshal(input, &inputMap)
 
 	// Submit captcha solving task to remote service with provided configuration
-	taskId, err := captcha.ClientInterface.CaptchaSolverCreateTask(ctx, &gateway_captcha.CreateTaskRequest{
-		ApiKey: env.GetActorEnv().ApiKey,
+	taskId, err := c.client.CaptchaSolverCreateTask(ctx, &gateway_captcha.CreateTaskRequest{
+		ApiKey: c.cfg.ApiKey,
 		Actor:  req.Actor,
 		Input:  inputMap,
 		Proxy: &gateway_captcha.ProxyParams{
@@ -95,7 +98,7 @@ func (c *Captcha) Create(ctx context.Context, req *CaptchaSolverReq) (string, er
 	if err != nil {
 		// Log error and return formatted error response
 		log.Errorf("captcha creat err:%v", err)
-		return "", code.Format(err)
+		return "", scerrors.From(err)
 	}
 	return taskId, nil
 }
@@ -107,8 +110,8 @@ func (c *Captcha) Create(ctx context.Context, req *CaptchaSolverReq) (string, er
 //	ctx: context object for controlling the request lifecycle and timeouts
 //	req: captcha solving request parameters containing the task ID
 func (c *Captcha) ResultGet(ctx context.Context, req *CaptchaSolverReq) (*CaptchaSolverResp, error) {
-	response, err := captcha.ClientInterface.CaptchaSolverGetTaskResult(ctx, &gateway_captcha.GetTaskResultRequest{
-		ApiKey: env.GetActorEnv().ApiKey,
+	response, err := c.client.CaptchaSolverGetTaskResult(ctx, &gateway_captcha.GetTaskResultRequest{
+		ApiKey: c.cfg.ApiKey,
 		TaskId: req.TaskId,
 	})
 	if err != nil {
@@ -123,5 +126,5 @@ func (c *Captcha) ResultGet(ctx context.Context, req *CaptchaSolverReq) (*Captch
 }
 
 func (c *Captcha) Close() error {
-	return nil
+	return c.client.Close()
 }
@@ -3,47 +3,61 @@ package crawl
 import (
 	"context"
 	"errors"
-	"fmt"
-	"time"
+	"iter"
 
-	"github.com/scrapeless-ai/sdk-go/env"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/crawl"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/crawl/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/poller"
 )
 
-type Crawl struct{}
+type Crawl struct {
+	client crawl.Crawl
+}
 
-func New() *Crawl {
+// New creates the crawl service. cfg overrides the credentials and endpoints
+// read from the environment.
+func New(cfg ...request.ClientConfig) *Crawl {
 	log.Info("Internal Crawl init")
-	crawl.NewClient("http", env.Env.ScrapelessCrawlApiUrl)
-	return &Crawl{}
+	c := request.ClientConfigOf(cfg...)
+	return &Crawl{client: crawl.NewClient("http", c.CrawlUrl, c)}
 }
 
-func (c *Crawl) ScrapeUrl(ctx context.Context, url string, crawlScrapeOptions ScrapeOptions) (scrapeStatusResponse *ScrapeStatusResponse, err error) {
+// ScrapeUrl scrapes a single url and waits for the job to finish. The status is
+// polled with exponential backoff until the job completes, fails or ctx is
+// done; pollOpts tune the backoff and register a progress callback.
+func (c *Crawl) ScrapeUrl(ctx context.Context, url string, crawlScrapeOptions ScrapeOptions, pollOpts ...poller.Option) (scrapeStatusResponse *ScrapeStatusResponse, err error) {
 	id, err := c.AsyncScrapeUrl(ctx, url, crawlScrapeOptions)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
-	for {
-		scrapeStatusResponse, err = c.CheckScrapeStatus(ctx, id)
+	resp, err := poller.Poll(ctx, func(ctx context.Context) (*ScrapeStatusResponse, error) {
+		resp, err := c.CheckScrapeStatus(ctx, id)
 		if err != nil {
 			return nil, err
 		}
-		switch scrapeStatusResponse.Status {
-		case StatusCompleted:
-			return scrapeStatusResponse, nil
-		case StatusActive, StatusPaused, StatusPending, StatusQueued, StatusWaiting, StatusScraping:
-			log.Info("Scraping status: ", scrapeStatusResponse.Status)
-			time.Sleep(time.Millisecond * 400)
-		default:
-			return nil, errors.New(fmt.Sprintf("Crawl job failed or was stopped. Status: %s", scrapeStatusResponse.Status))
-		}
+		return resp, jobState(resp.Status)
+	}, pollOpts...)
+	return resp, scerrors.From(err)
+}
+
+// jobState maps a job status to the poller's notion of done, pending or failed.
+func jobState(status Status) error {
+	switch status {
+	case StatusCompleted:
+		return nil
+	case StatusActive, StatusPaused, StatusPending, StatusQueued, StatusWaiting, StatusScraping:
+		log.Info("Scraping status: ", status)
+		return poller.Pending(string(status))
+	default:
+		return poller.Terminal(scerrors.Newf(scerrors.KindUnknown, "crawl job failed or was stopped. Status: %s", status))
 	}
 }
 
 func (c *Crawl) AsyncScrapeUrl(ctx context.Context, url string, crawlScrapeOptions ScrapeOptions) (id string, err error) {
-	id, err = crawl.ClientInterface.ScrapeUrl(ctx, &models.ScrapeOptions{
+	id, err = c.client.ScrapeUrl(ctx, &models.ScrapeOptions{
 		Url:             url,
 		Formats:         crawlScrapeOptions.Formats,
 		Headers:         crawlScrapeOptions.Headers,
@@ -64,9 +78,9 @@ func (c *Crawl) AsyncScrapeUrl(ctx context.Context, url string, crawlScrapeOptio
 	return
 }
 func (c *Crawl) CheckScrapeStatus(ctx context.Context, id string) (scrapeStatusResponse *ScrapeStatusResponse, err error) {
-	response, err := crawl.ClientInterface.CheckScrapeStatus(ctx, id)
+	response, err := c.client.CheckScrapeStatus(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	return &ScrapeStatusResponse{
 		Status: Status(response.Status),
@@ -120,7 +134,7 @@ func (c *Crawl) internalScrapingCrawlDocumentFormat(in *models.ScrapingCrawlDocu
 	}
 }
 func (c *Crawl) BatchScrapeUrls(ctx context.Context, urls []string, params ScrapeParams) (scrapeResponse *ScrapeResponse, err error) {
-	response, err := crawl.ClientInterface.BatchScrapeUrls(ctx, &models.ScrapeOptionsMultiple{
+	response, err := c.client.BatchScrapeUrls(ctx, &models.ScrapeOptionsMultiple{
 		Url:             urls,
 		Formats:         params.Formats,
 		Headers:         params.Headers,
@@ -134,7 +148,7 @@ func (c *Crawl) BatchScrapeUrls(ctx context.Context, urls []string, params Scrap
 		},
 	})
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	return &ScrapeResponse{
 		ID:          response.ID,
@@ -143,9 +157,9 @@ func (c *Crawl) BatchScrapeUrls(ctx context.Context, urls []string, params Scrap
 }
 
 func (c *Crawl) CheckBatchScrapeStatus(ctx context.Context, id string) (scrapeStatusResponseMultiple *ScrapeStatusResponseMultiple, err error) {
-	response, err := crawl.ClientInterface.CheckBatchScrapeStatus(ctx, id)
+	response, err := c.client.CheckBatchScrapeStatus(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	scrapeStatusResponseMultiple = c.internalScrapeStatusResponseMultipleFormat(response)
 	return
@@ -164,7 +178,7 @@ func (c *Crawl) internalScrapeStatusResponseMultipleFormat(in *models.ScrapeStat
 }
 
 func (c *Crawl) AsyncCrawlUrl(ctx context.Context, url string, params CrawlParams) (id string, err error) {
-	crawlUrl, err := crawl.ClientInterface.CrawlUrl(ctx, &models.CrawlParams{
+	crawlUrl, err := c.client.CrawlUrl(ctx, &models.CrawlParams{
 		Url:                    url,
 		IncludePaths:           params.IncludePaths,
 		ExcludePaths:           params.ExcludePaths,
@@ -197,52 +211,141 @@ func (c *Crawl) AsyncCrawlUrl(ctx context.Context, url string, params CrawlParam
 		},
 	})
 	if err != nil {
-		return "", err
+		return "", scerrors.From(err)
 	}
 	return crawlUrl, nil
 }
 
-func (c *Crawl) CrawlUrl(ctx context.Context, url string, params CrawlParams) (crawlStatusResponse *CrawlStatusResponse, err error) {
+// CrawlUrl starts a crawl job and waits until it completes, polling its status
+// with backoff. The poll stops early on failure, cancellation or when ctx ends.
+// The returned response holds the documents of every page of the result.
+func (c *Crawl) CrawlUrl(ctx context.Context, url string, params CrawlParams, pollOpts ...poller.Option) (crawlStatusResponse *CrawlStatusResponse, err error) {
 	id, err := c.AsyncCrawlUrl(ctx, url, params)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
-	for {
-		crawlStatusResponse, err = c.CheckCrawlStatus(ctx, id)
+	resp, err := poller.Poll(ctx, func(ctx context.Context) (*CrawlStatusResponse, error) {
+		resp, err := c.CheckCrawlStatus(ctx, id)
 		if err != nil {
 			return nil, err
 		}
-		switch crawlStatusResponse.Status {
-		case StatusCompleted:
-			return crawlStatusResponse, nil
-		case StatusActive, StatusPaused, StatusPending, StatusQueued, StatusWaiting, StatusScraping:
-			log.Info("Scraping status: ", crawlStatusResponse.Status)
-			time.Sleep(time.Millisecond * 400)
-		default:
-			return nil, errors.New(fmt.Sprintf("Crawl job failed or was stopped. Status: %s", crawlStatusResponse.Status))
+		return resp, jobState(resp.Status)
+	}, pollOpts...)
+	if err != nil {
+		return nil, scerrors.From(err)
+	}
+	for resp.Next != "" {
+		page, err := c.CheckCrawlStatusNext(ctx, resp.Next)
+		if err != nil {
+			return nil, err
 		}
+		resp.Data = append(resp.Data, page.Data...)
+		resp.Next = page.Next
 	}
-
+	return resp, nil
 }
+
+// CheckCrawlStatus returns the status of a crawl job with the first page of
+// the documents crawled so far. Next is set when more pages are available.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	id: The crawl job ID.
 func (c *Crawl) CheckCrawlStatus(ctx context.Context, id string) (crawlStatusResponse *CrawlStatusResponse, err error) {
-	response, err := crawl.ClientInterface.CheckCrawlStatus(ctx, id)
+	response, err := c.client.CheckCrawlStatus(ctx, id)
+	if err != nil {
+		return nil, scerrors.From(err)
+	}
+	return c.internalCrawlStatusResponseFormat(response), nil
+}
+
+// CheckCrawlStatusNext returns the page of a crawl status that a previous
+// response pointed to with Next.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	next: The Next field of the previous page.
+func (c *Crawl) CheckCrawlStatusNext(ctx context.Context, next string) (crawlStatusResponse *CrawlStatusResponse, err error) {
+	response, err := c.client.CheckCrawlStatusNext(ctx, next)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
+	return c.internalCrawlStatusResponseFormat(response), nil
+}
+
+func (c *Crawl) internalCrawlStatusResponseFormat(in *models.CrawlStatusResponse) *CrawlStatusResponse {
 	var scrapingCrawlDocuments []ScrapingCrawlDocument
-	for _, datum := range response.Data {
+	for _, datum := range in.Data {
 		format := c.internalScrapingCrawlDocumentFormat(&datum)
 		scrapingCrawlDocuments = append(scrapingCrawlDocuments, *format)
 	}
 	return &CrawlStatusResponse{
-		Status: Status(response.Status),
-		Data:   scrapingCrawlDocuments,
-	}, nil
+		Status:    Status(in.Status),
+		Completed: in.Completed,
+		Total:     in.Total,
+		Next:      in.Next,
+		Data:      scrapingCrawlDocuments,
+	}
 }
-func (c *Crawl) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *CrawlErrorsResponse, err error) {
-	response, err := crawl.ClientInterface.CheckCrawlErrors(ctx, id)
+
+// errStopIteration ends the poll of IterCrawl when the caller breaks out.
+var errStopIteration = errors.New("iteration stopped")
+
+// IterCrawl iterates over the documents of a crawl job as they complete. It
+// polls the job status with backoff, follows the Next pages and yields each
+// document once, until the job completes. A failed job, a persistent request
+// error or the end of ctx is yielded once as the error and ends the iteration.
+// Parameters:
+//
+//	ctx: The context for the requests.
+//	id: The crawl job ID, as returned by AsyncCrawlUrl.
+//	pollOpts: Tune the backoff between two status checks.
+func (c *Crawl) IterCrawl(ctx context.Context, id string, pollOpts ...poller.Option) iter.Seq2[ScrapingCrawlDocument, error] {
+	return func(yield func(ScrapingCrawlDocument, error) bool) {
+		var (
+			next   string // Page being read, empty for the first one
+			offset int    // Documents of that page already yielded
+		)
+		_, err := poller.Poll(ctx, func(ctx context.Context) (*CrawlStatusResponse, error) {
+			for {
+				var resp *CrawlStatusResponse
+				var err error
+				if next == "" {
+					resp, err = c.CheckCrawlStatus(ctx, id)
+				} else {
+					resp, err = c.CheckCrawlStatusNext(ctx, next)
+				}
+				if err != nil {
+					return nil, err
+				}
+				for _, doc := range resp.Data[min(offset, len(resp.Data)):] {
+					if !yield(doc, nil) {
+						return nil, poller.Terminal(errStopIteration)
+					}
+					offset++
+				}
+				if resp.Next == "" {
+					return resp, jobState(resp.Status)
+				}
+				next, offset = resp.Next, 0
+			}
+		}, pollOpts...)
+		if err != nil && !errors.Is(err, errStopIteration) {
+			yield(ScrapingCrawlDocument{}, scerrors.From(err))
+		}
+	}
+}
+
+// CrawlErrors returns the pages a crawl job failed to scrape and the URLs
+// blocked by robots.txt.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	id: The crawl job ID.
+func (c *Crawl) CrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *CrawlErrorsResponse, err error) {
+	response, err := c.client.CheckCrawlErrors(ctx, id)
 	if err != nil {
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	var robotsBlocked []string
 	var crawlErrorDetail []CrawlErrorDetail
@@ -254,22 +357,33 @@ func (c *Crawl) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsRes
 			Error:     detail.Error,
 		})
 	}
-	for _, rb := range response.RobotsBlocked {
-		robotsBlocked = append(robotsBlocked, rb)
-	}
+	robotsBlocked = append(robotsBlocked, response.RobotsBlocked...)
 	return &CrawlErrorsResponse{
 		Errors:        crawlErrorDetail,
 		RobotsBlocked: robotsBlocked,
 	}, nil
 }
+
+// CheckCrawlErrors returns the errors of a crawl job.
+//
+// Deprecated: use CrawlErrors.
+func (c *Crawl) CheckCrawlErrors(ctx context.Context, id string) (crawlErrorsResponse *CrawlErrorsResponse, err error) {
+	return c.CrawlErrors(ctx, id)
+}
+
+// CancelCrawl stops a running crawl job.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	id: The crawl job ID.
 func (c *Crawl) CancelCrawl(ctx context.Context, id string) (success bool, err error) {
-	_, err = crawl.ClientInterface.CancelCrawl(ctx, id)
+	_, err = c.client.CancelCrawl(ctx, id)
 	if err != nil {
-		return false, err
+		return false, scerrors.From(err)
 	}
 	return true, nil
 }
 
 func (c *Crawl) Close() error {
-	return nil
+	return c.client.Close()
 }
@@ -0,0 +1,101 @@
+package crawl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/poller"
+)
+
+// crawlServer serves a crawl job whose documents arrive over three status
+// checks, the last one spreading them over two pages.
+func crawlServer(t *testing.T) (*Crawl, *atomic.Int32) {
+	t.Helper()
+	var checks atomic.Int32
+	doc := func(url string) map[string]any {
+		return map[string]any{"markdown": url, "metadata": map[string]any{"sourceURL": url}}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/crawler/crawl":
+			body = map[string]any{"success": true, "id": "job"}
+		case r.URL.Path == "/api/v1/crawler/crawl/job/errors":
+			body = map[string]any{
+				"errors":        []any{map[string]any{"id": "1", "url": "https://example.com/x", "error": "timeout"}},
+				"robotsBlocked": []string{"https://example.com/private"},
+			}
+		case r.URL.Path == "/api/v1/crawler/crawl/job" && r.URL.Query().Get("skip") == "2":
+			body = map[string]any{"success": true, "status": "completed", "completed": 3, "total": 3,
+				"data": []any{doc("c")}}
+		case r.URL.Path == "/api/v1/crawler/crawl/job":
+			switch checks.Add(1) {
+			case 1:
+				body = map[string]any{"success": true, "status": "scraping", "completed": 1, "total": 3,
+					"data": []any{doc("a")}}
+			default:
+				body = map[string]any{"success": true, "status": "completed", "completed": 3, "total": 3,
+					"data": []any{doc("a"), doc("b")}, "next": "/api/v1/crawler/crawl/job?skip=2"}
+			}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	return New(request.ClientConfig{ApiKey: "key", CrawlUrl: srv.URL}), &checks
+}
+
+func TestIterCrawl(t *testing.T) {
+	c, checks := crawlServer(t)
+	var got []string
+	for doc, err := range c.IterCrawl(context.Background(), "job", poller.WithInitialInterval(time.Millisecond)) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, doc.Markdown)
+	}
+	if strings.Join(got, ",") != "a,b,c" || checks.Load() != 2 {
+		t.Fatalf("got %v after %d checks", got, checks.Load())
+	}
+
+	// Breaking out stops the polling.
+	c, checks = crawlServer(t)
+	for range c.IterCrawl(context.Background(), "job", poller.WithInitialInterval(time.Millisecond)) {
+		break
+	}
+	if checks.Load() != 1 {
+		t.Fatalf("%d checks after break", checks.Load())
+	}
+}
+
+func TestCrawlUrlCollectsPages(t *testing.T) {
+	c, _ := crawlServer(t)
+	resp, err := c.CrawlUrl(context.Background(), "https://example.com", CrawlParams{}, poller.WithInitialInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 3 || resp.Next != "" || resp.Completed != 3 || resp.Total != 3 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+
+	errs, err := c.CrawlErrors(context.Background(), "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs.Errors) != 1 || errs.Errors[0].Error != "timeout" || len(errs.RobotsBlocked) != 1 {
+		t.Fatalf("unexpected errors %+v", errs)
+	}
+
+	if _, err = c.CheckCrawlStatusNext(context.Background(), "https://elsewhere.example/api/v1/crawler/crawl/job"); err == nil {
+		t.Fatal("a next link to another host must be refused")
+	}
+}
@@ -154,8 +154,9 @@ type CrawlResponse struct {
 
 type CrawlStatusResponse struct {
 	Status    Status                  `json:"status"`
-	Completed int                     `json:"completed"`
-	Total     int                     `json:"total"`
+	Completed int                     `json:"completed"` // Pages crawled so far
+	Total     int                     `json:"total"`     // Pages discovered so far
+	Next      string                  `json:"next,omitempty"`
 	Data      []ScrapingCrawlDocument `json:"data"`
 }
 
@@ -2,70 +2,81 @@ package deepserp
 
 import (
 	"context"
+	"errors"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/deepserp"
-	dh "github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/deepserp/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/poller"
 	"github.com/tidwall/gjson"
 	"strings"
-	"time"
 )
 
-type DeepSerp struct{}
+type DeepSerp struct {
+	client deepserp.DeepSerp
+}
 
-func NewDeepSerp(serverMode string) *DeepSerp {
+// NewDeepSerp creates the DeepSerp service. cfg overrides the credentials
+// and endpoints read from the environment.
+func NewDeepSerp(serverMode string, cfg ...request.ClientConfig) *DeepSerp {
 	log.Info("Internal DeepSerp init")
-	deepserp.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &DeepSerp{}
+	c := request.ClientConfigOf(cfg...)
+	return &DeepSerp{client: deepserp.NewClient(serverMode, c.BaseApiUrl, c)}
 }
 
 // CreateTask creates a new deepSerp task with the given context and request parameters.
 func (s *DeepSerp) CreateTask(ctx context.Context, req DeepserpTaskRequest) ([]byte, error) {
 	if req.ProxyCountry == "" {
-		req.ProxyCountry = env.Env.ProxyCountry
+		req.ProxyCountry = env.Get().ProxyCountry
 	}
-	response, err := deepserp.ClientInterface.CreateTask(ctx, &models.DeepserpTaskRequest{
+	response, err := s.client.CreateTask(ctx, &models.DeepserpTaskRequest{
 		Actor: string(req.Actor),
 		Input: req.Input,
 		Proxy: models.TaskProxy{Country: strings.ToUpper(req.ProxyCountry)},
 	})
 	if err != nil {
 		log.Errorf("deepserp create err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	return response, nil
 }
 
 func (s *DeepSerp) Close() error {
-	return dh.Default().Close()
+	return s.client.Close()
 }
 
 // GetTaskResult retrieves the result of a deepSerp task by its ID.
 func (s *DeepSerp) GetTaskResult(ctx context.Context, taskId string) ([]byte, error) {
-	result, err := deepserp.ClientInterface.GetTaskResult(ctx, taskId)
+	result, err := s.client.GetTaskResult(ctx, taskId)
 	if err != nil {
+		if errors.Is(err, poller.ErrPending) {
+			return nil, err
+		}
 		log.Errorf("get task result err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	return result, nil
 }
 
-func (s *DeepSerp) Scrape(ctx context.Context, req DeepserpTaskRequest) ([]byte, error) {
+// Scrape creates a deepSerp task and polls until its result is ready or ctx
+// is done. Use poller options to change the backoff or watch progress.
+func (s *DeepSerp) Scrape(ctx context.Context, req DeepserpTaskRequest, opts ...poller.Option) ([]byte, error) {
 	task, err := s.CreateTask(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	taskId := gjson.Parse(string(task)).Get("taskId").String()
-	if taskId != "" {
-		for {
-			result, err := s.GetTaskResult(ctx, taskId)
-			if err == nil {
-				return result, nil
-			}
-			time.Sleep(time.Millisecond * 200)
-		}
+	if taskId == "" {
+		return task, nil
 	}
-	return task, nil
+	result, err := poller.Poll(ctx, func(ctx context.Context) ([]byte, error) {
+		return s.client.GetTaskResult(ctx, taskId)
+	}, opts...)
+	if err != nil {
+		log.Errorf("get task result err:%v", err)
+		return nil, scerrors.From(err)
+	}
+	return result, nil
 }
@@ -82,7 +82,7 @@ func (s *Server) AddHandleGet(path string, f func(input []byte) (Response, error
 
 func (s *Server) Start(addr ...string) error {
 	if len(addr) == 0 {
-		addr = append(addr, env.Env.Actor.HttpPort)
+		addr = append(addr, env.Get().Actor.HttpPort)
 	}
 	if !strings.Contains(addr[0], ":") {
 		addr[0] = fmt.Sprintf(":%s", addr[0])
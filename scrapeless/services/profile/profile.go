@@ -2,20 +2,25 @@ package profile
 
 import (
 	"context"
-	"errors"
-	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/profile/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
+	"iter"
 )
 
-type Profile struct{}
+type Profile struct {
+	client profile.Profile
+}
 
-func New() *Profile {
+// New creates the profile service. cfg overrides the credentials and
+// endpoints read from the environment.
+func New(cfg ...request.ClientConfig) *Profile {
 	log.Info("Internal Profile init")
-	profile.NewClient("http", env.Env.ScrapelessBaseApiUrl)
-	return &Profile{}
+	c := request.ClientConfigOf(cfg...)
+	return &Profile{client: profile.NewClient("http", c.BaseApiUrl, c)}
 }
 
 // CreateProfile creates a new profile.
@@ -27,10 +32,10 @@ func (p *Profile) CreateProfile(ctx context.Context, name string) (*ProfileInfo,
 	if name == "" {
 		name = "untitled"
 	}
-	resp, err := profile.ClientInterface.Create(ctx, name)
+	resp, err := p.client.Create(ctx, name)
 	if err != nil {
 		log.Errorf("create profile err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 
 	return &ProfileInfo{
@@ -49,10 +54,10 @@ func (p *Profile) CreateProfile(ctx context.Context, name string) (*ProfileInfo,
 //	ctx: The request context.
 //	profileId: Id of the profile.
 func (p *Profile) GetProfile(ctx context.Context, profileId string) (*ProfileInfo, error) {
-	resp, err := profile.ClientInterface.Get(ctx, profileId)
+	resp, err := p.client.Get(ctx, profileId)
 	if err != nil {
 		log.Errorf("get profile err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 
 	return &ProfileInfo{
@@ -72,16 +77,16 @@ func (p *Profile) GetProfile(ctx context.Context, profileId string) (*ProfileInf
 //	req: The request parameters for listing profiles.
 func (p *Profile) ListProfiles(ctx context.Context, req *ListProfileRequest) (*ListProfileResponse, error) {
 	if req == nil {
-		return nil, errors.New("req is nil")
+		return nil, scerrors.New(scerrors.KindInvalidArgument, "req is nil")
 	}
-	resp, err := profile.ClientInterface.List(ctx, &models.ListProfileRequest{
+	resp, err := p.client.List(ctx, &models.ListProfileRequest{
 		Name:     req.Name,
 		Page:     req.Page,
 		PageSize: req.PageSize,
 	})
 	if err != nil {
 		log.Errorf("list profile err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	items := make([]ProfileInfo, 0, len(resp.Items))
 	for i := range resp.Items {
@@ -104,6 +109,23 @@ func (p *Profile) ListProfiles(ctx context.Context, req *ListProfileRequest) (*L
 	}, nil
 }
 
+// IterProfiles iterates over the profiles whose name matches name, or all
+// profiles when name is nil.
+// Parameters:
+//
+//	ctx: The context for the request, cancelling it ends the iteration.
+//	name: Optional name search.
+//	opts: Page size, prefetch and limit, see package pager.
+func (p *Profile) IterProfiles(ctx context.Context, name *string, opts ...pager.Option) iter.Seq2[ProfileInfo, error] {
+	return pager.Seq(ctx, func(ctx context.Context, page, pageSize int64) ([]ProfileInfo, int64, error) {
+		resp, err := p.ListProfiles(ctx, &ListProfileRequest{Name: name, Page: page, PageSize: pageSize})
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Items, resp.Total, nil
+	}, opts...)
+}
+
 // UpdateProfile update profile's name
 // Parameters:
 //
@@ -111,10 +133,10 @@ func (p *Profile) ListProfiles(ctx context.Context, req *ListProfileRequest) (*L
 //	profileId: profile's id.
 //	name: profile's name.
 func (p *Profile) UpdateProfile(ctx context.Context, profileId string, name string) (bool, error) {
-	resp, err := profile.ClientInterface.Update(ctx, profileId, name)
+	resp, err := p.client.Update(ctx, profileId, name)
 	if err != nil {
 		log.Errorf("delete profile err:%v", err)
-		return false, code.Format(err)
+		return false, scerrors.From(err)
 	}
 
 	return resp.Success, nil
@@ -126,15 +148,15 @@ func (p *Profile) UpdateProfile(ctx context.Context, profileId string, name stri
 //	ctx: The context for the request.
 //	profileId: profile's id.
 func (p *Profile) DeleteProfile(ctx context.Context, profileId string) (bool, error) {
-	resp, err := profile.ClientInterface.Delete(ctx, profileId)
+	resp, err := p.client.Delete(ctx, profileId)
 	if err != nil {
 		log.Errorf("delete profile err:%v", err)
-		return false, code.Format(err)
+		return false, scerrors.From(err)
 	}
 
 	return resp.Success, nil
 }
 
 func (p *Profile) Close() error {
-	return nil
+	return p.client.Close()
 }
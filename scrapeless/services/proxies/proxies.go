@@ -3,20 +3,26 @@ package proxies
 import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	rp "github.com/scrapeless-ai/sdk-go/internal/remote/proxy"
-	"github.com/scrapeless-ai/sdk-go/internal/remote/proxy/http"
 	proxy2 "github.com/scrapeless-ai/sdk-go/internal/remote/proxy/models"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
 type Proxy struct {
+	client rp.Proxy
+	cfg    request.ClientConfig
 }
 
-func NewProxy(serverMode string) *Proxy {
+// NewProxy creates the proxy service. cfg overrides the API key read from
+// the environment.
+func NewProxy(serverMode string, cfg ...request.ClientConfig) *Proxy {
 	log.Infof("proxies init")
-	rp.NewClient(serverMode)
-	return &Proxy{}
+	return &Proxy{
+		client: rp.NewClient(serverMode),
+		cfg:    request.ClientConfigOf(cfg...),
+	}
 }
 
 // Proxy retrieves proxies information.
@@ -26,8 +32,8 @@ func NewProxy(serverMode string) *Proxy {
 //	ctx: context.Context - Context for the request.
 //	proxies: ProxyActor - Struct containing proxies request parameters like country, session duration, etc.
 func (ph *Proxy) Proxy(ctx context.Context, proxy ProxyActor) (string, error) {
-	proxyUrl, err := rp.ClientInterface.ProxyGetProxy(ctx, &proxy2.GetProxyRequest{
-		ApiKey:          env.GetActorEnv().ApiKey,
+	proxyUrl, err := ph.client.ProxyGetProxy(ctx, &proxy2.GetProxyRequest{
+		ApiKey:          ph.cfg.ApiKey,
 		Country:         proxy.Country,
 		SessionDuration: proxy.SessionDuration,
 		SessionId:       proxy.SessionId,
@@ -36,11 +42,11 @@ func (ph *Proxy) Proxy(ctx context.Context, proxy ProxyActor) (string, error) {
 	})
 	if err != nil {
 		log.Errorf("get proxies err:%v", err)
-		return "", code.Format(err)
+		return "", scerrors.From(err)
 	}
 	return proxyUrl, nil
 }
 
 func (ph *Proxy) Close() error {
-	return http.Default().Close()
+	return ph.client.Close()
 }
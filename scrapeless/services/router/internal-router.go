@@ -1,25 +1,32 @@
 package router
 
 import (
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/router"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"io"
 
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
 )
 
-type Router struct{}
+type Router struct {
+	client router.Router
+}
 
-func New(serverMode string) *Router {
+// New creates the router service. cfg overrides the credentials and
+// endpoints read from the environment.
+func New(serverMode string, cfg ...request.ClientConfig) *Router {
 	log.Info("Internal Router init")
-	router.NewClient(serverMode)
-	return &Router{}
+	c := request.ClientConfigOf(cfg...)
+	return &Router{client: router.NewClient(serverMode, c.ActorUrl, c)}
 }
 
 // Request keyword is the actor's keyword-->Now its value is runnerId
 func (r *Router) Request(keyword string, method string, path string, body io.Reader, headers map[string]string) (data []byte, err error) {
-	return router.ClientInterface.Request(keyword, method, path, body, headers)
+	data, err = r.client.Request(keyword, method, path, body, headers)
+	return data, scerrors.From(err)
 }
 
 func (r *Router) Close() error {
-	return nil
+	return r.client.Close()
 }
@@ -2,71 +2,82 @@ package scraping
 
 import (
 	"context"
+	"errors"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/scraping"
-	sh "github.com/scrapeless-ai/sdk-go/internal/remote/scraping/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/scraping/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/poller"
 	"github.com/tidwall/gjson"
 	"strings"
-	"time"
 )
 
-type Scraping struct{}
+type Scraping struct {
+	client scraping.Scraping
+}
 
-func New(serverMode string) *Scraping {
-	log.Info("Internal Router init")
-	scraping.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &Scraping{}
+// New creates the scraping service. cfg overrides the credentials and
+// endpoints read from the environment.
+func New(serverMode string, cfg ...request.ClientConfig) *Scraping {
+	log.Info("Internal Scraping init")
+	c := request.ClientConfigOf(cfg...)
+	return &Scraping{client: scraping.NewClient(serverMode, c.BaseApiUrl, c)}
 }
 
 // CreateTask creates a new scraping task with the given context and request parameters.
 func (s *Scraping) CreateTask(ctx context.Context, req ScrapingTaskRequest) ([]byte, error) {
 	if req.ProxyCountry == "" {
-		req.ProxyCountry = env.Env.ProxyCountry
+		req.ProxyCountry = env.Get().ProxyCountry
 	}
-	response, err := scraping.ClientInterface.CreateTask(ctx, &models.ScrapingTaskRequest{
+	response, err := s.client.CreateTask(ctx, &models.ScrapingTaskRequest{
 		Actor: string(req.Actor),
 		Input: req.Input,
 		Proxy: models.TaskProxy{Country: strings.ToUpper(req.ProxyCountry)},
 	})
 	if err != nil {
 		log.Errorf("scraping create err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	return response, nil
 }
 
 func (s *Scraping) Close() error {
-	return sh.Default().Close()
+	return s.client.Close()
 }
 
 // GetTaskResult retrieves the result of a scraping task by its ID.
 func (s *Scraping) GetTaskResult(ctx context.Context, taskId string) ([]byte, error) {
-	result, err := scraping.ClientInterface.GetTaskResult(ctx, taskId)
+	result, err := s.client.GetTaskResult(ctx, taskId)
 	if err != nil {
+		if errors.Is(err, poller.ErrPending) {
+			return nil, err
+		}
 		log.Errorf("get task result err:%v", err)
-		return nil, code.Format(err)
+		return nil, scerrors.From(err)
 	}
 	return result, nil
 }
 
-// Scrape performs a web scraping task by creating a task and polling for the result.
-func (s *Scraping) Scrape(ctx context.Context, req ScrapingTaskRequest) ([]byte, error) {
+// Scrape creates a task and waits for its result. Polling backs off
+// exponentially and stops when ctx is done; opts tune the backoff and
+// register a progress callback.
+func (s *Scraping) Scrape(ctx context.Context, req ScrapingTaskRequest, opts ...poller.Option) ([]byte, error) {
 	task, err := s.CreateTask(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	taskId := gjson.Parse(string(task)).Get("taskId").String()
-	if taskId != "" {
-		for {
-			result, err := s.GetTaskResult(ctx, taskId)
-			if err == nil {
-				return result, nil
-			}
-			time.Sleep(time.Millisecond * 200)
-		}
+	if taskId == "" {
+		return task, nil
 	}
-	return task, nil
+	result, err := poller.Poll(ctx, func(ctx context.Context) ([]byte, error) {
+		return s.client.GetTaskResult(ctx, taskId)
+	}, opts...)
+	if err != nil {
+		log.Errorf("get task result err:%v", err)
+		return nil, scerrors.From(err)
+	}
+	return result, nil
 }
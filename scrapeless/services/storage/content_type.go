@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// sniffLen is the number of bytes looked at to detect a content type.
+const sniffLen = 512
+
+// contentTypes maps the accepted file extensions, without dot and in lower
+// case, to their content type.
+var contentTypes = struct {
+	sync.RWMutex
+	byExt map[string]string
+}{byExt: map[string]string{
+	"json":    "application/json",
+	"jsonl":   "application/x-ndjson",
+	"html":    "text/html",
+	"htm":     "text/html",
+	"txt":     "text/plain",
+	"csv":     "text/csv",
+	"xml":     "application/xml",
+	"png":     "image/png",
+	"jpg":     "image/jpeg",
+	"jpeg":    "image/jpeg",
+	"gif":     "image/gif",
+	"webp":    "image/webp",
+	"pdf":     "application/pdf",
+	"zip":     "application/zip",
+	"gz":      "application/gzip",
+	"parquet": "application/vnd.apache.parquet",
+	"xlsx":    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}}
+
+// RegisterContentType allows objects with the extension to be stored, with
+// the given content type. It replaces the content type of a known extension.
+// Parameters:
+//
+//	ext: The file extension, with or without its leading dot.
+//	contentType: The media type of the files, like "application/epub+zip".
+func RegisterContentType(ext string, contentType string) {
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	contentTypes.Lock()
+	defer contentTypes.Unlock()
+	contentTypes.byExt[ext] = contentType
+}
+
+// DetectContentType returns the content type of an object from the extension
+// of its filename or, when it has no known extension, from its first bytes.
+// It reports false when the content type is not registered, see
+// RegisterContentType.
+// Parameters:
+//
+//	filename: The name of the object.
+//	head: The start of the content, only the first 512 bytes are used.
+func DetectContentType(filename string, head []byte) (string, bool) {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	contentTypes.RLock()
+	defer contentTypes.RUnlock()
+	if contentType, ok := contentTypes.byExt[ext]; ok {
+		return contentType, true
+	}
+	if _, ok := ObjectTypeMapping[ext]; ok && ext != "" {
+		if contentType := mime.TypeByExtension("." + ext); contentType != "" {
+			return contentType, true
+		}
+		return "application/octet-stream", true
+	}
+	if len(head) == 0 {
+		return "", false
+	}
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head[:min(len(head), sniffLen)]))
+	for _, contentType := range contentTypes.byExt {
+		if contentType == sniffed {
+			return contentType, true
+		}
+	}
+	return "", false
+}
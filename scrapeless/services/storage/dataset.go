@@ -3,29 +3,35 @@ package storage
 import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
+	"iter"
+	"sync"
 )
 
-type Dataset struct{}
+type Dataset struct {
+	client     storage.Dataset
+	validators sync.Map // datasetId -> *datasetValidator
+}
 
 // ListDatasets retrieves a list of dataset with pagination and sorting options.
 // Parameters:
 //
 //	ctx: The request context.
 //	page: Page number (starting from 1). Defaults to 1 if <=0.
-//	pageSize:  Number of items per page. Minimum 10, defaults to 10 if smaller.
+//	pageSize:  Number of items per page. Defaults to 10 if <1.
 //	desc: Sort namespaces in descending order by creation time if true.
 func (s *Dataset) ListDatasets(ctx context.Context, page int64, pageSize int64, desc bool) (*ListDatasetsResponse, error) {
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	datasets, err := storage.ClientInterface.ListDatasets(ctx, &models.ListDatasetsRequest{
+	datasets, err := s.client.ListDatasets(ctx, &models.ListDatasetsRequest{
 		ActorId:  &env.GetActorEnv().ActorId,
 		RunId:    &env.GetActorEnv().RunId,
 		Page:     page,
@@ -33,8 +39,8 @@ func (s *Dataset) ListDatasets(ctx context.Context, page int64, pageSize int64,
 		Desc:     desc,
 	})
 	if err != nil {
-		log.Errorf("failed to list datasets: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to list datasets: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var itemArray []DatasetInfo
 	for _, item := range datasets.Items {
@@ -54,6 +60,23 @@ func (s *Dataset) ListDatasets(ctx context.Context, page int64, pageSize int64,
 	}, nil
 }
 
+// IterDatasets iterates over the datasets of the current run, fetching the
+// pages as they are consumed.
+// Parameters:
+//
+//	ctx: The request context, cancelling it ends the iteration.
+//	desc: Sort datasets in descending order by creation time if true.
+//	opts: Page size, prefetch and limit, see package pager.
+func (s *Dataset) IterDatasets(ctx context.Context, desc bool, opts ...pager.Option) iter.Seq2[DatasetInfo, error] {
+	return pager.Seq(ctx, func(ctx context.Context, page, pageSize int64) ([]DatasetInfo, int64, error) {
+		resp, err := s.ListDatasets(ctx, page, pageSize, desc)
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Items, resp.Total, nil
+	}, opts...)
+}
+
 // CreateDataset Creates a new dataset storage.
 // Parameters:
 //
@@ -61,14 +84,14 @@ func (s *Dataset) ListDatasets(ctx context.Context, page int64, pageSize int64,
 //	name: The name of the dataset to create.
 func (s *Dataset) CreateDataset(ctx context.Context, name string) (id string, datasetName string, err error) {
 	name = name + "-" + env.GetActorEnv().RunId
-	dataset, err := storage.ClientInterface.CreateDataset(ctx, &models.CreateDatasetRequest{
+	dataset, err := s.client.CreateDataset(ctx, &models.CreateDatasetRequest{
 		Name:    name,
 		ActorId: &env.GetActorEnv().ActorId,
 		RunId:   &env.GetActorEnv().RunId,
 	})
 	if err != nil {
-		log.Errorf("failed to create dataset: %v", code.Format(err))
-		return "", "", code.Format(err)
+		log.Errorf("failed to create dataset: %v", err)
+		return "", "", scerrors.From(err)
 	}
 	return dataset.Id, name, nil
 }
@@ -81,10 +104,10 @@ func (s *Dataset) CreateDataset(ctx context.Context, name string) (id string, da
 //	name: Original dataset name (will be combined with runtime ID internally)
 func (s *Dataset) UpdateDataset(ctx context.Context, datasetId string, name string) (ok bool, datasetName string, err error) {
 	name = name + "-" + env.GetActorEnv().RunId
-	ok, err = storage.ClientInterface.UpdateDataset(ctx, datasetId, name)
+	ok, err = s.client.UpdateDataset(ctx, datasetId, name)
 	if err != nil {
-		log.Errorf("failed to update dataset: %v", code.Format(err))
-		return false, "", code.Format(err)
+		log.Errorf("failed to update dataset: %v", err)
+		return false, "", scerrors.From(err)
 	}
 	return ok, name, nil
 }
@@ -95,24 +118,34 @@ func (s *Dataset) UpdateDataset(ctx context.Context, datasetId string, name stri
 //
 //	ctx: The context for the request, used for cancellation and timeouts.
 func (s *Dataset) DelDataset(ctx context.Context, datasetId string) (bool, error) {
-	ok, err := storage.ClientInterface.DelDataset(ctx, datasetId)
+	ok, err := s.client.DelDataset(ctx, datasetId)
 	if err != nil {
-		log.Errorf("failed to delete dataset: %v", code.Format(err))
-		return false, code.Format(err)
+		log.Errorf("failed to delete dataset: %v", err)
+		return false, scerrors.From(err)
 	}
 	return ok, nil
 }
 
-// AddItems adds a list of items to the dataset data store.
+// AddItems adds a list of items to the dataset data store. When the dataset
+// has a schema, see SetSchema, only the valid items are added.
 //
 // Parameters:
 //   - ctx: The context for the request.
 //   - items: A slice of maps representing the items to add. Each map contains key-value pairs of any type.
 func (s *Dataset) AddItems(ctx context.Context, datasetId string, items []map[string]any) (bool, error) {
-	ok, err := storage.ClientInterface.AddDatasetItem(ctx, datasetId, items)
+	items = s.validate(ctx, datasetId, items)
+	if len(items) == 0 {
+		return true, nil
+	}
+	return s.addValidated(ctx, datasetId, items)
+}
+
+// addValidated adds items which went through validate already.
+func (s *Dataset) addValidated(ctx context.Context, datasetId string, items []map[string]any) (bool, error) {
+	ok, err := s.client.AddDatasetItem(ctx, datasetId, items)
 	if err != nil {
 		log.Errorf("failed to add items: %v", err)
-		return false, code.Format(err)
+		return false, scerrors.From(err)
 	}
 	return ok, nil
 }
@@ -129,18 +162,18 @@ func (s *Dataset) GetItems(ctx context.Context, datasetId string, page int, page
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	items, err := storage.ClientInterface.GetDataset(ctx, &models.GetDataset{
+	items, err := s.client.GetDataset(ctx, &models.GetDataset{
 		DatasetId: datasetId,
 		Desc:      desc,
 		Page:      page,
 		PageSize:  pageSize,
 	})
 	if err != nil {
-		log.Errorf("failed to get items: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to get items: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var itemArray []map[string]any
 	for _, item := range items.Items {
@@ -152,6 +185,23 @@ func (s *Dataset) GetItems(ctx context.Context, datasetId string, page int, page
 	}, nil
 }
 
+// IterItems iterates over the items of a dataset.
+// Parameters:
+//
+//	ctx: The context for the request, cancelling it ends the iteration.
+//	datasetId: The dataset to read.
+//	desc: Whether to sort items in descending order (true) or ascending (false).
+//	opts: Page size, prefetch and limit, see package pager.
+func (s *Dataset) IterItems(ctx context.Context, datasetId string, desc bool, opts ...pager.Option) iter.Seq2[map[string]any, error] {
+	return pager.Seq(ctx, func(ctx context.Context, page, pageSize int64) ([]map[string]any, int64, error) {
+		resp, err := s.GetItems(ctx, datasetId, int(page), int(pageSize), desc)
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Items, int64(resp.Total), nil
+	}, opts...)
+}
+
 func (s *Dataset) Close() error {
 	return nil
 }
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// Operators of a DatasetFilter and of a MetadataFilter.
+const (
+	FilterEq     = "eq"     // Equal to Value
+	FilterNe     = "ne"     // Missing or not equal to Value
+	FilterGt     = "gt"     // Greater than Value, both numbers or both strings
+	FilterGte    = "gte"    // Greater than or equal to Value
+	FilterLt     = "lt"     // Less than Value
+	FilterLte    = "lte"    // Less than or equal to Value
+	FilterIn     = "in"     // Equal to an element of Value, a slice
+	FilterRegex  = "regex"  // Matching the regular expression Value
+	FilterExists = "exists" // Present, or missing when Value is false
+)
+
+// Operators of a DatasetAggregation.
+const (
+	AggCount = "count" // Items, or items with a non-null value at Path
+	AggSum   = "sum"   // Sum of the numbers at Path
+	AggMin   = "min"
+	AggMax   = "max"
+	AggAvg   = "avg"
+)
+
+// DatasetQuery selects, projects and aggregates the items of a dataset.
+// Paths use the gjson syntax, such as "price.amount" or "tags.0".
+//
+// Without GroupBy and Aggregations the results are the matching items,
+// reduced to Fields when set and deduplicated when Distinct is set. With
+// them, there is one result per distinct value of the GroupBy paths, holding
+// those paths and the aggregations, in order of first appearance.
+type DatasetQuery struct {
+	Filters      []DatasetFilter      // All of them must hold
+	Fields       []string             // Paths kept in the results, keyed by path
+	Distinct     bool                 // Drop repeated results
+	GroupBy      []string             // Paths whose values form the groups
+	Aggregations []DatasetAggregation // Values computed per group
+	Desc         bool                 // Read the dataset from its last item
+	Offset       int                  // Results skipped
+	Limit        int                  // Maximum number of results, no limit when 0
+}
+
+// DatasetFilter is a condition on the value at Path, see the Filter constants.
+type DatasetFilter struct {
+	Path  string
+	Op    string
+	Value any
+}
+
+// DatasetAggregation computes a value per group, see the Agg constants. The
+// result is named As, or op(path) when As is empty.
+type DatasetAggregation struct {
+	Op   string
+	Path string
+	As   string
+}
+
+// QueryResult holds the results of Dataset.Query.
+type QueryResult struct {
+	Items   []map[string]any `json:"items"`
+	Scanned int              `json:"scanned"` // Items read to answer the query
+}
+
+// Query runs a query over the items of a dataset. The items are read page by
+// page and the query is evaluated locally, with the same results on every
+// backend. A query with a Limit and without aggregations stops reading once
+// it has enough results.
+// Parameters:
+//
+//	ctx: The request context.
+//	datasetId: The dataset to query.
+//	query: Filters, projection, distinct, grouping and aggregations.
+func (s *Dataset) Query(ctx context.Context, datasetId string, query DatasetQuery) (*QueryResult, error) {
+	req := &models.QueryDataset{
+		DatasetId: datasetId,
+		Fields:    query.Fields,
+		Distinct:  query.Distinct,
+		GroupBy:   query.GroupBy,
+		Desc:      query.Desc,
+		Offset:    query.Offset,
+		Limit:     query.Limit,
+	}
+	for _, f := range query.Filters {
+		req.Filters = append(req.Filters, models.DatasetFilter(f))
+	}
+	for _, a := range query.Aggregations {
+		req.Aggregations = append(req.Aggregations, models.DatasetAggregation(a))
+	}
+	resp, err := s.client.QueryDataset(ctx, req)
+	if err != nil {
+		log.Errorf("failed to query dataset: %v", err)
+		return nil, scerrors.From(err)
+	}
+	return &QueryResult{Items: resp.Items, Scanned: resp.Scanned}, nil
+}
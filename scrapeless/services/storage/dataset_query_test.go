@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+func queryItems() []map[string]any {
+	shops := []string{"north", "south", "west"}
+	var items []map[string]any
+	for i := 0; i < 250; i++ {
+		item := map[string]any{
+			"title": fmt.Sprintf("item %03d", i),
+			"price": map[string]any{"amount": i % 10},
+			"shop":  shops[i%3],
+		}
+		if i%5 == 0 {
+			item["tags"] = []any{"sale"}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// datasetServer serves the items of a dataset, queries are evaluated by the
+// client.
+func datasetServer(t *testing.T, items []map[string]any) (*Dataset, *atomic.Int32) {
+	t.Helper()
+	var pages atomic.Int32
+	client := httpClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/dataset/ds/items" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		pages.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		size = min(size, 40) // Smaller pages than queries ask for
+		items := items
+		if r.URL.Query().Get("desc") == "true" {
+			items = slices.Clone(items)
+			slices.Reverse(items)
+		}
+		data := map[string]any{
+			"items": items[min((page-1)*size, len(items)):min(page*size, len(items))],
+			"total": len(items),
+		}
+		_ = json.NewEncoder(w).Encode(request.RespInfo{Data: data})
+	})
+	return &Dataset{client: client}, &pages
+}
+
+func TestDatasetQuery(t *testing.T) {
+	items := queryItems()
+	local, id := localDataset(t, items)
+	remote, pages := datasetServer(t, items)
+	ctx := context.Background()
+
+	queries := map[string]DatasetQuery{
+		"filter and project": {
+			Filters: []DatasetFilter{
+				{Path: "price.amount", Op: FilterGte, Value: 8},
+				{Path: "shop", Op: FilterIn, Value: []string{"north", "west"}},
+				{Path: "title", Op: FilterRegex, Value: `^item 1`},
+			},
+			Fields: []string{"title", "price.amount"},
+		},
+		"distinct": {Fields: []string{"shop"}, Distinct: true},
+		"group": {
+			Filters: []DatasetFilter{{Path: "tags", Op: FilterExists}},
+			GroupBy: []string{"shop"},
+			Aggregations: []DatasetAggregation{
+				{Op: AggCount, As: "n"},
+				{Op: AggSum, Path: "price.amount"},
+				{Op: AggMax, Path: "price.amount"},
+			},
+		},
+		"limit": {Filters: []DatasetFilter{{Path: "shop", Op: FilterNe, Value: "south"}}, Desc: true, Offset: 2, Limit: 3},
+	}
+	for name, query := range queries {
+		want, err := local.Query(ctx, id, query)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := remote.Query(ctx, "ds", query)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		// The items of the local backend went through JSON too.
+		if encoded, _ := json.Marshal(got.Items); string(encoded) != mustJSON(t, want.Items) {
+			t.Fatalf("%s: http %s, local %s", name, encoded, mustJSON(t, want.Items))
+		}
+	}
+
+	res, _ := local.Query(ctx, id, queries["filter and project"])
+	if len(res.Items) != 13 || !reflect.DeepEqual(res.Items[0], map[string]any{"title": "item 108", "price.amount": 8.0}) {
+		t.Fatalf("filter: %v", res.Items)
+	}
+	res, _ = local.Query(ctx, id, queries["distinct"])
+	if len(res.Items) != 3 {
+		t.Fatalf("distinct: %v", res.Items)
+	}
+	res, _ = local.Query(ctx, id, queries["group"])
+	if len(res.Items) != 3 || res.Items[0]["shop"] != "north" || res.Items[0]["n"] != 17 ||
+		res.Items[0]["sum(price.amount)"] != 40.0 || res.Items[0]["max(price.amount)"] != 5.0 {
+		t.Fatalf("group: %v", res.Items)
+	}
+
+	// A limited query stops reading once it has its results.
+	pages.Store(0)
+	res, err := remote.Query(ctx, "ds", queries["limit"])
+	if err != nil || len(res.Items) != 3 || res.Items[0]["title"] != "item 246" || res.Scanned != 7 || pages.Load() != 1 {
+		t.Fatalf("limit: %v, scanned %d, pages %d, %v", res.Items, res.Scanned, pages.Load(), err)
+	}
+
+	res, _ = local.Query(ctx, id, DatasetQuery{
+		Filters:      []DatasetFilter{{Path: "shop", Op: FilterEq, Value: "east"}},
+		Aggregations: []DatasetAggregation{{Op: AggCount}, {Op: AggAvg, Path: "price.amount"}},
+	})
+	if len(res.Items) != 1 || res.Items[0]["count"] != 0 || res.Items[0]["avg(price.amount)"] != nil {
+		t.Fatalf("aggregations of nothing: %v", res.Items)
+	}
+
+	_, err = local.Query(ctx, id, DatasetQuery{Filters: []DatasetFilter{{Path: "title", Op: "like"}}})
+	if !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("unknown operator: %v", err)
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	buf, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/schema"
+)
+
+const defaultReportSamples = 20
+
+// Actions taken on an item that does not satisfy the schema of its dataset.
+const (
+	ActionCoerced     = "coerced"     // The item was repaired and added
+	ActionQuarantined = "quarantined" // The item was added to the quarantine dataset
+	ActionRejected    = "rejected"    // The item was dropped
+)
+
+// SchemaOptions configures the validation of the items of a dataset.
+type SchemaOptions struct {
+	Coerce     bool   // Repair invalid items before quarantining or rejecting them, see schema.Coerce
+	Quarantine string // Dataset receiving the invalid items, which are rejected when empty
+	Samples    int    // Invalid items kept in the report
+	// OnInvalid receives every item that did not satisfy the schema as
+	// given, with the action taken.
+	OnInvalid func(item InvalidItem)
+}
+
+// SchemaOption configures the validation of the items of a dataset.
+type SchemaOption func(*SchemaOptions)
+
+// WithCoercion repairs invalid items when possible, see schema.Coerce.
+func WithCoercion() SchemaOption {
+	return func(o *SchemaOptions) { o.Coerce = true }
+}
+
+// WithQuarantine adds the invalid items to datasetId instead of dropping
+// them. Their validation errors are stored in the "_errors" field.
+func WithQuarantine(datasetId string) SchemaOption {
+	return func(o *SchemaOptions) { o.Quarantine = datasetId }
+}
+
+// WithReportSamples keeps up to n invalid items in the validation report.
+func WithReportSamples(n int) SchemaOption {
+	return func(o *SchemaOptions) { o.Samples = n }
+}
+
+// WithInvalidHandler registers a callback receiving the invalid items.
+func WithInvalidHandler(fn func(item InvalidItem)) SchemaOption {
+	return func(o *SchemaOptions) { o.OnInvalid = fn }
+}
+
+// InvalidItem is an item that did not satisfy the schema of its dataset.
+type InvalidItem struct {
+	Item   map[string]any           `json:"item"`
+	Errors []schema.ValidationError `json:"errors"`
+	Action string                   `json:"action"`
+}
+
+// ValidationReport counts the items checked against the schema of a dataset.
+type ValidationReport struct {
+	DatasetId   string        `json:"datasetId"`
+	Checked     int64         `json:"checked"`
+	Valid       int64         `json:"valid"`
+	Coerced     int64         `json:"coerced"`
+	Quarantined int64         `json:"quarantined"`
+	Rejected    int64         `json:"rejected"`
+	Samples     []InvalidItem `json:"samples,omitempty"`
+}
+
+type datasetValidator struct {
+	schema *schema.Schema
+	opts   SchemaOptions
+
+	mu     sync.Mutex
+	report ValidationReport
+}
+
+// SetSchema validates the items later added to datasetId against sch.
+// Invalid items are rejected unless the options coerce or quarantine them;
+// AddItems logs them but does not fail. Build sch with schema.Parse or
+// derive it from a struct with schema.For.
+// Parameters:
+//
+//	datasetId: The dataset whose items are validated.
+//	sch: The schema of the items.
+//	opts: Coercion, quarantine dataset, report samples and invalid item handler.
+func (s *Dataset) SetSchema(datasetId string, sch *schema.Schema, opts ...SchemaOption) error {
+	if sch == nil {
+		return scerrors.New(scerrors.KindInvalidArgument, "dataset schema is nil")
+	}
+	o := SchemaOptions{Samples: defaultReportSamples}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Quarantine == datasetId {
+		return scerrors.Newf(scerrors.KindInvalidArgument, "dataset %s cannot quarantine its own items", datasetId)
+	}
+	s.validators.Store(datasetId, &datasetValidator{
+		schema: sch,
+		opts:   o,
+		report: ValidationReport{DatasetId: datasetId},
+	})
+	return nil
+}
+
+// RemoveSchema stops validating the items of datasetId and drops its report.
+func (s *Dataset) RemoveSchema(datasetId string) {
+	s.validators.Delete(datasetId)
+}
+
+// ValidationReport returns the report of the items validated since the
+// schema of datasetId was set, false when it has none.
+func (s *Dataset) ValidationReport(datasetId string) (ValidationReport, bool) {
+	v, ok := s.validators.Load(datasetId)
+	if !ok {
+		return ValidationReport{}, false
+	}
+	return v.(*datasetValidator).snapshot(), true
+}
+
+// validate sorts the items to add to datasetId. The caller adds the valid
+// ones and the quarantined ones to the quarantine dataset.
+func (s *Dataset) validate(ctx context.Context, datasetId string, items []map[string]any) []map[string]any {
+	val, ok := s.validators.Load(datasetId)
+	if !ok {
+		return items
+	}
+	v := val.(*datasetValidator)
+	valid, quarantined := v.check(items)
+	if len(quarantined) > 0 {
+		if _, err := s.client.AddDatasetItem(ctx, v.opts.Quarantine, quarantined); err != nil {
+			log.Errorf("failed to quarantine %d items of dataset %s: %v", len(quarantined), datasetId, err)
+			v.mu.Lock()
+			v.report.Quarantined -= int64(len(quarantined))
+			v.report.Rejected += int64(len(quarantined))
+			v.mu.Unlock()
+		}
+	}
+	if n := len(items) - len(valid) - len(quarantined); n > 0 {
+		log.Warnf("dataset %s: rejected %d items not satisfying its schema", datasetId, n)
+	}
+	return valid
+}
+
+func (v *datasetValidator) check(items []map[string]any) (valid, quarantined []map[string]any) {
+	var invalid []InvalidItem
+	v.mu.Lock()
+	for _, item := range items {
+		v.report.Checked++
+		normalized, err := schema.Normalize(item)
+		var errs []schema.ValidationError
+		if err != nil {
+			errs = []schema.ValidationError{{Message: "item cannot be encoded: " + err.Error()}}
+		} else if errs = v.schema.Validate(normalized); len(errs) == 0 {
+			v.report.Valid++
+			valid = append(valid, item)
+			continue
+		}
+
+		bad := InvalidItem{Item: item, Errors: errs}
+		if v.opts.Coerce && err == nil {
+			if fixed, ok := v.schema.Coerce(normalized); ok && len(v.schema.Validate(fixed)) == 0 {
+				if m, isMap := fixed.(map[string]any); isMap {
+					bad.Action = ActionCoerced
+					v.report.Coerced++
+					valid = append(valid, m)
+				}
+			}
+		}
+		if bad.Action == "" && v.opts.Quarantine != "" && err == nil {
+			bad.Action = ActionQuarantined
+			v.report.Quarantined++
+			quarantined = append(quarantined, quarantineItem(item, errs))
+		}
+		if bad.Action == "" {
+			bad.Action = ActionRejected
+			v.report.Rejected++
+		}
+		if len(v.report.Samples) < v.opts.Samples {
+			v.report.Samples = append(v.report.Samples, bad)
+		}
+		invalid = append(invalid, bad)
+	}
+	v.mu.Unlock()
+
+	if v.opts.OnInvalid != nil {
+		for _, bad := range invalid {
+			v.opts.OnInvalid(bad)
+		}
+	}
+	return valid, quarantined
+}
+
+func (v *datasetValidator) snapshot() ValidationReport {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	report := v.report
+	report.Samples = append([]InvalidItem(nil), v.report.Samples...)
+	return report
+}
+
+// quarantineItem copies item with its validation errors.
+func quarantineItem(item map[string]any, errs []schema.ValidationError) map[string]any {
+	out := make(map[string]any, len(item)+1)
+	for k, v := range item {
+		out[k] = v
+	}
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Error()
+	}
+	out["_errors"] = messages
+	return out
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/schema"
+)
+
+type listing struct {
+	Title string  `json:"title" jsonschema:"minLength=1"`
+	Price float64 `json:"price" jsonschema:"minimum=0"`
+}
+
+var listingItems = []map[string]any{
+	{"title": "lamp", "price": 12.5},
+	{"title": "desk", "price": "120"},
+	{"title": "", "price": 3},
+	{"price": 4},
+}
+
+func TestDatasetSchemaReject(t *testing.T) {
+	sch, err := schema.For(listing{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var invalid []InvalidItem
+	rec := &recordingDataset{}
+	dataset := &Dataset{client: rec}
+	if err = dataset.SetSchema("ds", sch, WithReportSamples(2), WithInvalidHandler(func(item InvalidItem) {
+		invalid = append(invalid, item)
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, err := dataset.AddItems(context.Background(), "ds", listingItems)
+	if !ok || err != nil {
+		t.Fatalf("add: %v, %v", ok, err)
+	}
+	if got := rec.sizes(); len(got) != 1 || got[0] != 1 || rec.batches[0][0]["title"] != "lamp" {
+		t.Fatalf("batches %v", rec.batches)
+	}
+	report, _ := dataset.ValidationReport("ds")
+	if report.Checked != 4 || report.Valid != 1 || report.Rejected != 3 || len(report.Samples) != 2 {
+		t.Fatalf("report %+v", report)
+	}
+	if len(invalid) != 3 || invalid[2].Action != ActionRejected || invalid[2].Errors[0].Path != "/title" {
+		t.Fatalf("invalid items %+v", invalid)
+	}
+
+	// Nothing is left to add once every item is rejected.
+	if _, err = dataset.AddItems(context.Background(), "ds", listingItems[3:]); err != nil || len(rec.sizes()) != 1 {
+		t.Fatalf("add invalid only: %v, %v", err, rec.sizes())
+	}
+	dataset.RemoveSchema("ds")
+	if _, ok := dataset.ValidationReport("ds"); ok {
+		t.Fatal("report kept after RemoveSchema")
+	}
+	if err = dataset.SetSchema("ds", sch, WithQuarantine("ds")); !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("quarantine to itself: %v", err)
+	}
+}
+
+func TestDatasetSchemaQuarantine(t *testing.T) {
+	dataset, id := localDataset(t, nil)
+	ctx := context.Background()
+	quarantine, _, err := dataset.CreateDataset(ctx, "quarantine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sch, _ := schema.For(listing{})
+	if err = dataset.SetSchema(id, sch, WithCoercion(), WithQuarantine(quarantine)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = dataset.AddItems(ctx, id, listingItems); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := dataset.GetItems(ctx, id, 1, 10, false)
+	if err != nil || items.Total != 2 {
+		t.Fatalf("items %+v, %v", items, err)
+	}
+	for _, item := range items.Items {
+		if _, isNumber := item["price"].(float64); !isNumber {
+			t.Fatalf("price of %v was not coerced", item)
+		}
+	}
+	bad, err := dataset.GetItems(ctx, quarantine, 1, 10, false)
+	if err != nil || bad.Total != 2 {
+		t.Fatalf("quarantined %+v, %v", bad, err)
+	}
+	if errs, _ := bad.Items[0]["_errors"].([]any); len(errs) == 0 {
+		t.Fatalf("quarantined item without errors: %v", bad.Items[0])
+	}
+	report, _ := dataset.ValidationReport(id)
+	if report.Valid != 1 || report.Coerced != 1 || report.Quarantined != 2 || report.Rejected != 0 {
+		t.Fatalf("report %+v", report)
+	}
+}
@@ -0,0 +1,317 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+const (
+	defaultWriterBatchItems    = 500
+	defaultWriterBatchBytes    = 4 << 20
+	defaultWriterFlushInterval = 5 * time.Second
+	defaultWriterRetries       = 3
+)
+
+// ErrWriterClosed is reported for items written after DatasetWriter.Close.
+var ErrWriterClosed = scerrors.New(scerrors.KindInvalidArgument, "dataset writer is closed")
+
+// DatasetWriterOptions configures a DatasetWriter.
+type DatasetWriterOptions struct {
+	BatchItems    int           // Items that trigger a flush
+	BatchBytes    int           // JSON size of the buffered items that triggers a flush
+	FlushInterval time.Duration // Longest time an item waits in the buffer
+	Retries       int           // Attempts made after the first failure of a batch
+	RetryBackoff  time.Duration // Delay before the first retry, doubled for every further one
+	// OnDrop receives the items that were not written: batches out of
+	// retries, items that cannot be encoded and writes after Close.
+	OnDrop func(items []map[string]any, err error)
+}
+
+// DatasetWriterOption configures a DatasetWriter.
+type DatasetWriterOption func(*DatasetWriterOptions)
+
+// WithBatchItems flushes the buffer once it holds n items.
+func WithBatchItems(n int) DatasetWriterOption {
+	return func(o *DatasetWriterOptions) { o.BatchItems = n }
+}
+
+// WithBatchBytes flushes the buffer once its items weigh n bytes as JSON.
+func WithBatchBytes(n int) DatasetWriterOption {
+	return func(o *DatasetWriterOptions) { o.BatchBytes = n }
+}
+
+// WithFlushInterval flushes the buffer at least every d.
+func WithFlushInterval(d time.Duration) DatasetWriterOption {
+	return func(o *DatasetWriterOptions) { o.FlushInterval = d }
+}
+
+// WithWriteRetries retries a failed batch up to n times, waiting backoff
+// before the first retry and twice as long before each next one. Only
+// errors that may be transient are retried.
+func WithWriteRetries(n int, backoff time.Duration) DatasetWriterOption {
+	return func(o *DatasetWriterOptions) {
+		o.Retries = n
+		o.RetryBackoff = backoff
+	}
+}
+
+// WithDropHandler registers a callback receiving the items that could not be
+// written, with the reason.
+func WithDropHandler(fn func(items []map[string]any, err error)) DatasetWriterOption {
+	return func(o *DatasetWriterOptions) { o.OnDrop = fn }
+}
+
+func newDatasetWriterOptions(opts []DatasetWriterOption) DatasetWriterOptions {
+	o := DatasetWriterOptions{
+		BatchItems:    defaultWriterBatchItems,
+		BatchBytes:    defaultWriterBatchBytes,
+		FlushInterval: defaultWriterFlushInterval,
+		Retries:       defaultWriterRetries,
+		RetryBackoff:  defaultRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.BatchItems < 1 {
+		o.BatchItems = defaultWriterBatchItems
+	}
+	if o.BatchBytes < 1 {
+		o.BatchBytes = defaultWriterBatchBytes
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = defaultWriterFlushInterval
+	}
+	o.Retries = max(o.Retries, 0)
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = defaultRetryBackoff
+	}
+	return o
+}
+
+// DatasetWriterStats counts the items handled by a DatasetWriter.
+type DatasetWriterStats struct {
+	Written int64 // Items added to the dataset
+	Batches int64 // Batches the dataset accepted
+	Retries int64 // Writes repeated after a failure
+	Dropped int64 // Items handed to the drop handler
+}
+
+type writerBatch struct {
+	items []map[string]any
+	done  chan error // Receives the outcome when a caller waits for it
+}
+
+// DatasetWriter buffers items and adds them to a dataset in batches, sent
+// in order by a single goroutine. A batch is sent when it reaches the item
+// or byte limit and at every flush interval. Write blocks while a full
+// batch waits for the previous one, which bounds the memory in use.
+//
+// Close must be called to send the last items.
+type DatasetWriter struct {
+	dataset   *Dataset
+	datasetId string
+	opts      DatasetWriterOptions
+
+	mu      sync.Mutex
+	items   []map[string]any
+	size    int
+	closed  bool
+	batches chan writerBatch
+	done    chan struct{}
+
+	written, sent, retries, dropped atomic.Int64
+}
+
+// NewWriter creates a buffered writer adding items to datasetId.
+// Parameters:
+//
+//	datasetId: The dataset receiving the items.
+//	opts: Batch limits, flush interval, retries and drop handler.
+func (s *Dataset) NewWriter(datasetId string, opts ...DatasetWriterOption) *DatasetWriter {
+	w := &DatasetWriter{
+		dataset:   s,
+		datasetId: datasetId,
+		opts:      newDatasetWriterOptions(opts),
+		batches:   make(chan writerBatch, 1),
+		done:      make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write buffers an item. It returns ErrWriterClosed after Close and an
+// error when the item cannot be encoded; both are also reported to the drop
+// handler. Failures to send a batch are only reported to the drop handler.
+func (w *DatasetWriter) Write(item map[string]any) error {
+	encoded, err := json.Marshal(item)
+	if err != nil {
+		err = scerrors.Newf(scerrors.KindInvalidArgument, "encode dataset item: %v", err)
+		w.drop([]map[string]any{item}, err)
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		w.drop([]map[string]any{item}, ErrWriterClosed)
+		return ErrWriterClosed
+	}
+	if len(w.items) > 0 && w.size+len(encoded) > w.opts.BatchBytes {
+		w.sendLocked(nil)
+	}
+	w.items = append(w.items, item)
+	w.size += len(encoded)
+	if len(w.items) >= w.opts.BatchItems || w.size >= w.opts.BatchBytes {
+		w.sendLocked(nil)
+	}
+	return nil
+}
+
+// Flush sends the buffered items and waits until every batch written so far
+// has been sent or dropped. It returns the errors of the batches dropped in
+// the meantime.
+func (w *DatasetWriter) Flush(ctx context.Context) error {
+	done := make(chan error, 1)
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrWriterClosed
+	}
+	w.sendLocked(done)
+	w.mu.Unlock()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return scerrors.From(ctx.Err())
+	}
+}
+
+// Close sends the buffered items, waits for every batch and stops the
+// writer. It returns the errors of the batches dropped since the last Flush.
+func (w *DatasetWriter) Close() error {
+	done := make(chan error, 1)
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		<-w.done
+		return nil
+	}
+	w.sendLocked(done)
+	w.closed = true
+	close(w.batches)
+	w.mu.Unlock()
+
+	err := <-done
+	<-w.done
+	return err
+}
+
+// Stats returns the counters of the writer.
+func (w *DatasetWriter) Stats() DatasetWriterStats {
+	return DatasetWriterStats{
+		Written: w.written.Load(),
+		Batches: w.sent.Load(),
+		Retries: w.retries.Load(),
+		Dropped: w.dropped.Load(),
+	}
+}
+
+// sendLocked hands the buffered items to the sending goroutine, blocking
+// while the previous batch is still queued. The caller holds w.mu.
+func (w *DatasetWriter) sendLocked(done chan error) {
+	if len(w.items) == 0 && done == nil {
+		return
+	}
+	w.batches <- writerBatch{items: w.items, done: done}
+	w.items, w.size = nil, 0
+}
+
+func (w *DatasetWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var errs []error // Failures since the last batch somebody waited for
+	for {
+		select {
+		case batch, ok := <-w.batches:
+			if !ok {
+				return
+			}
+			if err := w.send(batch.items); err != nil {
+				errs = append(errs, err)
+			}
+			if batch.done != nil {
+				batch.done <- errors.Join(errs...)
+				errs = nil
+			}
+			ticker.Reset(w.opts.FlushInterval)
+		case <-ticker.C:
+			// A writer holding the lock may be blocked queueing a batch for
+			// this goroutine, in which case there is nothing to wait for.
+			if !w.mu.TryLock() {
+				continue
+			}
+			if !w.closed && len(w.items) > 0 {
+				// The channel is drained by this goroutine only, so take the
+				// items rather than queueing them.
+				items := w.items
+				w.items, w.size = nil, 0
+				w.mu.Unlock()
+				if err := w.send(items); err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			w.mu.Unlock()
+		}
+	}
+}
+
+// send adds items to the dataset, retrying transient failures.
+func (w *DatasetWriter) send(items []map[string]any) error {
+	ctx := context.Background()
+	// Validate once: the retries below resend the same valid items and must
+	// neither count them again in the report nor quarantine them twice.
+	items = w.dataset.validate(ctx, w.datasetId, items)
+	if len(items) == 0 {
+		return nil
+	}
+	backoff := w.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		ok, err := w.dataset.addValidated(ctx, w.datasetId, items)
+		if err == nil && !ok {
+			err = scerrors.Newf(scerrors.KindInternal, "dataset %s did not accept the items", w.datasetId)
+		}
+		if err == nil {
+			w.written.Add(int64(len(items)))
+			w.sent.Add(1)
+			return nil
+		}
+		if attempt == w.opts.Retries || !scerrors.IsRetryable(err) {
+			log.Warnf("dataset writer: dropping %d items of %s: %v", len(items), w.datasetId, err)
+			w.drop(items, err)
+			return err
+		}
+		w.retries.Add(1)
+		time.Sleep(backoff/2 + rand.N(backoff/2+1))
+		backoff = min(backoff*2, defaultMaxRetryBackoff)
+	}
+}
+
+func (w *DatasetWriter) drop(items []map[string]any, err error) {
+	w.dropped.Add(int64(len(items)))
+	if w.opts.OnDrop != nil {
+		w.opts.OnDrop(items, err)
+	}
+}
@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/schema"
+)
+
+// recordingDataset records the batches it is sent and fails the first ones,
+// of failId only when set.
+type recordingDataset struct {
+	storage.Dataset
+	mu      sync.Mutex
+	batches [][]map[string]any
+	failN   int
+	failId  string
+	failErr error
+}
+
+func (d *recordingDataset) AddDatasetItem(ctx context.Context, datasetId string, data []map[string]any) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.failN > 0 && (d.failId == "" || d.failId == datasetId) {
+		d.failN--
+		return false, d.failErr
+	}
+	d.batches = append(d.batches, data)
+	return true, nil
+}
+
+func (d *recordingDataset) sizes() []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var sizes []int
+	for _, b := range d.batches {
+		sizes = append(sizes, len(b))
+	}
+	return sizes
+}
+
+func TestDatasetWriterBatches(t *testing.T) {
+	rec := &recordingDataset{}
+	w := (&Dataset{client: rec}).NewWriter("ds", WithBatchItems(3), WithFlushInterval(time.Hour))
+	for i := 0; i < 7; i++ {
+		if err := w.Write(map[string]any{"n": i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.sizes(); len(got) != 3 || got[0] != 3 || got[1] != 3 || got[2] != 1 {
+		t.Fatalf("batches %v", got)
+	}
+	if rec.batches[2][0]["n"] != 6 {
+		t.Fatalf("order: %v", rec.batches)
+	}
+
+	// A batch is also cut before it outgrows the byte limit.
+	w2 := (&Dataset{client: rec}).NewWriter("ds", WithBatchBytes(50), WithFlushInterval(time.Hour))
+	for i := 0; i < 4; i++ {
+		_ = w2.Write(map[string]any{"text": "0123456789"}) // 21 bytes
+	}
+	if err := w2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.sizes()[3:]; len(got) != 2 || got[0] != 2 || got[1] != 2 {
+		t.Fatalf("batches by size %v", got)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(map[string]any{}); !errors.Is(err, ErrWriterClosed) {
+		t.Fatalf("write after close: %v", err)
+	}
+	if stats := w.Stats(); stats.Written != 7 || stats.Batches != 3 || stats.Dropped != 1 {
+		t.Fatalf("stats %+v", stats)
+	}
+}
+
+func TestDatasetWriterInterval(t *testing.T) {
+	rec := &recordingDataset{}
+	w := (&Dataset{client: rec}).NewWriter("ds", WithFlushInterval(20*time.Millisecond))
+	defer w.Close()
+	_ = w.Write(map[string]any{"n": 1})
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the buffer was not flushed by the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDatasetWriterRetries(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		dropped []map[string]any
+	)
+	onDrop := func(items []map[string]any, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dropped = append(dropped, items...)
+	}
+
+	rec := &recordingDataset{failN: 2, failErr: scerrors.New(scerrors.KindUnavailable, "busy")}
+	w := (&Dataset{client: rec}).NewWriter("ds", WithWriteRetries(2, time.Millisecond), WithDropHandler(onDrop))
+	_ = w.Write(map[string]any{"n": 1})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := w.Stats(); stats.Written != 1 || stats.Retries != 2 {
+		t.Fatalf("stats %+v", stats)
+	}
+
+	rec = &recordingDataset{failN: 1, failErr: scerrors.New(scerrors.KindInvalidArgument, "bad item")}
+	w = (&Dataset{client: rec}).NewWriter("ds", WithWriteRetries(5, time.Millisecond), WithDropHandler(onDrop))
+	_ = w.Write(map[string]any{"n": 2})
+	if err := w.Write(map[string]any{"bad": math.Inf(1)}); err == nil {
+		t.Fatal("unencodable item accepted")
+	}
+	if err := w.Flush(context.Background()); !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("flush: %v", err)
+	}
+	_ = w.Close()
+	if len(dropped) != 2 || w.Stats().Retries != 0 {
+		t.Fatalf("dropped %v, stats %+v", dropped, w.Stats())
+	}
+}
+
+func TestDatasetWriterValidatesOnce(t *testing.T) {
+	sch, err := schema.For(listing{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recordingDataset{failN: 2, failId: "ds", failErr: scerrors.New(scerrors.KindUnavailable, "busy")}
+	dataset := &Dataset{client: rec}
+	if err = dataset.SetSchema("ds", sch, WithQuarantine("bad")); err != nil {
+		t.Fatal(err)
+	}
+	w := dataset.NewWriter("ds", WithWriteRetries(2, time.Millisecond))
+	for _, item := range listingItems {
+		_ = w.Write(item)
+	}
+	// Every item of this batch is rejected, none is written.
+	w2 := dataset.NewWriter("ds")
+	_ = w2.Write(map[string]any{"price": -1})
+	if err = errors.Join(w.Close(), w2.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The valid item once and the three invalid ones quarantined once.
+	if got := rec.sizes(); len(got) != 3 || got[0]+got[1]+got[2] != 5 {
+		t.Fatalf("batches %v", rec.batches)
+	}
+	report, _ := dataset.ValidationReport("ds")
+	if report.Checked != 5 || report.Valid != 1 || report.Quarantined != 4 {
+		t.Fatalf("report %+v", report)
+	}
+	if stats := w.Stats(); stats.Written != 1 || stats.Retries != 2 {
+		t.Fatalf("stats %+v", stats)
+	}
+	if stats := w2.Stats(); stats.Written != 0 || stats.Batches != 0 {
+		t.Fatalf("stats of the rejected batch %+v", stats)
+	}
+}
+
+func TestDatasetWriterLocal(t *testing.T) {
+	dataset, id := localDataset(t, nil)
+	w := dataset.NewWriter(id, WithBatchItems(10))
+	for i := 0; i < 25; i++ {
+		_ = w.Write(map[string]any{"n": i})
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := dataset.GetItems(context.Background(), id, 1, 100, false)
+	if err != nil || resp.Total != 25 {
+		t.Fatalf("items: %+v, %v", resp, err)
+	}
+}
@@ -0,0 +1,378 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"iter"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
+)
+
+// ExportFormat is a file format Dataset.Export can write.
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportJSONL   ExportFormat = "jsonl"
+	ExportParquet ExportFormat = "parquet"
+	ExportXLSX    ExportFormat = "xlsx"
+)
+
+const (
+	defaultExportSeparator = "."
+	defaultExportPageSize  = 100
+)
+
+// ExportOptions controls how a dataset is exported.
+type ExportOptions struct {
+	// Fields selects the columns to write, in order. Nested values are named
+	// by joining their path with Separator, e.g. "price.amount". When empty
+	// the columns are inferred from the items.
+	Fields    []string
+	Separator string // Joins the keys of nested objects, "." by default
+	Desc      bool   // Export the items in descending order
+	PageSize  int64  // Items fetched per request
+}
+
+// ExportOption configures Dataset.Export.
+type ExportOption func(*ExportOptions)
+
+// WithFields selects the columns to export and their order.
+func WithFields(fields ...string) ExportOption {
+	return func(o *ExportOptions) { o.Fields = fields }
+}
+
+// WithSeparator sets the separator used to flatten nested objects.
+func WithSeparator(sep string) ExportOption {
+	return func(o *ExportOptions) { o.Separator = sep }
+}
+
+// WithDescending exports the items in descending order.
+func WithDescending(desc bool) ExportOption {
+	return func(o *ExportOptions) { o.Desc = desc }
+}
+
+// WithExportPageSize sets the number of items fetched per request.
+func WithExportPageSize(n int64) ExportOption {
+	return func(o *ExportOptions) { o.PageSize = n }
+}
+
+// Export streams all the items of a dataset to w. Nested objects are
+// flattened into columns for every format but JSONL, which writes the items
+// as they are unless fields are selected. Arrays are written as JSON.
+//
+// Unless WithFields is given, the columns are the union of the keys of all
+// items, so the dataset is read twice: once to infer the columns and once
+// to write them. Parquet always needs that first pass to type its columns.
+// Parameters:
+//
+//	ctx: The request context.
+//	datasetId: The dataset to export.
+//	format: ExportCSV, ExportJSONL, ExportParquet or ExportXLSX.
+//	w: Destination of the file.
+//	opts: Field selection, separator, order and page size.
+func (s *Dataset) Export(ctx context.Context, datasetId string, format ExportFormat, w io.Writer, opts ...ExportOption) (rows int64, err error) {
+	o := ExportOptions{Separator: defaultExportSeparator, PageSize: defaultExportPageSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Separator == "" {
+		o.Separator = defaultExportSeparator
+	}
+	switch format {
+	case ExportCSV, ExportJSONL, ExportParquet, ExportXLSX:
+	default:
+		return 0, scerrors.Newf(scerrors.KindInvalidArgument, "unknown export format %q", format)
+	}
+	items := func() iter.Seq2[map[string]any, error] {
+		return s.IterItems(ctx, datasetId, o.Desc, pager.WithPageSize(o.PageSize), pager.WithPrefetch(1))
+	}
+
+	var cols []column
+	switch {
+	case format == ExportJSONL && len(o.Fields) == 0:
+		return exportRawJSONL(items(), w)
+	case format == ExportParquet || len(o.Fields) == 0:
+		if cols, err = inferColumns(items(), o.Separator, o.Fields); err != nil {
+			return 0, err
+		}
+	default:
+		for _, name := range o.Fields {
+			cols = append(cols, column{Name: name, Type: colString})
+		}
+	}
+
+	var out rowWriter
+	switch format {
+	case ExportCSV:
+		out, err = newCSVWriter(w, cols)
+	case ExportJSONL:
+		out, err = newJSONLWriter(w, cols), nil
+	case ExportParquet:
+		out, err = newParquetWriter(w, cols)
+	case ExportXLSX:
+		out, err = newXLSXWriter(w, cols)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	row := make([]any, len(cols))
+	for item, err := range items() {
+		if err != nil {
+			return rows, err
+		}
+		flat := flatten(item, o.Separator)
+		for i, col := range cols {
+			row[i] = flat[col.Name]
+		}
+		if err = out.WriteRow(row); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	return rows, out.Close()
+}
+
+// rowWriter writes the rows of an export. Row values are nil, bool, string,
+// float64, json.Number or JSON values such as arrays.
+type rowWriter interface {
+	WriteRow(row []any) error
+	Close() error
+}
+
+type columnType int
+
+const (
+	colNull columnType = iota // Only null values seen so far
+	colBool
+	colInt
+	colFloat
+	colString
+)
+
+type column struct {
+	Name string
+	Type columnType
+}
+
+// inferColumns reads every item and returns the columns in the order they
+// first appear, typed with the narrowest type holding all their values.
+// With fields, only those columns are typed and their order is kept.
+func inferColumns(items iter.Seq2[map[string]any, error], sep string, fields []string) ([]column, error) {
+	var cols []column
+	index := map[string]int{}
+	for _, name := range fields {
+		index[name] = len(cols)
+		cols = append(cols, column{Name: name})
+	}
+	for item, err := range items {
+		if err != nil {
+			return nil, err
+		}
+		flat := flatten(item, sep)
+		keys := make([]string, 0, len(flat))
+		for key := range flat {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			i, ok := index[key]
+			if !ok {
+				if len(fields) > 0 {
+					continue
+				}
+				i = len(cols)
+				index[key] = i
+				cols = append(cols, column{Name: key})
+			}
+			cols[i].Type = mergeType(cols[i].Type, typeOf(flat[key]))
+		}
+	}
+	typed := cols[:0]
+	for _, col := range cols {
+		if col.Type == colNull {
+			// An object that is null in some items only names its children.
+			if len(fields) == 0 && hasChildren(index, col.Name+sep) {
+				continue
+			}
+			col.Type = colString
+		}
+		typed = append(typed, col)
+	}
+	return typed, nil
+}
+
+func hasChildren(index map[string]int, prefix string) bool {
+	for name := range index {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(v any) columnType {
+	switch v := v.(type) {
+	case nil:
+		return colNull
+	case bool:
+		return colBool
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return colInt
+		}
+		return colFloat
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return colInt
+		}
+		return colFloat
+	}
+	return colString
+}
+
+func mergeType(a, b columnType) columnType {
+	switch {
+	case a == colNull:
+		return b
+	case b == colNull || a == b:
+		return a
+	case (a == colInt || a == colFloat) && (b == colInt || b == colFloat):
+		return colFloat
+	}
+	return colString
+}
+
+// flatten returns the leaves of item keyed by their path joined with sep.
+// Arrays are leaves.
+func flatten(item map[string]any, sep string) map[string]any {
+	flat := make(map[string]any, len(item))
+	var walk func(prefix string, m map[string]any)
+	walk = func(prefix string, m map[string]any) {
+		for key, v := range m {
+			if prefix != "" {
+				key = prefix + sep + key
+			}
+			if nested, ok := v.(map[string]any); ok {
+				walk(key, nested)
+				continue
+			}
+			flat[key] = v
+		}
+	}
+	walk("", item)
+	return flat
+}
+
+// formatValue renders a value as text, without quotes for strings.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(buf)
+}
+
+func exportRawJSONL(items iter.Seq2[map[string]any, error], w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	var rows int64
+	for item, err := range items {
+		if err != nil {
+			return rows, err
+		}
+		if err = enc.Encode(item); err != nil {
+			return rows, scerrors.From(err)
+		}
+		rows++
+	}
+	return rows, bw.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, cols []column) (*csvWriter, error) {
+	c := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(cols))}
+	for i, col := range cols {
+		c.record[i] = col.Name
+	}
+	return c, c.w.Write(c.record)
+}
+
+func (c *csvWriter) WriteRow(row []any) error {
+	for i, v := range row {
+		c.record[i] = formatValue(v)
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlWriter writes each row as an object keeping the column order.
+type jsonlWriter struct {
+	w     *bufio.Writer
+	names [][]byte
+	buf   bytes.Buffer
+}
+
+func newJSONLWriter(w io.Writer, cols []column) *jsonlWriter {
+	j := &jsonlWriter{w: bufio.NewWriter(w)}
+	for _, col := range cols {
+		name, _ := json.Marshal(col.Name)
+		j.names = append(j.names, name)
+	}
+	return j
+}
+
+func (j *jsonlWriter) WriteRow(row []any) error {
+	j.buf.Reset()
+	j.buf.WriteByte('{')
+	for i, v := range row {
+		if i > 0 {
+			j.buf.WriteByte(',')
+		}
+		j.buf.Write(j.names[i])
+		j.buf.WriteByte(':')
+		value, err := json.Marshal(v)
+		if err != nil {
+			return scerrors.From(err)
+		}
+		j.buf.Write(value)
+	}
+	j.buf.WriteString("}\n")
+	_, err := j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.w.Flush()
+}
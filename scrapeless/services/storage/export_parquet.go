@@ -0,0 +1,355 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+// The Parquet writer produces the simplest valid files: flat optional
+// columns, uncompressed PLAIN pages, one page per column chunk. Rows are
+// buffered until parquetRowGroupSize of them make a row group.
+
+const (
+	parquetMagic        = "PAR1"
+	parquetRowGroupSize = 10000
+	parquetCreatedBy    = "scrapeless sdk-go"
+)
+
+// Parquet physical types, encodings and enums of the format.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetOptional = 1
+	parquetUTF8     = 0
+	parquetDataPage = 0
+)
+
+type parquetChunk struct {
+	offset    int64
+	size      int64
+	numValues int64
+}
+
+type parquetRowGroup struct {
+	chunks []parquetChunk
+	rows   int64
+	size   int64
+}
+
+type parquetWriter struct {
+	w      io.Writer
+	offset int64
+	cols   []column
+	values [][]any // Values of the row group being filled, by column
+	groups []parquetRowGroup
+	rows   int64
+}
+
+func newParquetWriter(w io.Writer, cols []column) (*parquetWriter, error) {
+	p := &parquetWriter{w: w, cols: cols, values: make([][]any, len(cols))}
+	return p, p.write([]byte(parquetMagic))
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	if err != nil {
+		return scerrors.From(err)
+	}
+	return nil
+}
+
+func (p *parquetWriter) WriteRow(row []any) error {
+	if len(p.cols) == 0 {
+		return nil
+	}
+	for i, v := range row {
+		p.values[i] = append(p.values[i], parquetValue(p.cols[i].Type, v))
+	}
+	if len(p.values[0]) >= parquetRowGroupSize {
+		return p.flush()
+	}
+	return nil
+}
+
+// parquetValue converts v to the Go type of the column, nil for nulls.
+func parquetValue(t columnType, v any) any {
+	if v == nil {
+		return nil
+	}
+	switch t {
+	case colBool:
+		return v.(bool)
+	case colInt:
+		switch v := v.(type) {
+		case float64:
+			return int64(v)
+		case json.Number:
+			n, _ := v.Int64()
+			return n
+		}
+	case colFloat:
+		switch v := v.(type) {
+		case float64:
+			return v
+		case json.Number:
+			f, _ := v.Float64()
+			return f
+		}
+	}
+	return formatValue(v)
+}
+
+// flush writes the buffered rows as a row group.
+func (p *parquetWriter) flush() error {
+	if len(p.cols) == 0 || len(p.values[0]) == 0 {
+		return nil
+	}
+	group := parquetRowGroup{rows: int64(len(p.values[0]))}
+	for i, col := range p.cols {
+		chunk, err := p.writeChunk(col, p.values[i])
+		if err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, chunk)
+		group.size += chunk.size
+		p.values[i] = p.values[i][:0]
+	}
+	p.groups = append(p.groups, group)
+	p.rows += group.rows
+	return nil
+}
+
+func (p *parquetWriter) writeChunk(col column, values []any) (parquetChunk, error) {
+	var page bytes.Buffer
+	levels := parquetLevels(values)
+	_ = binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+	page.Write(levels)
+
+	var bits, nbits byte
+	for _, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case bool:
+			if v {
+				bits |= 1 << nbits
+			}
+			if nbits++; nbits == 8 {
+				page.WriteByte(bits)
+				bits, nbits = 0, 0
+			}
+		case int64:
+			_ = binary.Write(&page, binary.LittleEndian, v)
+		case float64:
+			_ = binary.Write(&page, binary.LittleEndian, math.Float64bits(v))
+		case string:
+			_ = binary.Write(&page, binary.LittleEndian, uint32(len(v)))
+			page.WriteString(v)
+		}
+	}
+	if nbits > 0 {
+		page.WriteByte(bits)
+	}
+
+	var header thriftWriter
+	header.i32(1, parquetDataPage)
+	header.i32(2, int32(page.Len()))
+	header.i32(3, int32(page.Len()))
+	header.beginStruct(5)
+	header.i32(1, int32(len(values)))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRLE)
+	header.i32(4, parquetRLE)
+	header.endStruct()
+	header.stop()
+
+	chunk := parquetChunk{offset: p.offset, numValues: int64(len(values))}
+	if err := p.write(header.buf.Bytes()); err != nil {
+		return chunk, err
+	}
+	if err := p.write(page.Bytes()); err != nil {
+		return chunk, err
+	}
+	chunk.size = p.offset - chunk.offset
+	return chunk, nil
+}
+
+// parquetLevels encodes the definition levels of values, 1 for a value and
+// 0 for a null, as a single bit-packed run of the RLE hybrid encoding.
+func parquetLevels(values []any) []byte {
+	groups := (len(values) + 7) / 8
+	out := binary.AppendUvarint(nil, uint64(groups)<<1|1)
+	packed := make([]byte, groups)
+	for i, v := range values {
+		if v != nil {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return append(out, packed...)
+}
+
+func (p *parquetWriter) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	var meta thriftWriter
+	meta.i32(1, 1)
+	meta.beginList(2, thriftStruct, len(p.cols)+1)
+	meta.beginElem()
+	meta.binary(4, "schema")
+	meta.i32(5, int32(len(p.cols)))
+	meta.endStruct()
+	for _, col := range p.cols {
+		meta.beginElem()
+		meta.i32(1, parquetType(col.Type))
+		meta.i32(3, parquetOptional)
+		meta.binary(4, col.Name)
+		if col.Type == colString {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.endStruct()
+	}
+	meta.i64(3, p.rows)
+	meta.beginList(4, thriftStruct, len(p.groups))
+	for _, group := range p.groups {
+		meta.beginElem()
+		meta.beginList(1, thriftStruct, len(group.chunks))
+		for i, chunk := range group.chunks {
+			meta.beginElem()
+			meta.i64(2, chunk.offset)
+			meta.beginStruct(3)
+			meta.i32(1, parquetType(p.cols[i].Type))
+			meta.beginList(2, thriftI32, 2)
+			meta.elemI32(parquetPlain)
+			meta.elemI32(parquetRLE)
+			meta.beginList(3, thriftBinary, 1)
+			meta.elemBinary(p.cols[i].Name)
+			meta.i32(4, 0) // Uncompressed
+			meta.i64(5, chunk.numValues)
+			meta.i64(6, chunk.size)
+			meta.i64(7, chunk.size)
+			meta.i64(9, chunk.offset)
+			meta.endStruct()
+			meta.endStruct()
+		}
+		meta.i64(2, group.size)
+		meta.i64(3, group.rows)
+		meta.endStruct()
+	}
+	meta.binary(6, parquetCreatedBy)
+	meta.stop()
+
+	footer := meta.buf.Bytes()
+	footer = binary.LittleEndian.AppendUint32(footer, uint32(len(footer)))
+	return p.write(append(footer, parquetMagic...))
+}
+
+func parquetType(t columnType) int32 {
+	switch t {
+	case colBool:
+		return parquetBoolean
+	case colInt:
+		return parquetInt64
+	case colFloat:
+		return parquetDouble
+	}
+	return parquetByteArray
+}
+
+// Types of the Thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the structures of the Parquet metadata with the
+// Thrift compact protocol.
+type thriftWriter struct {
+	buf   bytes.Buffer
+	last  int16   // Id of the previous field of the current struct
+	stack []int16 // Ids of the previous fields of the enclosing structs
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.varint(int64(id))
+	}
+	t.last = id
+}
+
+// varint writes a zigzag varint.
+func (t *thriftWriter) varint(v int64) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(v<<1^v>>63)))
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(v)
+}
+
+func (t *thriftWriter) binary(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.elemBinary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginElem()
+}
+
+// beginElem starts a struct inside a list.
+func (t *thriftWriter) beginElem() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *thriftWriter) stop() {
+	t.buf.WriteByte(0)
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elem)
+		return
+	}
+	t.buf.WriteByte(0xF0 | elem)
+	t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+func (t *thriftWriter) elemI32(v int32) {
+	t.varint(int64(v))
+}
+
+func (t *thriftWriter) elemBinary(s string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(s))))
+	t.buf.WriteString(s)
+}
@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func localDataset(t *testing.T, items []map[string]any) (*Dataset, string) {
+	t.Helper()
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	id, _, err := s.Dataset.CreateDataset(ctx, "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = s.Dataset.AddItems(ctx, id, items); err != nil {
+		t.Fatal(err)
+	}
+	return s.Dataset, id
+}
+
+var exportItems = []map[string]any{
+	{"title": "lamp", "price": map[string]any{"amount": 12.5, "currency": "EUR"}, "stock": 3},
+	{"title": "desk", "price": map[string]any{"amount": 120}, "tags": []any{"office", "wood"}, "sold": true},
+	{"title": "chair, \"oak\"", "stock": nil, "price": map[string]any{"amount": 40, "currency": "USD"}},
+}
+
+func TestExportCSV(t *testing.T) {
+	dataset, id := localDataset(t, exportItems)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	rows, err := dataset.Export(ctx, id, ExportCSV, &buf, WithSeparator("_"), WithExportPageSize(2))
+	if err != nil || rows != 3 {
+		t.Fatalf("export: %d, %v", rows, err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"price_amount", "price_currency", "stock", "title", "sold", "tags"},
+		{"12.5", "EUR", "3", "lamp", "", ""},
+		{"120", "", "", "desk", "true", `["office","wood"]`},
+		{"40", "USD", "", `chair, "oak"`, "", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %q", records)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Fatalf("row %d: got %q, want %q", i, records[i], want[i])
+		}
+	}
+
+	buf.Reset()
+	if _, err = dataset.Export(ctx, id, ExportCSV, &buf, WithFields("title", "price.amount"), WithDescending(true)); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "title,price.amount\n\"chair, \"\"oak\"\"\",40\ndesk,120\nlamp,12.5\n" {
+		t.Fatalf("selected fields: %q", got)
+	}
+
+	if _, err = dataset.Export(ctx, id, "xml", io.Discard); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
+
+func TestExportJSONL(t *testing.T) {
+	dataset, id := localDataset(t, exportItems)
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	if _, err := dataset.Export(ctx, id, ExportJSONL, &buf); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != `{"price":{"amount":12.5,"currency":"EUR"},"stock":3,"title":"lamp"}` {
+		t.Fatalf("raw items: %q", lines)
+	}
+
+	buf.Reset()
+	if _, err := dataset.Export(ctx, id, ExportJSONL, &buf, WithFields("title", "price.currency")); err != nil {
+		t.Fatal(err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[1] != `{"title":"desk","price.currency":null}` {
+		t.Fatalf("selected fields: %q", lines)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	dataset, id := localDataset(t, exportItems)
+
+	var buf bytes.Buffer
+	if _, err := dataset.Export(context.Background(), id, ExportXLSX, &buf, WithFields("title", "price.amount", "sold")); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sheet string
+	for _, f := range zr.File {
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			r, _ := f.Open()
+			b, _ := io.ReadAll(r)
+			sheet = string(b)
+		}
+	}
+	for _, cell := range []string{
+		`<c r="B1" t="inlineStr"><is><t xml:space="preserve">price.amount</t></is></c>`,
+		`<c r="B2"><v>12.5</v></c>`,
+		`<c r="C3" t="b"><v>1</v></c>`,
+		`<t xml:space="preserve">chair, &#34;oak&#34;</t>`,
+	} {
+		if !strings.Contains(sheet, cell) {
+			t.Fatalf("%s not in sheet:\n%s", cell, sheet)
+		}
+	}
+	if columnLetters(0) != "A" || columnLetters(25) != "Z" || columnLetters(26) != "AA" || columnLetters(702) != "AAA" {
+		t.Fatal("column letters")
+	}
+}
+
+func TestExportParquet(t *testing.T) {
+	dataset, id := localDataset(t, exportItems)
+
+	var buf bytes.Buffer
+	if _, err := dataset.Export(context.Background(), id, ExportParquet, &buf); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	if !bytes.HasPrefix(b, []byte(parquetMagic)) || !bytes.HasSuffix(b, []byte(parquetMagic)) {
+		t.Fatal("missing magic")
+	}
+	footer := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	meta := (&thriftReader{b: b[len(b)-8-footer : len(b)-8]}).readStruct()
+	if meta[1] != int64(1) || meta[3] != int64(3) || meta[6] != parquetCreatedBy {
+		t.Fatalf("file metadata %v", meta)
+	}
+	schema := meta[2].([]any)
+	if root := schema[0].(map[int16]any); root[4] != "schema" || root[5] != int64(6) {
+		t.Fatalf("schema root %v", root)
+	}
+	types := map[string]int64{}
+	for _, elem := range schema[1:] {
+		col := elem.(map[int16]any)
+		if col[3] != int64(parquetOptional) {
+			t.Fatalf("column %v is not optional", col)
+		}
+		types[col[4].(string)] = col[1].(int64)
+	}
+	wantTypes := map[string]int64{
+		"price.amount": parquetDouble, "price.currency": parquetByteArray, "stock": parquetInt64,
+		"title": parquetByteArray, "sold": parquetBoolean, "tags": parquetByteArray,
+	}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("column types %v", types)
+	}
+
+	// Read every column chunk back from its data page.
+	groups := meta[4].([]any)
+	if len(groups) != 1 {
+		t.Fatalf("%d row groups", len(groups))
+	}
+	got := map[string][]any{}
+	for _, elem := range groups[0].(map[int16]any)[1].([]any) {
+		chunk := elem.(map[int16]any)[3].(map[int16]any)
+		name := chunk[3].([]any)[0].(string)
+		got[name] = readParquetPage(t, b, chunk[9].(int64), chunk[1].(int64))
+	}
+	want := map[string][]any{
+		"price.amount":   {12.5, 120.0, 40.0},
+		"price.currency": {"EUR", nil, "USD"},
+		"stock":          {int64(3), nil, nil},
+		"title":          {"lamp", "desk", `chair, "oak"`},
+		"sold":           {nil, true, nil},
+		"tags":           {nil, `["office","wood"]`, nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("columns\n%v\nwant\n%v", got, want)
+	}
+
+	cols, err := inferColumns(dataset.IterItems(context.Background(), id, false), ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inferred := map[string]columnType{}
+	for _, col := range cols {
+		inferred[col.Name] = col.Type
+	}
+	if inferred["price.amount"] != colFloat || inferred["stock"] != colInt || inferred["sold"] != colBool || inferred["tags"] != colString {
+		t.Fatalf("inferred %+v", cols)
+	}
+}
+
+// thriftReader decodes the Thrift compact protocol of thriftWriter: structs
+// become maps by field id, lists slices and integers int64.
+type thriftReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	c := r.b[r.pos]
+	r.pos++
+	return c
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := map[int16]any{}
+	var last int16
+	for {
+		head := r.byte()
+		if head == 0 {
+			return fields
+		}
+		id := last + int16(head>>4)
+		if head>>4 == 0 {
+			id = int16(r.varint())
+		}
+		fields[id] = r.value(head & 0x0f)
+		last = id
+	}
+}
+
+func (r *thriftReader) value(typ byte) any {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.varint()
+	case thriftBinary:
+		n := int(r.uvarint())
+		r.pos += n
+		return string(r.b[r.pos-n : r.pos])
+	case thriftList:
+		head := r.byte()
+		size := int(head >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.value(head & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("thrift type %d", typ))
+}
+
+// readParquetPage decodes the data page at offset of a column chunk of type
+// typ, nil standing for the nulls.
+func readParquetPage(t *testing.T, file []byte, offset, typ int64) []any {
+	t.Helper()
+	r := &thriftReader{b: file, pos: int(offset)}
+	header := r.readStruct()
+	data := header[5].(map[int16]any)
+	if header[1] != int64(parquetDataPage) || data[2] != int64(parquetPlain) || data[3] != int64(parquetRLE) {
+		t.Fatalf("page header %v", header)
+	}
+	page := file[r.pos : r.pos+int(header[3].(int64))]
+	n := int(data[1].(int64))
+
+	// Definition levels: one bit-packed run of 1 for a value, 0 for a null.
+	levels := &thriftReader{b: page[4 : 4+binary.LittleEndian.Uint32(page)]}
+	if run := levels.uvarint(); run != uint64((n+7)/8)<<1|1 {
+		t.Fatalf("levels run header %d for %d values", run, n)
+	}
+	packed := levels.b[levels.pos:]
+	values := page[4+len(levels.b):]
+	out := make([]any, n)
+	var bit int
+	for i := range out {
+		if packed[i/8]>>(i%8)&1 == 0 {
+			continue
+		}
+		switch typ {
+		case parquetBoolean:
+			out[i] = values[bit/8]>>(bit%8)&1 == 1
+			bit++
+		case parquetInt64:
+			out[i] = int64(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetDouble:
+			out[i] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case parquetByteArray:
+			size := binary.LittleEndian.Uint32(values)
+			out[i] = string(values[4 : 4+size])
+			values = values[4+size:]
+		}
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_http"
+)
+
+// newLocalStorage returns a storage on the local backend, which keeps its
+// files in a temporary working directory of the test.
+func newLocalStorage(t *testing.T) *Storage {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	s := NewStorage("http")
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+// httpClient returns an API client whose requests are served by handler.
+func httpClient(t *testing.T, handler http.HandlerFunc) *storage_http.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client, err := storage_http.New(srv.URL, request.ClientConfig{ApiKey: "key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
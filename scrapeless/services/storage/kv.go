@@ -3,32 +3,36 @@ package storage
 import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
+	"iter"
 )
 
-type KV struct{}
+type KV struct {
+	client storage.KV
+}
 
 // ListNamespaces retrieves a list of KV namespaces with pagination and sorting options.
 // Parameters:
 //
 //	ctx: The request context.
 //	page: Page number (starting from 1). Defaults to 1 if <=0.
-//	pageSize:  Number of items per page. Minimum 10, defaults to 10 if smaller.
+//	pageSize:  Number of items per page. Defaults to 10 if <1.
 //	desc: Sort namespaces in descending order by creation time if true.
 func (s *KV) ListNamespaces(ctx context.Context, page int64, pageSize int64, desc bool) (*NamespacesResponse, error) {
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	keyResp, err := storage.ClientInterface.ListNamespaces(ctx, page, pageSize, desc)
+	keyResp, err := s.client.ListNamespaces(ctx, page, pageSize, desc)
 	if err != nil {
-		log.Errorf("failed to list kv namespaces: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to list kv namespaces: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var KvNamespaceItems []KvNamespaceItem
 	for _, item := range keyResp.Items {
@@ -59,14 +63,14 @@ func (s *KV) ListNamespaces(ctx context.Context, page int64, pageSize int64, des
 //	name: The name of the namespace to create.
 func (s *KV) CreateNamespace(ctx context.Context, name string) (namespaceId string, namespaceName string, err error) {
 	name = name + "-" + env.GetActorEnv().RunId
-	namespaceId, err = storage.ClientInterface.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{
+	namespaceId, err = s.client.CreateNamespace(ctx, &models.CreateKvNamespaceRequest{
 		Name:    name,
 		ActorId: env.GetActorEnv().ActorId,
 		RunId:   env.GetActorEnv().RunId,
 	})
 	if err != nil {
-		log.Errorf("failed to create kv namespace: %v", code.Format(err))
-		return "", "", code.Format(err)
+		log.Errorf("failed to create kv namespace: %v", err)
+		return "", "", scerrors.From(err)
 	}
 	return namespaceId, name, nil
 }
@@ -77,10 +81,10 @@ func (s *KV) CreateNamespace(ctx context.Context, name string) (namespaceId stri
 //	ctx: The request context.
 //	namespaceName: Name of the namespace to retrieve
 func (s *KV) GetNamespace(ctx context.Context, namespaceName string) (*KvNamespaceItem, error) {
-	namespace, err := storage.ClientInterface.GetNamespace(ctx, namespaceName)
+	namespace, err := s.client.GetNamespace(ctx, namespaceName)
 	if err != nil {
-		log.Errorf("failed to get kv namespace: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to get kv namespace: %v", err)
+		return nil, scerrors.From(err)
 	}
 	resp := &KvNamespaceItem{
 		Id:        namespace.Id,
@@ -102,10 +106,10 @@ func (s *KV) GetNamespace(ctx context.Context, namespaceName string) (*KvNamespa
 //
 //	ctx:The request context.
 func (s *KV) DelNamespace(ctx context.Context, namespaceId string) (bool, error) {
-	ok, err := storage.ClientInterface.DelNamespace(ctx, namespaceId)
+	ok, err := s.client.DelNamespace(ctx, namespaceId)
 	if err != nil {
-		log.Errorf("failed to delete kv namespace: %v", code.Format(err))
-		return false, code.Format(err)
+		log.Errorf("failed to delete kv namespace: %v", err)
+		return false, scerrors.From(err)
 	}
 	return ok, nil
 }
@@ -117,10 +121,10 @@ func (s *KV) DelNamespace(ctx context.Context, namespaceId string) (bool, error)
 //	name: New namespace name
 func (s *KV) RenameNamespace(ctx context.Context, namespaceId string, name string) (ok bool, namespaceName string, err error) {
 	name = name + "-" + env.GetActorEnv().RunId
-	ok, err = storage.ClientInterface.RenameNamespace(ctx, namespaceId, name)
+	ok, err = s.client.RenameNamespace(ctx, namespaceId, name)
 	if err != nil {
-		log.Errorf("failed to rename kv namespace: %v", code.Format(err))
-		return false, "", code.Format(err)
+		log.Errorf("failed to rename kv namespace: %v", err)
+		return false, "", scerrors.From(err)
 	}
 	return ok, name, nil
 }
@@ -130,22 +134,22 @@ func (s *KV) RenameNamespace(ctx context.Context, namespaceId string, name strin
 //
 //	ctx: Request context
 //	page: Page number (starting from 1). Defaults to 1 if <=0
-//	pageSize: Number of items per page. Minimum 10, defaults to 10 if smaller
+//	pageSize: Number of items per page. Defaults to 10 if <1
 func (s *KV) ListKeys(ctx context.Context, namespaceId string, page int64, pageSize int64) (*KvKeys, error) {
 	if page <= 0 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	keys, err := storage.ClientInterface.ListKeys(ctx, &models.ListKeyInfo{
+	keys, err := s.client.ListKeys(ctx, &models.ListKeyInfo{
 		NamespaceId: namespaceId,
 		Page:        page,
 		Size:        pageSize,
 	})
 	if err != nil {
-		log.Errorf("failed to list kv keys: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to list kv keys: %v", err)
+		return nil, scerrors.From(err)
 	}
 	if keys == nil {
 		return nil, nil
@@ -160,6 +164,87 @@ func (s *KV) ListKeys(ctx context.Context, namespaceId string, page int64, pageS
 	return kvKeys, nil
 }
 
+// IterKeys iterates over the keys of a namespace. Each item holds the
+// "key" and its "size".
+// Parameters:
+//
+//	ctx: The request context, cancelling it ends the iteration.
+//	namespaceId: The namespace to list.
+//	opts: Page size, prefetch and limit, see package pager.
+func (s *KV) IterKeys(ctx context.Context, namespaceId string, opts ...pager.Option) iter.Seq2[map[string]any, error] {
+	return pager.Seq(ctx, func(ctx context.Context, page, pageSize int64) ([]map[string]any, int64, error) {
+		resp, err := s.ListKeys(ctx, namespaceId, page, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Items, resp.Total, nil
+	}, opts...)
+}
+
+// ScanKeys returns a batch of the keys matching a glob pattern, with their
+// size and expiry. Patterns use '*', '?', '[a-z]' and '\' escapes, '*' also
+// matching ':' and '/', so "seen:example.com:*" selects the keys of a domain.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	match: Glob pattern; empty matches every key
+//	cursor: Cursor of the previous batch; empty to start the scan
+//	count: Number of keys wanted; a batch may hold fewer or more
+func (s *KV) ScanKeys(ctx context.Context, namespaceId, match, cursor string, count int64) (*ScanKeysResult, error) {
+	if count < 1 {
+		count = pager.DefaultPageSize
+	}
+	resp, err := s.client.ScanKeys(ctx, &models.ScanKeys{
+		NamespaceId: namespaceId,
+		Match:       match,
+		Cursor:      cursor,
+		Count:       count,
+	})
+	if err != nil {
+		log.Errorf("failed to scan kv keys: %v", err)
+		return nil, scerrors.From(err)
+	}
+	result := &ScanKeysResult{Cursor: resp.Cursor, Items: make([]KeyInfo, 0, len(resp.Items))}
+	for _, item := range resp.Items {
+		result.Items = append(result.Items, KeyInfo(item))
+	}
+	return result, nil
+}
+
+// IterScan iterates over the keys matching a glob pattern, see ScanKeys.
+// Parameters:
+//
+//	ctx: The request context, cancelling it ends the iteration.
+//	namespaceId: Identifier of the namespace
+//	match: Glob pattern; empty matches every key
+//	count: Number of keys fetched per call
+func (s *KV) IterScan(ctx context.Context, namespaceId, match string, count int64) iter.Seq2[KeyInfo, error] {
+	return func(yield func(KeyInfo, error) bool) {
+		cursor := ""
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(KeyInfo{}, scerrors.From(err))
+				return
+			}
+			resp, err := s.ScanKeys(ctx, namespaceId, match, cursor, count)
+			if err != nil {
+				yield(KeyInfo{}, err)
+				return
+			}
+			for _, item := range resp.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if resp.Cursor == "" {
+				return
+			}
+			cursor = resp.Cursor
+		}
+	}
+}
+
 // DelValue deletes the value associated with the specified key in the given namespace.
 // Parameters:
 //
@@ -167,10 +252,10 @@ func (s *KV) ListKeys(ctx context.Context, namespaceId string, page int64, pageS
 //	namespaceId: Identifier of the namespace
 //	key: The key to delete
 func (s *KV) DelValue(ctx context.Context, namespaceId string, key string) (bool, error) {
-	ok, err := storage.ClientInterface.DelValue(ctx, namespaceId, key)
+	ok, err := s.client.DelValue(ctx, namespaceId, key)
 	if err != nil {
-		log.Errorf("failed to delete kv value: %v", code.Format(err))
-		return false, code.Format(err)
+		log.Errorf("failed to delete kv value: %v", err)
+		return false, scerrors.From(err)
 	}
 	return ok, nil
 }
@@ -191,13 +276,13 @@ func (s *KV) BulkSetValue(ctx context.Context, namespaceId string, data []BulkIt
 		})
 	}
 
-	val, err := storage.ClientInterface.BulkSetValue(ctx, &models.BulkSet{
+	val, err := s.client.BulkSetValue(ctx, &models.BulkSet{
 		NamespaceId: namespaceId,
 		Items:       items,
 	})
 	if err != nil {
-		log.Errorf("failed to bulk set kv value: %v", code.Format(err))
-		return 0, code.Format(err)
+		log.Errorf("failed to bulk set kv value: %v", err)
+		return 0, scerrors.From(err)
 	}
 	return val, nil
 }
@@ -209,10 +294,10 @@ func (s *KV) BulkSetValue(ctx context.Context, namespaceId string, data []BulkIt
 //	namespaceId: Identifier of the namespace
 //	keys: A slice of keys to delete
 func (s *KV) BulkDelValue(ctx context.Context, namespaceId string, keys []string) (bool, error) {
-	ok, err := storage.ClientInterface.BulkDelValue(ctx, namespaceId, keys)
+	ok, err := s.client.BulkDelValue(ctx, namespaceId, keys)
 	if err != nil {
-		log.Errorf("failed to bulk delete kv value: %v", code.Format(err))
-		return false, code.Format(err)
+		log.Errorf("failed to bulk delete kv value: %v", err)
+		return false, scerrors.From(err)
 	}
 	return ok, nil
 }
@@ -226,15 +311,15 @@ func (s *KV) BulkDelValue(ctx context.Context, namespaceId string, keys []string
 //	value: kv value
 //	expiration: kv expiration  Time-to-live in seconds (s)
 func (s *KV) SetValue(ctx context.Context, namespaceId string, key string, value string, expiration uint) (bool, error) {
-	ok, err := storage.ClientInterface.SetValue(ctx, &models.SetValue{
+	ok, err := s.client.SetValue(ctx, &models.SetValue{
 		NamespaceId: namespaceId,
 		Key:         key,
 		Value:       value,
 		Expiration:  expiration,
 	})
 	if err != nil {
-		log.Errorf("failed to set kv value: %v", code.Format(err))
-		return false, code.Format(err)
+		log.Errorf("failed to set kv value: %v", err)
+		return false, scerrors.From(err)
 	}
 	return ok, nil
 }
@@ -246,10 +331,103 @@ func (s *KV) SetValue(ctx context.Context, namespaceId string, key string, value
 //	namespaceId: Identifier of the namespace
 //	key: The key whose value is to be retrieved
 func (s *KV) GetValue(ctx context.Context, namespaceId string, key string) (string, error) {
-	val, err := storage.ClientInterface.GetValue(ctx, namespaceId, key)
+	val, err := s.client.GetValue(ctx, namespaceId, key)
+	if err != nil {
+		log.Errorf("failed to get kv value: %v", err)
+		return "", scerrors.From(err)
+	}
+	return val, nil
+}
+
+// CompareAndSwap replaces the value of key with newValue only if it currently
+// holds oldValue, and reports whether it did. A missing key never matches.
+// The hosted API has no conditional writes, so there it fails with an error
+// matching errors.ErrNotImplemented; the local storage is atomic across
+// processes.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: The key to update
+//	oldValue: The value the key must hold
+//	newValue: The value to store
+//	expiration: Time-to-live in seconds (s) of the new value
+func (s *KV) CompareAndSwap(ctx context.Context, namespaceId string, key string, oldValue string, newValue string, expiration uint) (bool, error) {
+	ok, err := s.client.CompareAndSwap(ctx, &models.CompareAndSwap{
+		NamespaceId: namespaceId,
+		Key:         key,
+		Old:         oldValue,
+		New:         newValue,
+		Expiration:  expiration,
+	})
+	if err != nil {
+		log.Errorf("failed to compare and swap kv value: %v", err)
+		return false, scerrors.From(err)
+	}
+	return ok, nil
+}
+
+// Increment adds delta to the integer stored at key and returns the result.
+// A missing key counts as 0; a value that is not an integer is an error.
+// Like CompareAndSwap, it is only available on the local storage.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: The counter key
+//	delta: The amount to add, negative to decrement
+//	expiration: Time-to-live in seconds (s); 0 keeps the lifetime of an existing local counter
+func (s *KV) Increment(ctx context.Context, namespaceId string, key string, delta int64, expiration uint) (int64, error) {
+	n, err := s.client.Increment(ctx, &models.Increment{
+		NamespaceId: namespaceId,
+		Key:         key,
+		Delta:       delta,
+		Expiration:  expiration,
+	})
+	if err != nil {
+		log.Errorf("failed to increment kv value: %v", err)
+		return 0, scerrors.From(err)
+	}
+	return n, nil
+}
+
+// SetNX sets key only if it does not exist yet, and reports whether it did.
+// It suits dedup markers and simple locks, on the local storage only, see
+// CompareAndSwap.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: kv key
+//	value: kv value
+//	expiration: kv expiration  Time-to-live in seconds (s)
+func (s *KV) SetNX(ctx context.Context, namespaceId string, key string, value string, expiration uint) (bool, error) {
+	ok, err := s.client.SetNX(ctx, &models.SetValue{
+		NamespaceId: namespaceId,
+		Key:         key,
+		Value:       value,
+		Expiration:  expiration,
+	})
+	if err != nil {
+		log.Errorf("failed to set kv value if absent: %v", err)
+		return false, scerrors.From(err)
+	}
+	return ok, nil
+}
+
+// GetAndDelete removes key and returns the value it held. A missing key is
+// an error matching errors.ErrNotFound. The hosted API cannot do it
+// atomically, see CompareAndSwap.
+// Parameters:
+//
+//	ctx: Request context
+//	namespaceId: Identifier of the namespace
+//	key: The key to take
+func (s *KV) GetAndDelete(ctx context.Context, namespaceId string, key string) (string, error) {
+	val, err := s.client.GetAndDelete(ctx, namespaceId, key)
 	if err != nil {
-		log.Errorf("failed to get kv value: %v", code.Format(err))
-		return "", code.Format(err)
+		log.Errorf("failed to get and delete kv value: %v", err)
+		return "", scerrors.From(err)
 	}
 	return val, nil
 }
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+func testAtomicKV(t *testing.T, kv *KV, ns string) {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	var winners atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := kv.Increment(ctx, ns, "counter", 2, 0); err != nil {
+				t.Error(err)
+			}
+			ok, err := kv.SetNX(ctx, ns, "marker", "taken", 60)
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				winners.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n, err := kv.Increment(ctx, ns, "counter", -5, 0); err != nil || n != 35 {
+		t.Fatalf("counter: %d, %v", n, err)
+	}
+	if winners.Load() != 1 {
+		t.Fatalf("%d callers set the marker", winners.Load())
+	}
+
+	if ok, err := kv.CompareAndSwap(ctx, ns, "marker", "other", "x", 60); err != nil || ok {
+		t.Fatalf("swap with a stale value: %v, %v", ok, err)
+	}
+	if ok, err := kv.CompareAndSwap(ctx, ns, "marker", "taken", "released", 60); err != nil || !ok {
+		t.Fatalf("swap: %v, %v", ok, err)
+	}
+	if ok, err := kv.CompareAndSwap(ctx, ns, "missing", "", "x", 60); err != nil || ok {
+		t.Fatalf("swap of a missing key: %v, %v", ok, err)
+	}
+
+	if v, err := kv.GetAndDelete(ctx, ns, "marker"); err != nil || v != "released" {
+		t.Fatalf("get and delete: %q, %v", v, err)
+	}
+	if _, err := kv.GetAndDelete(ctx, ns, "marker"); !errors.Is(err, scerrors.ErrNotFound) {
+		t.Fatalf("second get and delete: %v", err)
+	}
+
+	if _, err := kv.SetValue(ctx, ns, "text", "abc", 60); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kv.Increment(ctx, ns, "text", 1, 0); !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("increment of a string: %v", err)
+	}
+}
+
+func TestAtomicKV(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		kv, id := localKV(t)
+		testAtomicKV(t, kv, id)
+	})
+	t.Run("http", func(t *testing.T) {
+		// A read and a write would race with other runs: refused.
+		kv, ctx := kvServer(t), context.Background()
+		_, casErr := kv.CompareAndSwap(ctx, "ns", "k", "a", "b", 0)
+		_, incrErr := kv.Increment(ctx, "ns", "k", 1, 0)
+		_, nxErr := kv.SetNX(ctx, "ns", "k", "a", 0)
+		_, takeErr := kv.GetAndDelete(ctx, "ns", "k")
+		for _, err := range []error{casErr, incrErr, nxErr, takeErr} {
+			if !errors.Is(err, scerrors.ErrNotImplemented) {
+				t.Fatalf("want not implemented, got %v", err)
+			}
+		}
+	})
+}
+
+func testScanKeys(t *testing.T, kv *KV, ns string) {
+	ctx := context.Background()
+	keys := map[string]string{}
+	for i := 0; i < 150; i++ {
+		keys[fmt.Sprintf("seen:example.com:%03d", i)] = "1"
+		keys[fmt.Sprintf("seen:other.org:%03d", i)] = "1"
+	}
+	keys["seen:example.com"] = "1"
+	// Shares its name with the old guess of a scan route.
+	keys["scan"] = "1"
+	keys["queue:example.com:1"] = "1"
+	var items []BulkItem
+	for key, value := range keys {
+		items = append(items, BulkItem{Key: key, Value: value, Expiration: 3600})
+	}
+	if _, err := kv.BulkSetValue(ctx, ns, items); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for key, err := range kv.IterScan(ctx, ns, "seen:example.com:*", 20) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.Size != 1 {
+			t.Fatalf("size of %s: %d", key.Key, key.Size)
+		}
+		got = append(got, key.Key)
+	}
+	if len(got) != 150 || slices.Contains(got, "seen:example.com") {
+		t.Fatalf("scanned %d keys", len(got))
+	}
+
+	resp, err := kv.ScanKeys(ctx, ns, "*:example.com:1[0-4]?", "", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for resp.Cursor != "" {
+		next, err := kv.ScanKeys(ctx, ns, "*:example.com:1[0-4]?", resp.Cursor, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Items, resp.Cursor = append(resp.Items, next.Items...), next.Cursor
+	}
+	if len(resp.Items) != 50 {
+		t.Fatalf("scanned %d keys with a class", len(resp.Items))
+	}
+}
+
+func TestScanKeys(t *testing.T) {
+	t.Run("http", func(t *testing.T) {
+		testScanKeys(t, kvServer(t), "ns")
+	})
+	t.Run("local", func(t *testing.T) {
+		kv, id := localKV(t)
+		testScanKeys(t, kv, id)
+
+		resp, err := kv.ScanKeys(context.Background(), id, "seen:*", "", 5)
+		if err != nil || len(resp.Items) != 5 || resp.Cursor != "seen:example.com:003" {
+			t.Fatalf("batch: %+v, %v", resp, err)
+		}
+		if resp.Items[0].ExpireAt.Before(time.Now().Add(59 * time.Minute)) {
+			t.Fatalf("expiry: %v", resp.Items[0].ExpireAt)
+		}
+	})
+}
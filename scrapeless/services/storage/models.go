@@ -76,6 +76,21 @@ type KvKeys struct {
 	TotalPage int64            `json:"totalPage"`
 }
 
+// KeyInfo describes a key returned by a scan. ExpireAt is zero when the
+// backend does not report expiries.
+type KeyInfo struct {
+	Key      string    `json:"key"`
+	Size     int64     `json:"size"`
+	ExpireAt time.Time `json:"expireAt"`
+}
+
+// ScanKeysResult is a batch of keys. Pass Cursor to the next ScanKeys call;
+// it is empty once the scan is over.
+type ScanKeysResult struct {
+	Items  []KeyInfo `json:"items"`
+	Cursor string    `json:"cursor"`
+}
+
 type BulkSet struct {
 	NamespaceId string     `json:"namespaceId"`
 	Items       []BulkItem `json:"items"`
@@ -122,8 +137,21 @@ type ObjectInfo struct {
 	ActorId   string `json:"actorId,omitempty"`
 	RunId     string `json:"runId,omitempty"`
 	FileType  string `json:"fileType,omitempty"` // The value of FileType is one of json, html, png
+	MD5       string `json:"md5,omitempty"`
+	SHA256    string `json:"sha256,omitempty"`
 	CreatedAt string `json:"createdAt,omitempty"`
 	UpdatedAt string `json:"updatedAt,omitempty"`
+
+	ContentType string            `json:"contentType,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+}
+
+// ObjectFilter selects the objects listed by ListObjects.
+type ObjectFilter struct {
+	ContentType string            // Prefix of the content type, like "image/"
+	Metadata    map[string]string // Entries the objects must all have
+	Tags        []string          // Tags the objects must all have
 }
 
 // Queue
@@ -137,14 +165,23 @@ type ListQueuesResponse struct {
 }
 
 type Item struct {
-	Id          string `json:"id,omitempty"`
-	Name        string `json:"name,omitempty"`
-	TeamId      string `json:"teamId,omitempty"`
-	ActorId     string `json:"actorId,omitempty"`
-	RunId       string `json:"runId,omitempty"`
-	Description string `json:"description,omitempty"`
-	CreatedAt   string `json:"createdAt,omitempty"`
-	UpdatedAt   string `json:"updatedAt,omitempty"`
+	Id          string     `json:"id,omitempty"`
+	Name        string     `json:"name,omitempty"`
+	TeamId      string     `json:"teamId,omitempty"`
+	ActorId     string     `json:"actorId,omitempty"`
+	RunId       string     `json:"runId,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CreatedAt   string     `json:"createdAt,omitempty"`
+	UpdatedAt   string     `json:"updatedAt,omitempty"`
+	Stats       QueueStats `json:"stats"`
+}
+
+// QueueStats counts the messages of a queue by state.
+type QueueStats struct {
+	Pending int `json:"pending"`
+	Running int `json:"running"`
+	Success int `json:"success"`
+	Failed  int `json:"failed"`
 }
 
 type CreateQueueReq struct {
@@ -198,17 +235,19 @@ type Collection struct {
 }
 
 type Doc struct {
-	ID           string             `json:"id"`           // DocId
-	Vector       []float64          `json:"vector"`       // The vector content of the text
-	Content      string             `json:"content"`      // The text content of the vector
-	SparseVector map[string]float64 `json:"sparseVector"` // The sparse vector content of the text
-	Score        float64            `json:"score"`        // Matching score of query results
+	ID           string             `json:"id"`                 // DocId
+	Vector       []float64          `json:"vector"`             // The vector content of the text
+	Content      string             `json:"content"`            // The text content of the vector
+	SparseVector map[string]float64 `json:"sparseVector"`       // The sparse vector content of the text
+	Score        float64            `json:"score"`              // Matching score of query results
+	Metadata     map[string]any     `json:"metadata,omitempty"` // JSON attributes of the doc, matched by MetadataFilter
 }
 
 type BaseDoc struct {
-	Vector       []float64          `json:"vector"`       // The vector content of the text
-	Content      string             `json:"content"`      // The text content of the vector
-	SparseVector map[string]float64 `json:"sparseVector"` // The sparse vector content of the text
+	Vector       []float64          `json:"vector"`             // The vector content of the text
+	Content      string             `json:"content"`            // The text content of the vector
+	SparseVector map[string]float64 `json:"sparseVector"`       // The sparse vector content of the text
+	Metadata     map[string]any     `json:"metadata,omitempty"` // JSON attributes of the doc, matched by MetadataFilter
 }
 
 type ListCollectionsResponse struct {
@@ -223,6 +262,7 @@ type CreateCollectionRequest struct {
 	ActorId     string `json:"actorId"`
 	Description string `json:"description"`
 	Dimension   int    `json:"dimension"`
+	Metric      string `json:"metric,omitempty"` // Distance metric: cosine (default), euclidean or dotproduct
 	Name        string `json:"name"`
 	RunId       string `json:"runId"`
 }
@@ -243,9 +283,26 @@ type DocOpResponse struct {
 }
 
 type QueryVectorParam struct {
-	Vector         []float64          `json:"vector"`         // Query vector
-	SparseVector   map[string]float64 `json:"sparseVector"`   // Query sparse vector
-	Topk           int32              `json:"topk"`           // Number of results to return, min:1 max:1024
-	IncludeVector  bool               `json:"includeVector"`  // Whether to return the vector
-	IncludeContent bool               `json:"includeContent"` // Whether to return the content
+	Vector         []float64          `json:"vector"`           // Query vector
+	Text           string             `json:"text,omitempty"`   // Query text, embedded when Vector is empty
+	SparseVector   map[string]float64 `json:"sparseVector"`     // Query sparse vector
+	Topk           int32              `json:"topk"`             // Number of results to return, min:1 max:1024
+	IncludeVector  bool               `json:"includeVector"`    // Whether to return the vector
+	IncludeContent bool               `json:"includeContent"`   // Whether to return the content
+	Filter         *MetadataFilter    `json:"filter,omitempty"` // Only the docs whose metadata match, see MetadataFilter
+}
+
+// HybridQueryParam is a query ranking docs by a weighted sum of their dense
+// and sparse scores, see Vector.HybridQuery.
+type HybridQueryParam struct {
+	Text           string             `json:"text,omitempty"`       // Query text, embedded and sparse encoded when the vectors are empty
+	Vector         []float64          `json:"vector"`               // Query vector
+	SparseVector   map[string]float64 `json:"sparseVector"`         // Query sparse vector
+	DenseWeight    float64            `json:"denseWeight"`          // Weight of the dense score, both weights are 0.5 when 0
+	SparseWeight   float64            `json:"sparseWeight"`         // Weight of the sparse score
+	Topk           int32              `json:"topk"`                 // Number of results to return, min:1 max:1024
+	Candidates     int32              `json:"candidates,omitempty"` // Results of each query which are fused, 4*Topk when 0
+	IncludeVector  bool               `json:"includeVector"`        // Whether to return the vector
+	IncludeContent bool               `json:"includeContent"`       // Whether to return the content
+	Filter         *MetadataFilter    `json:"filter,omitempty"`     // Only the docs whose metadata match, see MetadataFilter
 }
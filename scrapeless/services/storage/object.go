@@ -2,35 +2,36 @@ package storage
 
 import (
 	"context"
-	"errors"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
-	"path/filepath"
-	"strings"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
+	"iter"
 )
 
-type Object struct{}
+type Object struct {
+	client storage.Object
+}
 
 // ListBuckets retrieves the list of buckets with pagination support.
 // Parameters:
 //
 //	ctx: The context for the request.
 //	page: Current page number, minimum value is 1. Defaults to 1 if provided value is <1.
-//	pageSize: Number of items per page, minimum value is 10. Defaults to 10 if provided value is <10.
+//	pageSize: Number of items per page. Defaults to 10 if provided value is <1.
 func (s *Object) ListBuckets(ctx context.Context, page int, pageSize int) (*ListBucketsResponse, error) {
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	buckets, err := storage.ClientInterface.ListBuckets(ctx, page, pageSize)
+	buckets, err := s.client.ListBuckets(ctx, page, pageSize)
 	if err != nil {
-		log.Errorf("failed to list buckets: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to list buckets: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var bucketsArray []Bucket
 	for _, bucket := range buckets.Buckets {
@@ -61,15 +62,15 @@ func (s *Object) ListBuckets(ctx context.Context, page int, pageSize int) (*List
 //	description: Optional description for the bucket.
 func (s *Object) CreateBucket(ctx context.Context, name string, description string) (bucketId string, bucketName string, err error) {
 	name = name + "-" + env.GetActorEnv().RunId
-	bucketId, err = storage.ClientInterface.CreateBucket(ctx, &models.CreateBucketRequest{
+	bucketId, err = s.client.CreateBucket(ctx, &models.CreateBucketRequest{
 		Name:        name,
 		Description: description,
 		ActorId:     env.GetActorEnv().ActorId,
 		RunId:       env.GetActorEnv().RunId,
 	})
 	if err != nil {
-		log.Errorf("failed to create bucket: %v", code.Format(err))
-		return "", "", code.Format(err)
+		log.Errorf("failed to create bucket: %v", err)
+		return "", "", scerrors.From(err)
 	}
 	return bucketId, bucketName, nil
 }
@@ -79,10 +80,10 @@ func (s *Object) CreateBucket(ctx context.Context, name string, description stri
 //
 //	ctx: The context for the request.
 func (s *Object) DeleteBucket(ctx context.Context, bucketId string) (bool, error) {
-	ok, err := storage.ClientInterface.DeleteBucket(ctx, bucketId)
+	ok, err := s.client.DeleteBucket(ctx, bucketId)
 	if err != nil {
-		log.Errorf("failed to delete bucket: %v", code.Format(err))
-		return false, code.Format(err)
+		log.Errorf("failed to delete bucket: %v", err)
+		return false, scerrors.From(err)
 	}
 	return ok, nil
 }
@@ -92,10 +93,10 @@ func (s *Object) DeleteBucket(ctx context.Context, bucketId string) (bool, error
 //
 //	ctx: The context for the request.
 func (s *Object) GetBucket(ctx context.Context, bucketId string) (*Bucket, error) {
-	bucket, err := storage.ClientInterface.GetBucket(ctx, bucketId)
+	bucket, err := s.client.GetBucket(ctx, bucketId)
 	if err != nil {
-		log.Errorf("failed to get bucket: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to get bucket: %v", err)
+		return nil, scerrors.From(err)
 	}
 	b := &Bucket{
 		Id:          bucket.Id,
@@ -116,23 +117,37 @@ func (s *Object) GetBucket(ctx context.Context, bucketId string) (*Bucket, error
 //	ctx: The context for the request.
 //	fuzzyFileName: Search pattern for matching object filenames.
 //	page: Current page number, defaults to 1 if <1.
-//	pageSize: Number of objects per page, defaults to 10 if <10.
-func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName string, page int64, pageSize int64) (*ListObjectsResponse, error) {
+//	pageSize: Number of objects per page, defaults to 10 if <1.
+//	filter: Optional content type, metadata and tags the objects must have.
+func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName string, page int64, pageSize int64, filter ...ObjectFilter) (*ListObjectsResponse, error) {
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	objects, err := storage.ClientInterface.ListObjects(ctx, &models.ListObjectsRequest{
+	req := &models.ListObjectsRequest{
 		BucketId: bucketId,
 		Search:   fuzzyFileName,
 		Page:     page,
 		PageSize: pageSize,
-	})
+	}
+	for _, f := range filter {
+		if f.ContentType != "" {
+			req.ContentType = f.ContentType
+		}
+		for key, value := range f.Metadata {
+			if req.Metadata == nil {
+				req.Metadata = map[string]string{}
+			}
+			req.Metadata[key] = value
+		}
+		req.Tags = append(req.Tags, f.Tags...)
+	}
+	objects, err := s.client.ListObjects(ctx, req)
 	if err != nil {
-		log.Errorf("failed to list objects: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to list objects: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var objectsArray []ObjectInfo
 	for _, object := range objects.Objects {
@@ -145,8 +160,14 @@ func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName
 			ActorId:   object.ActorId,
 			RunId:     object.RunId,
 			FileType:  object.FileType,
+			MD5:       object.MD5,
+			SHA256:    object.SHA256,
 			CreatedAt: object.CreatedAt,
 			UpdatedAt: object.UpdatedAt,
+
+			ContentType: object.ContentType,
+			Metadata:    object.Metadata,
+			Tags:        object.Tags,
 		}
 		objectsArray = append(objectsArray, o)
 	}
@@ -156,6 +177,23 @@ func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName
 	}, nil
 }
 
+// IterObjects iterates over the objects of a bucket.
+// Parameters:
+//
+//	ctx: The context for the request, cancelling it ends the iteration.
+//	bucketId: The bucket to list.
+//	fuzzyFileName: Search pattern for matching object filenames.
+//	opts: Page size, prefetch and limit, see package pager.
+func (s *Object) IterObjects(ctx context.Context, bucketId string, fuzzyFileName string, opts ...pager.Option) iter.Seq2[ObjectInfo, error] {
+	return pager.Seq(ctx, func(ctx context.Context, page, pageSize int64) ([]ObjectInfo, int64, error) {
+		resp, err := s.ListObjects(ctx, bucketId, fuzzyFileName, page, pageSize)
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Objects, resp.Total, nil
+	}, opts...)
+}
+
 // GetObject retrieves an object by its ID using HTTP.
 //
 // Parameters:
@@ -163,13 +201,13 @@ func (s *Object) ListObjects(ctx context.Context, bucketId string, fuzzyFileName
 //	ctx: The context for the request.
 //	objectId: The unique identifier of the object to retrieve.
 func (s *Object) GetObject(ctx context.Context, bucketId string, objectId string) ([]byte, error) {
-	object, err := storage.ClientInterface.GetObject(ctx, &models.ObjectRequest{
+	object, err := s.client.GetObject(ctx, &models.ObjectRequest{
 		BucketId: bucketId,
 		ObjectId: objectId,
 	})
 	if err != nil {
-		log.Errorf("failed to get object: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to get object: %v", err)
+		return nil, scerrors.From(err)
 	}
 	return object, nil
 }
@@ -181,21 +219,26 @@ func (s *Object) GetObject(ctx context.Context, bucketId string, objectId string
 //	ctx: The context for the request.
 //	filename: The name of the file to store.
 //	data: The byte data to upload.
-func (s *Object) PutObject(ctx context.Context, bucketId string, filename string, data []byte) (string, error) {
-	_, ok := getObjectType(filename)
-	if !ok {
-		return "", errors.New("object type not supported")
+//	opts: Content type, metadata and tags, the other options apply to
+//	PutObjectStream only.
+func (s *Object) PutObject(ctx context.Context, bucketId string, filename string, data []byte, opts ...ObjectOption) (string, error) {
+	o := newObjectOptions(opts)
+	attrs, err := o.attributes(filename, data)
+	if err != nil {
+		return "", err
 	}
-	object, err := storage.ClientInterface.PutObject(ctx, &models.PutObjectRequest{
+	object, err := s.client.PutObject(ctx, &models.PutObjectRequest{
 		BucketId: bucketId,
 		Filename: filename,
 		Data:     data,
 		ActorId:  env.GetActorEnv().ActorId,
 		RunId:    env.GetActorEnv().RunId,
+
+		ObjectAttributes: attrs,
 	})
 	if err != nil {
-		log.Errorf("failed to put object: %v", code.Format(err))
-		return "", code.Format(err)
+		log.Errorf("failed to put object: %v", err)
+		return "", scerrors.From(err)
 	}
 	return object, nil
 }
@@ -206,13 +249,13 @@ func (s *Object) PutObject(ctx context.Context, bucketId string, filename string
 //	ctx: The context used for the HTTP request.
 //	objectId: The identifier of the object to delete.
 func (s *Object) DeleteObject(ctx context.Context, bucketId string, objectId string) (bool, error) {
-	resp, err := storage.ClientInterface.DeleteObject(ctx, &models.ObjectRequest{
+	resp, err := s.client.DeleteObject(ctx, &models.ObjectRequest{
 		BucketId: bucketId,
 		ObjectId: objectId,
 	})
 	if err != nil {
-		log.Errorf("failed to delete object: %v", code.Format(err))
-		return false, code.Format(err)
+		log.Errorf("failed to delete object: %v", err)
+		return false, scerrors.From(err)
 	}
 	return resp, nil
 }
@@ -222,16 +265,12 @@ func (s *Object) Close() error {
 }
 
 var (
+	// ObjectTypeMapping lists extensions accepted besides the registered ones.
+	//
+	// Deprecated: use RegisterContentType, which is safe for concurrent use.
 	ObjectTypeMapping = map[string]struct{}{
 		"json": {},
 		"html": {},
 		"png":  {},
 	}
 )
-
-func getObjectType(filename string) (string, bool) {
-	ext := filepath.Ext(filename)
-	ext = strings.Replace(ext, ".", "", -1)
-	_, ok := ObjectTypeMapping[ext]
-	return ext, ok
-}
@@ -0,0 +1,452 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+const defaultPartSize = 8 << 20
+
+// ObjectOptions configures the uploads and downloads of objects.
+type ObjectOptions struct {
+	ContentType string            // Content type of an upload, detected when empty
+	Metadata    map[string]string // User metadata of an upload
+	Tags        []string          // Tags of an upload
+
+	PartSize int64 // Uploads of more bytes, or of unknown size, are sent in parts of PartSize
+	// Progress is called after every part uploaded or chunk read with the
+	// bytes done so far and the total, -1 when unknown.
+	Progress func(done, total int64)
+	MD5      string // Expected hex digest of the content
+	SHA256   string // Expected hex digest of the content
+	UploadId string // Multipart upload to resume, see UploadError
+	Offset   int64  // First byte read by GetObjectStream
+	Length   int64  // Bytes read by GetObjectStream, up to the end when 0
+}
+
+// ObjectOption configures the uploads and downloads of objects.
+type ObjectOption func(*ObjectOptions)
+
+// WithContentType sets the content type of an upload instead of detecting
+// it, which allows any type of file.
+func WithContentType(contentType string) ObjectOption {
+	return func(o *ObjectOptions) { o.ContentType = contentType }
+}
+
+// WithMetadata adds user metadata to an upload, objects can be listed by it.
+func WithMetadata(metadata map[string]string) ObjectOption {
+	return func(o *ObjectOptions) {
+		if o.Metadata == nil {
+			o.Metadata = make(map[string]string, len(metadata))
+		}
+		for key, value := range metadata {
+			o.Metadata[key] = value
+		}
+	}
+}
+
+// WithTags adds tags to an upload, objects can be listed by them.
+func WithTags(tags ...string) ObjectOption {
+	return func(o *ObjectOptions) {
+		for _, tag := range tags {
+			if tag != "" && !slices.Contains(o.Tags, tag) {
+				o.Tags = append(o.Tags, tag)
+			}
+		}
+	}
+}
+
+// WithPartSize sets the size of the parts of multipart uploads.
+func WithPartSize(n int64) ObjectOption {
+	return func(o *ObjectOptions) { o.PartSize = n }
+}
+
+// WithProgress registers a callback receiving the bytes transferred so far
+// and the total, -1 when unknown.
+func WithProgress(fn func(done, total int64)) ObjectOption {
+	return func(o *ObjectOptions) { o.Progress = fn }
+}
+
+// WithMD5 checks the content against a hex MD5 digest. An upload that does
+// not match is not stored; a download that does not match fails on its last
+// read.
+func WithMD5(digest string) ObjectOption {
+	return func(o *ObjectOptions) { o.MD5 = strings.ToLower(digest) }
+}
+
+// WithSHA256 checks the content against a hex SHA-256 digest, see WithMD5.
+func WithSHA256(digest string) ObjectOption {
+	return func(o *ObjectOptions) { o.SHA256 = strings.ToLower(digest) }
+}
+
+// WithResume resumes the multipart upload reported by an UploadError. The
+// reader must provide the same content from its start; the parts already
+// uploaded are read and checked but not sent again.
+func WithResume(uploadId string) ObjectOption {
+	return func(o *ObjectOptions) { o.UploadId = uploadId }
+}
+
+// WithRange reads length bytes from offset, up to the end when length is 0.
+// Digests are only checked when the whole object is read.
+func WithRange(offset, length int64) ObjectOption {
+	return func(o *ObjectOptions) {
+		o.Offset = offset
+		o.Length = length
+	}
+}
+
+func newObjectOptions(opts []ObjectOption) ObjectOptions {
+	o := ObjectOptions{PartSize: defaultPartSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.PartSize < 1 {
+		o.PartSize = defaultPartSize
+	}
+	return o
+}
+
+// attributes returns the attributes of an upload of filename, detecting its
+// content type from head when it was not set.
+func (o *ObjectOptions) attributes(filename string, head []byte) (models.ObjectAttributes, error) {
+	for key := range o.Metadata {
+		if key == "" {
+			return models.ObjectAttributes{}, scerrors.New(scerrors.KindInvalidArgument, "object metadata key cannot be empty")
+		}
+	}
+	contentType := o.ContentType
+	if contentType == "" {
+		detected, ok := DetectContentType(filename, head)
+		if !ok {
+			return models.ObjectAttributes{}, scerrors.Newf(scerrors.KindInvalidArgument, "object type of %s not supported", filename)
+		}
+		contentType = detected
+	}
+	return models.ObjectAttributes{ContentType: contentType, Metadata: o.Metadata, Tags: o.Tags}, nil
+}
+
+// PutObjectResult describes an uploaded object.
+type PutObjectResult struct {
+	ObjectId string `json:"objectId"`
+	Size     int64  `json:"size"`
+	MD5      string `json:"md5"`    // Hex digest of the content
+	SHA256   string `json:"sha256"` // Hex digest of the content
+	Parts    int    `json:"parts"`  // Parts of a multipart upload, 0 for a single request
+}
+
+// UploadError is returned when a multipart upload stops before completion.
+// The parts already sent are kept: the upload resumes with WithResume.
+type UploadError struct {
+	UploadId string
+	Err      error
+}
+
+func (e *UploadError) Error() string {
+	return "upload " + e.UploadId + " interrupted: " + e.Err.Error()
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// PutObjectStream uploads the content of r without holding it in memory.
+// Objects up to the part size are sent in a single request; larger ones, or
+// ones of unknown size, in parts which can be resumed after a failure, see
+// UploadError. Storages without multipart uploads, like the HTTP API, get
+// every object in a single request. The MD5 and SHA-256 digests of the
+// content are computed while it is sent and checked by the storage.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	bucketId: The bucket receiving the object.
+//	filename: The name of the file to store.
+//	r: The content.
+//	size: The length of the content, -1 when unknown.
+//	opts: Content type, metadata, tags, part size, progress callback, expected
+//	digests and upload to resume.
+func (s *Object) PutObjectStream(ctx context.Context, bucketId string, filename string, r io.Reader, size int64, opts ...ObjectOption) (*PutObjectResult, error) {
+	o := newObjectOptions(opts)
+	var head []byte
+	if _, ok := DetectContentType(filename, nil); !ok && o.ContentType == "" {
+		// Sniff the content type from the start of the content, then send it.
+		head = make([]byte, sniffLen)
+		n, err := io.ReadFull(r, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, scerrors.From(err)
+		}
+		head = head[:n]
+		r = io.MultiReader(bytes.NewReader(head), r)
+	}
+	attrs, err := o.attributes(filename, head)
+	if err != nil {
+		return nil, err
+	}
+	size = max(size, -1)
+	up := &upload{object: s, bucketId: bucketId, filename: filename, attrs: attrs, size: size, opts: o, md5: md5.New(), sha256: sha256.New()}
+
+	var result *PutObjectResult
+	if o.UploadId == "" && size >= 0 && size <= o.PartSize {
+		result, err = up.single(ctx, r)
+	} else {
+		result, err = up.multipart(ctx, r)
+	}
+	if err != nil {
+		log.Errorf("failed to put object stream: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// upload is the state of a PutObjectStream call.
+type upload struct {
+	object   *Object
+	bucketId string
+	filename string
+	attrs    models.ObjectAttributes
+	size     int64
+	opts     ObjectOptions
+	md5      hash.Hash
+	sha256   hash.Hash
+	done     int64
+}
+
+func (u *upload) single(ctx context.Context, r io.Reader) (*PutObjectResult, error) {
+	body := &progressReader{r: io.TeeReader(r, io.MultiWriter(u.md5, u.sha256)), done: &u.done, total: u.size, progress: u.opts.Progress}
+	objectId, err := u.object.client.PutObjectStream(ctx, &models.PutObjectStreamRequest{
+		BucketId: u.bucketId,
+		Filename: u.filename,
+		Body:     body,
+		Size:     u.size,
+		ActorId:  env.GetActorEnv().ActorId,
+		RunId:    env.GetActorEnv().RunId,
+
+		ObjectAttributes: u.attrs,
+	})
+	if err != nil {
+		return nil, scerrors.From(err)
+	}
+	result := u.result(objectId, 0)
+	if err = u.check(result); err != nil {
+		// Only known once the content has been sent.
+		if _, delErr := u.object.client.DeleteObject(ctx, &models.ObjectRequest{BucketId: u.bucketId, ObjectId: objectId}); delErr != nil {
+			log.Warnf("failed to delete corrupted object %s: %v", objectId, delErr)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (u *upload) multipart(ctx context.Context, r io.Reader) (*PutObjectResult, error) {
+	uploadId := u.opts.UploadId
+	uploaded := map[int]models.UploadedPart{}
+	if uploadId == "" {
+		id, err := u.object.client.CreateUpload(ctx, &models.CreateUploadRequest{
+			BucketId: u.bucketId,
+			Filename: u.filename,
+			Size:     u.size,
+			ActorId:  env.GetActorEnv().ActorId,
+			RunId:    env.GetActorEnv().RunId,
+
+			ObjectAttributes: u.attrs,
+		})
+		if errors.Is(err, scerrors.ErrNotImplemented) {
+			return u.single(ctx, r)
+		}
+		if err != nil {
+			return nil, scerrors.From(err)
+		}
+		uploadId = id
+	} else {
+		parts, err := u.object.client.ListParts(ctx, u.bucketId, uploadId)
+		if err != nil {
+			return nil, scerrors.From(err)
+		}
+		for _, part := range parts {
+			uploaded[part.Number] = part
+		}
+	}
+	interrupted := func(err error) error {
+		return &UploadError{UploadId: uploadId, Err: scerrors.From(err)}
+	}
+
+	var parts []models.UploadedPart
+	buf := make([]byte, u.opts.PartSize)
+	for number := 1; ; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, interrupted(err)
+		}
+		n, readErr := io.ReadFull(r, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return nil, interrupted(readErr)
+		}
+		if n == 0 && number > 1 {
+			break
+		}
+		data := buf[:n]
+		u.md5.Write(data)
+		u.sha256.Write(data)
+		sum := md5.Sum(data)
+		part := models.UploadedPart{Number: number, Size: int64(n), MD5: hex.EncodeToString(sum[:])}
+		if prev, ok := uploaded[number]; !ok || prev.Size != part.Size || !strings.EqualFold(prev.MD5, part.MD5) {
+			sent, err := u.object.client.UploadPart(ctx, &models.UploadPartRequest{
+				BucketId: u.bucketId,
+				UploadId: uploadId,
+				Number:   number,
+				Data:     data,
+				MD5:      part.MD5,
+			})
+			if err != nil {
+				return nil, interrupted(err)
+			}
+			if !strings.EqualFold(sent.MD5, part.MD5) {
+				return nil, interrupted(scerrors.Newf(scerrors.KindInternal, "part %d was corrupted in transit", number))
+			}
+		}
+		parts = append(parts, part)
+		u.done += int64(n)
+		if u.opts.Progress != nil {
+			u.opts.Progress(u.done, u.size)
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	result := u.result("", len(parts))
+	if err := u.check(result); err != nil {
+		if abortErr := u.object.client.AbortUpload(ctx, u.bucketId, uploadId); abortErr != nil {
+			log.Warnf("failed to abort upload %s: %v", uploadId, abortErr)
+		}
+		return nil, err
+	}
+	objectId, err := u.object.client.CompleteUpload(ctx, &models.CompleteUploadRequest{
+		BucketId: u.bucketId,
+		UploadId: uploadId,
+		Parts:    parts,
+		MD5:      result.MD5,
+		SHA256:   result.SHA256,
+	})
+	if err != nil {
+		return nil, interrupted(err)
+	}
+	result.ObjectId = objectId
+	return result, nil
+}
+
+func (u *upload) result(objectId string, parts int) *PutObjectResult {
+	return &PutObjectResult{
+		ObjectId: objectId,
+		Size:     u.done,
+		MD5:      hex.EncodeToString(u.md5.Sum(nil)),
+		SHA256:   hex.EncodeToString(u.sha256.Sum(nil)),
+		Parts:    parts,
+	}
+}
+
+// check compares the content sent with the announced size and digests.
+func (u *upload) check(result *PutObjectResult) error {
+	if u.size >= 0 && result.Size != u.size {
+		return scerrors.Newf(scerrors.KindInvalidArgument, "object %s has %d bytes, %d were announced", u.filename, result.Size, u.size)
+	}
+	if u.opts.MD5 != "" && u.opts.MD5 != result.MD5 {
+		return scerrors.Newf(scerrors.KindInvalidArgument, "object %s has md5 %s, expected %s", u.filename, result.MD5, u.opts.MD5)
+	}
+	if u.opts.SHA256 != "" && u.opts.SHA256 != result.SHA256 {
+		return scerrors.Newf(scerrors.KindInvalidArgument, "object %s has sha256 %s, expected %s", u.filename, result.SHA256, u.opts.SHA256)
+	}
+	return nil
+}
+
+// GetObjectStream opens the content of an object, or of a range of it, for
+// reading. The caller must close it.
+// Parameters:
+//
+//	ctx: The context for the request, cancelling it aborts the reads.
+//	bucketId: The bucket of the object.
+//	objectId: The unique identifier of the object to read.
+//	opts: Range, progress callback and expected digests.
+func (s *Object) GetObjectStream(ctx context.Context, bucketId string, objectId string, opts ...ObjectOption) (io.ReadCloser, error) {
+	o := newObjectOptions(opts)
+	if o.Offset < 0 || o.Length < 0 {
+		return nil, scerrors.New(scerrors.KindInvalidArgument, "object range cannot be negative")
+	}
+	stream, err := s.client.GetObjectStream(ctx, &models.GetObjectStreamRequest{
+		BucketId: bucketId,
+		ObjectId: objectId,
+		Offset:   o.Offset,
+		Length:   o.Length,
+	})
+	if err != nil {
+		log.Errorf("failed to get object stream: %v", err)
+		return nil, scerrors.From(err)
+	}
+	var done int64
+	r := &progressReader{r: stream.Body, done: &done, total: stream.Size, progress: o.Progress}
+	if o.Offset > 0 || o.Length > 0 || o.MD5 == "" && o.SHA256 == "" {
+		return readCloser{Reader: r, Closer: stream.Body}, nil
+	}
+	return &verifyingReader{r: r, Closer: stream.Body, md5: md5.New(), sha256: sha256.New(), opts: o, objectId: objectId}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// progressReader reports the bytes read.
+type progressReader struct {
+	r        io.Reader
+	done     *int64
+	total    int64
+	progress func(done, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		*p.done += int64(n)
+		if p.progress != nil {
+			p.progress(*p.done, p.total)
+		}
+	}
+	return n, err
+}
+
+// verifyingReader fails the read reaching the end of the content when its
+// digests differ from the expected ones.
+type verifyingReader struct {
+	r io.Reader
+	io.Closer
+	md5, sha256 hash.Hash
+	opts        ObjectOptions
+	objectId    string
+}
+
+func (v *verifyingReader) Read(b []byte) (int, error) {
+	n, err := v.r.Read(b)
+	v.md5.Write(b[:n])
+	v.sha256.Write(b[:n])
+	if err != io.EOF {
+		return n, err
+	}
+	if digest := hex.EncodeToString(v.md5.Sum(nil)); v.opts.MD5 != "" && digest != v.opts.MD5 {
+		return n, scerrors.Newf(scerrors.KindInternal, "object %s has md5 %s, expected %s", v.objectId, digest, v.opts.MD5)
+	}
+	if digest := hex.EncodeToString(v.sha256.Sum(nil)); v.opts.SHA256 != "" && digest != v.opts.SHA256 {
+		return n, scerrors.Newf(scerrors.KindInternal, "object %s has sha256 %s, expected %s", v.objectId, digest, v.opts.SHA256)
+	}
+	return n, io.EOF
+}
@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/storage_http"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+func localObjects(t *testing.T) (*Object, string) {
+	t.Helper()
+	s := newLocalStorage(t)
+	bucketId, _, err := s.Object.CreateBucket(context.Background(), "streams", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.Object, bucketId
+}
+
+func streamContent(n int) ([]byte, string, string) {
+	data := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(data)
+	m, s := md5.Sum(data), sha256.Sum256(data)
+	return data, hex.EncodeToString(m[:]), hex.EncodeToString(s[:])
+}
+
+// failingReader returns an error once n bytes have been read.
+type failingReader struct {
+	r io.Reader
+	n int
+}
+
+func (f *failingReader) Read(b []byte) (int, error) {
+	if f.n <= 0 {
+		return 0, errors.New("connection reset")
+	}
+	if len(b) > f.n {
+		b = b[:f.n]
+	}
+	n, err := f.r.Read(b)
+	f.n -= n
+	return n, err
+}
+
+func TestObjectStream(t *testing.T) {
+	objects, bucketId := localObjects(t)
+	ctx := context.Background()
+	data, md5Sum, sha256Sum := streamContent(100_000)
+
+	var last int64
+	res, err := objects.PutObjectStream(ctx, bucketId, "data.json", bytes.NewReader(data), -1,
+		WithPartSize(16<<10), WithMD5(md5Sum), WithSHA256(strings.ToUpper(sha256Sum)),
+		WithProgress(func(done, total int64) { last = done }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Parts != 7 || res.Size != int64(len(data)) || res.MD5 != md5Sum || res.SHA256 != sha256Sum || last != res.Size {
+		t.Fatalf("result %+v, progress %d", res, last)
+	}
+
+	r, err := objects.GetObjectStream(ctx, bucketId, res.ObjectId, WithMD5(md5Sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	_ = r.Close()
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes: %v", len(got), err)
+	}
+	r, err = objects.GetObjectStream(ctx, bucketId, res.ObjectId, WithRange(99_990, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ = io.ReadAll(r)
+	_ = r.Close()
+	if !bytes.Equal(got, data[99_990:]) {
+		t.Fatalf("range: %d bytes", len(got))
+	}
+
+	// A digest mismatch fails the last read.
+	r, _ = objects.GetObjectStream(ctx, bucketId, res.ObjectId, WithSHA256(md5Sum))
+	if _, err = io.ReadAll(r); err == nil {
+		t.Fatal("want a checksum error")
+	}
+	_ = r.Close()
+
+	list, err := objects.ListObjects(ctx, bucketId, "", 1, 10)
+	if err != nil || list.Total != 1 || list.Objects[0].MD5 != md5Sum || list.Objects[0].Size != len(data) {
+		t.Fatalf("list: %+v, %v", list, err)
+	}
+}
+
+func TestObjectStreamResume(t *testing.T) {
+	objects, bucketId := localObjects(t)
+	ctx := context.Background()
+	data, md5Sum, _ := streamContent(50_000)
+
+	_, err := objects.PutObjectStream(ctx, bucketId, "data.json", &failingReader{r: bytes.NewReader(data), n: 25_000}, int64(len(data)), WithPartSize(10_000))
+	var uploadErr *UploadError
+	if !errors.As(err, &uploadErr) || uploadErr.UploadId == "" {
+		t.Fatalf("want an upload error, got %v", err)
+	}
+	parts, _ := objects.client.ListParts(ctx, bucketId, uploadErr.UploadId)
+	if len(parts) != 2 {
+		t.Fatalf("uploaded parts: %+v", parts)
+	}
+
+	res, err := objects.PutObjectStream(ctx, bucketId, "data.json", bytes.NewReader(data), int64(len(data)),
+		WithPartSize(10_000), WithResume(uploadErr.UploadId), WithMD5(md5Sum))
+	if err != nil || res.Parts != 5 {
+		t.Fatalf("resume: %+v, %v", res, err)
+	}
+	got, err := objects.GetObject(ctx, bucketId, res.ObjectId)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("resumed object: %d bytes, %v", len(got), err)
+	}
+}
+
+func TestObjectStreamChecksumMismatch(t *testing.T) {
+	objects, bucketId := localObjects(t)
+	ctx := context.Background()
+	data, md5Sum, _ := streamContent(30_000)
+
+	for _, partSize := range []int64{64 << 10, 10_000} {
+		_, err := objects.PutObjectStream(ctx, bucketId, "data.json", bytes.NewReader(data), int64(len(data)),
+			WithPartSize(partSize), WithSHA256(md5Sum))
+		if !errors.Is(err, scerrors.ErrInvalidArgument) {
+			t.Fatalf("part size %d: want an invalid argument, got %v", partSize, err)
+		}
+	}
+	_, err := objects.PutObjectStream(ctx, bucketId, "data.json", bytes.NewReader(data[:10]), int64(len(data)))
+	if !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("short content: %v", err)
+	}
+	if list, _ := objects.ListObjects(ctx, bucketId, "", 1, 10); list.Total != 0 {
+		t.Fatalf("corrupted objects were kept: %+v", list.Objects)
+	}
+}
+
+// objectServer serves objects like an API without multipart uploads.
+func objectServer(t *testing.T) *Object {
+	t.Helper()
+	var (
+		mu      sync.Mutex
+		objects = map[string][]byte{}
+	)
+	client := httpClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/object/buckets/b/object":
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			mu.Lock()
+			objects["o1"] = data
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(request.RespInfo{Data: map[string]any{"objectId": "o1"}})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/object/buckets/b/o1":
+			mu.Lock()
+			data := objects["o1"]
+			mu.Unlock()
+			http.ServeContent(w, r, "o1", time.Time{}, bytes.NewReader(data))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return &Object{client: client}
+}
+
+func TestObjectStreamHTTP(t *testing.T) {
+	objects := objectServer(t)
+	ctx := context.Background()
+	data, md5Sum, _ := streamContent(40_000)
+
+	// The API has no multipart upload, the object is sent in one request.
+	res, err := objects.PutObjectStream(ctx, "b", "data.json", bytes.NewReader(data), -1, WithPartSize(10_000), WithMD5(md5Sum))
+	if err != nil || res.ObjectId != "o1" || res.Parts != 0 || res.Size != int64(len(data)) {
+		t.Fatalf("put: %+v, %v", res, err)
+	}
+
+	r, err := objects.GetObjectStream(ctx, "b", "o1", WithRange(1_000, 2_000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := io.ReadAll(r)
+	_ = r.Close()
+	if !bytes.Equal(got, data[1_000:3_000]) {
+		t.Fatalf("range: %d bytes", len(got))
+	}
+	r, err = objects.GetObjectStream(ctx, "b", "o1", WithRange(50_000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = io.ReadAll(r); len(got) != 0 {
+		t.Fatalf("range after the end: %d bytes", len(got))
+	}
+	_ = r.Close()
+}
+
+// trickleReader yields its content one byte every delay.
+type trickleReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *trickleReader) Read(b []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	b[0], r.data = r.data[0], r.data[1:]
+	return 1, nil
+}
+
+func TestObjectStreamHTTPSlow(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			_ = json.NewEncoder(w).Encode(request.RespInfo{Data: map[string]any{"objectId": string(data)}})
+			return
+		}
+		for _, c := range []byte("slowly") {
+			_, _ = w.Write([]byte{c})
+			w.(http.Flusher).Flush()
+			time.Sleep(25 * time.Millisecond)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	// Both transfers take about 150ms, twice the timeout of the transport.
+	client, _ := storage_http.New(srv.URL, request.ClientConfig{
+		ApiKey:     "key",
+		HTTPClient: request.NewTransport(request.Config{Timeout: 80 * time.Millisecond}).Client(),
+	})
+	objects := &Object{client: client}
+	ctx := context.Background()
+
+	res, err := objects.PutObjectStream(ctx, "b", "data.json", &trickleReader{data: []byte("upload"), delay: 25 * time.Millisecond}, 6)
+	if err != nil || res.ObjectId != "upload" {
+		t.Fatalf("put: %+v, %v", res, err)
+	}
+	r, err := objects.GetObjectStream(ctx, "b", "o1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if got, err := io.ReadAll(r); err != nil || string(got) != "slowly" {
+		t.Fatalf("get: %q, %v", got, err)
+	}
+}
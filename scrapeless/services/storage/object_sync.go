@@ -0,0 +1,493 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/scrapeless-ai/sdk-go/internal/helper"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
+)
+
+// syncDigestKey is the metadata entry holding the SHA-256 digest of a synced
+// file, for backends which do not report the digest of objects. Backends
+// without metadata either, like the HTTP API, cannot tell unchanged files.
+const syncDigestKey = "sha256"
+
+// Actions of a SyncEntry.
+const (
+	SyncCopy   = "copy"   // The file was uploaded or downloaded
+	SyncSkip   = "skip"   // The file is unchanged
+	SyncDelete = "delete" // The file is not in the source and was deleted
+)
+
+// SyncOptions configures SyncDir and SyncDirFromBucket.
+type SyncOptions struct {
+	Prefix      string   // Prefix of the object filenames in the bucket, like "run-1/"
+	Include     []string // Globs of the relative paths to sync, all when empty
+	Exclude     []string // Globs of the relative paths not to sync
+	Concurrency int      // Files transferred at once, 4 by default
+	DryRun      bool     // Report the actions without running them
+	Delete      bool     // Delete the destination files missing from the source
+	Upload      []ObjectOption
+}
+
+// SyncOption configures SyncDir and SyncDirFromBucket.
+type SyncOption func(*SyncOptions)
+
+// WithSyncPrefix syncs the directory with the objects whose filename starts
+// with prefix, the rest of the filename being the relative path.
+func WithSyncPrefix(prefix string) SyncOption {
+	return func(o *SyncOptions) { o.Prefix = prefix }
+}
+
+// WithInclude only syncs the files whose slash-separated relative path
+// matches one of the globs, see KV.ScanKeys for the syntax. '*' also matches
+// '/', so "*.png" matches the PNG files of every directory.
+func WithInclude(globs ...string) SyncOption {
+	return func(o *SyncOptions) { o.Include = append(o.Include, globs...) }
+}
+
+// WithExclude does not sync the files whose relative path matches one of the
+// globs, even when included. Excluded files are never deleted.
+func WithExclude(globs ...string) SyncOption {
+	return func(o *SyncOptions) { o.Exclude = append(o.Exclude, globs...) }
+}
+
+// WithSyncConcurrency sets the number of files transferred at once.
+func WithSyncConcurrency(n int) SyncOption {
+	return func(o *SyncOptions) { o.Concurrency = n }
+}
+
+// WithDryRun reports what a sync would do without changing anything.
+func WithDryRun() SyncOption {
+	return func(o *SyncOptions) { o.DryRun = true }
+}
+
+// WithDeleteExtraneous deletes the destination files which are not in the
+// source, making the destination a copy of it.
+func WithDeleteExtraneous() SyncOption {
+	return func(o *SyncOptions) { o.Delete = true }
+}
+
+// WithUploadOptions applies options, like tags, to the objects uploaded by
+// SyncDir.
+func WithUploadOptions(opts ...ObjectOption) SyncOption {
+	return func(o *SyncOptions) { o.Upload = append(o.Upload, opts...) }
+}
+
+func (o *SyncOptions) match(rel string) bool {
+	for _, glob := range o.Exclude {
+		if helper.GlobMatch(glob, rel) {
+			return false
+		}
+	}
+	if len(o.Include) == 0 {
+		return true
+	}
+	for _, glob := range o.Include {
+		if helper.GlobMatch(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncEntry is the action taken on a file by a sync.
+type SyncEntry struct {
+	Path      string `json:"path"` // Slash-separated path relative to the directory
+	Action    string `json:"action"`
+	Size      int64  `json:"size"`
+	Unchecked bool   `json:"unchecked,omitempty"` // Copied over an existing file whose digest is unknown
+	Err       error  `json:"-"`                   // Why the action failed
+}
+
+// SyncResult lists the files of a sync by path.
+type SyncResult struct {
+	Entries   []SyncEntry `json:"entries"`
+	Copied    int         `json:"copied"`
+	Skipped   int         `json:"skipped"`
+	Deleted   int         `json:"deleted"`
+	Failed    int         `json:"failed"`
+	Unchecked int         `json:"unchecked"` // Files copied, maybe unchanged, because the storage does not report their digest
+	Bytes     int64       `json:"bytes"`     // Bytes copied
+}
+
+// localFile is a regular file of a synced directory.
+type localFile struct {
+	path string
+	size int64
+}
+
+// remoteFile is the newest object with a filename, and the older ones.
+type remoteFile struct {
+	object ObjectInfo
+	older  []ObjectInfo
+}
+
+func (f *remoteFile) digest() string {
+	if f.object.SHA256 != "" {
+		return strings.ToLower(f.object.SHA256)
+	}
+	return f.object.Metadata[syncDigestKey]
+}
+
+// SyncDir uploads the files of a local directory to a bucket, skipping the
+// ones whose content is already there. The objects are named after the
+// slash-separated path of the files relative to the directory. Failed
+// files do not stop the sync: they are reported in the result and the
+// returned error. Storages which neither report the digest of objects nor
+// store their metadata, like the HTTP API, cannot tell the unchanged files:
+// every file is uploaded again and counted as Unchecked.
+// Parameters:
+//
+//	ctx: The context for the requests, cancelling it stops the sync.
+//	bucketId: The bucket to upload to.
+//	localDir: The directory to upload.
+//	opts: Prefix, globs, concurrency, dry run, deletion of extraneous objects
+//	and options of the uploads.
+func (s *Object) SyncDir(ctx context.Context, bucketId string, localDir string, opts ...SyncOption) (*SyncResult, error) {
+	o := newSyncOptions(opts)
+	local, err := listLocalFiles(localDir, o)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.listRemoteFiles(ctx, bucketId, o)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tasks      []func() SyncEntry
+		noMetadata atomic.Bool // The storage refused the digest metadata
+	)
+	for rel, file := range local {
+		tasks = append(tasks, func() SyncEntry {
+			entry := SyncEntry{Path: rel, Action: SyncCopy, Size: file.size}
+			digest, err := fileDigest(file.path)
+			if err != nil {
+				entry.Err = err
+				return entry
+			}
+			prev, ok := remote[rel]
+			if ok && prev.digest() == digest {
+				entry.Action = SyncSkip
+				return entry
+			}
+			entry.Unchecked = ok && prev.digest() == ""
+			if o.DryRun {
+				return entry
+			}
+			entry.Err = s.uploadFile(ctx, bucketId, o, rel, file, digest, &noMetadata)
+			if entry.Err == nil && ok {
+				// The new object replaces the previous ones.
+				for _, object := range append(prev.older, prev.object) {
+					if _, err := s.DeleteObject(ctx, bucketId, object.Id); err != nil {
+						log.Warnf("sync: failed to delete the previous object of %s: %v", rel, err)
+					}
+				}
+			}
+			return entry
+		})
+	}
+	if o.Delete {
+		for rel, prev := range remote {
+			if _, ok := local[rel]; ok {
+				continue
+			}
+			tasks = append(tasks, func() SyncEntry {
+				entry := SyncEntry{Path: rel, Action: SyncDelete, Size: int64(prev.object.Size)}
+				if o.DryRun {
+					return entry
+				}
+				for _, object := range append(prev.older, prev.object) {
+					if _, err := s.DeleteObject(ctx, bucketId, object.Id); err != nil {
+						entry.Err = err
+					}
+				}
+				return entry
+			})
+		}
+	}
+	return runSync(ctx, o, tasks)
+}
+
+// uploadFile uploads the file with its digest in the metadata of the object,
+// or without once the storage refused metadata.
+func (s *Object) uploadFile(ctx context.Context, bucketId string, o SyncOptions, rel string, file localFile, digest string, noMetadata *atomic.Bool) error {
+	put := func(withDigest bool) error {
+		f, err := os.Open(file.path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		var opts []ObjectOption
+		if withDigest {
+			opts = append(opts, WithMetadata(map[string]string{syncDigestKey: digest}))
+		}
+		// The file may have changed since it was hashed.
+		opts = append(append(opts, o.Upload...), WithSHA256(digest))
+		_, err = s.PutObjectStream(ctx, bucketId, o.Prefix+rel, f, file.size, opts...)
+		return err
+	}
+	if noMetadata.Load() {
+		return put(false)
+	}
+	err := put(true)
+	if errors.Is(err, scerrors.ErrNotImplemented) {
+		noMetadata.Store(true)
+		return put(false)
+	}
+	return err
+}
+
+// SyncDirFromBucket downloads the objects of a bucket to a local directory,
+// skipping the files whose content is already there. It is the reverse of
+// SyncDir: the filenames of the objects are the paths of the files relative
+// to the directory, which is created if needed. Objects whose filename
+// would be outside of the directory are reported as failed.
+// Parameters:
+//
+//	ctx: The context for the requests, cancelling it stops the sync.
+//	bucketId: The bucket to download.
+//	localDir: The directory receiving the files.
+//	opts: Prefix, globs, concurrency, dry run and deletion of extraneous
+//	files.
+func (s *Object) SyncDirFromBucket(ctx context.Context, bucketId string, localDir string, opts ...SyncOption) (*SyncResult, error) {
+	o := newSyncOptions(opts)
+	remote, err := s.listRemoteFiles(ctx, bucketId, o)
+	if err != nil {
+		return nil, err
+	}
+	local, err := listLocalFiles(localDir, o)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var tasks []func() SyncEntry
+	for rel, file := range remote {
+		tasks = append(tasks, func() SyncEntry {
+			entry := SyncEntry{Path: rel, Action: SyncCopy, Size: int64(file.object.Size)}
+			if !filepath.IsLocal(filepath.FromSlash(rel)) {
+				entry.Err = scerrors.Newf(scerrors.KindInvalidArgument, "object %s is outside of the directory", file.object.Filename)
+				return entry
+			}
+			if prev, ok := local[rel]; ok && file.digest() != "" {
+				if digest, err := fileDigest(prev.path); err == nil && digest == file.digest() {
+					entry.Action = SyncSkip
+					return entry
+				}
+			} else {
+				entry.Unchecked = ok
+			}
+			if o.DryRun {
+				return entry
+			}
+			entry.Size, entry.Err = s.downloadFile(ctx, bucketId, file, filepath.Join(localDir, filepath.FromSlash(rel)))
+			return entry
+		})
+	}
+	if o.Delete {
+		for rel, file := range local {
+			if _, ok := remote[rel]; ok {
+				continue
+			}
+			tasks = append(tasks, func() SyncEntry {
+				entry := SyncEntry{Path: rel, Action: SyncDelete, Size: file.size}
+				if !o.DryRun {
+					entry.Err = os.Remove(file.path)
+				}
+				return entry
+			})
+		}
+	}
+	return runSync(ctx, o, tasks)
+}
+
+// downloadFile writes the object next to path, then renames it so that
+// readers never see a partial file.
+func (s *Object) downloadFile(ctx context.Context, bucketId string, file *remoteFile, path string) (int64, error) {
+	var opts []ObjectOption
+	if digest := file.digest(); digest != "" {
+		opts = append(opts, WithSHA256(digest))
+	}
+	r, err := s.GetObjectStream(ctx, bucketId, file.object.Id, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return n, err
+	}
+	return n, nil
+}
+
+func newSyncOptions(opts []SyncOption) SyncOptions {
+	o := SyncOptions{Concurrency: 4}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+	return o
+}
+
+// listLocalFiles returns the regular files of dir matching the globs, by
+// slash-separated relative path.
+func listLocalFiles(dir string, o SyncOptions) (map[string]localFile, error) {
+	files := map[string]localFile{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !o.match(rel) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[rel] = localFile{path: path, size: info.Size()}
+		return nil
+	})
+	if err != nil {
+		return files, fmt.Errorf("list %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// listRemoteFiles returns the objects of the bucket under the prefix and
+// matching the globs, by relative path.
+func (s *Object) listRemoteFiles(ctx context.Context, bucketId string, o SyncOptions) (map[string]*remoteFile, error) {
+	files := map[string]*remoteFile{}
+	for object, err := range s.IterObjects(ctx, bucketId, o.Prefix, pager.WithPageSize(100)) {
+		if err != nil {
+			return nil, err
+		}
+		rel, ok := strings.CutPrefix(object.Filename, o.Prefix)
+		if !ok || rel == "" || !o.match(rel) {
+			continue
+		}
+		file, ok := files[rel]
+		switch {
+		case !ok:
+			files[rel] = &remoteFile{object: object}
+		case object.CreatedAt >= file.object.CreatedAt:
+			file.older = append(file.older, file.object)
+			file.object = object
+		default:
+			file.older = append(file.older, object)
+		}
+	}
+	return files, nil
+}
+
+func fileDigest(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// runSync runs the tasks on o.Concurrency workers and gathers their entries.
+func runSync(ctx context.Context, o SyncOptions, tasks []func() SyncEntry) (*SyncResult, error) {
+	entries := make([]SyncEntry, len(tasks))
+	slots := make(chan struct{}, o.Concurrency)
+	var workers sync.WaitGroup
+	for i, task := range tasks {
+		if err := ctx.Err(); err != nil {
+			entries[i] = SyncEntry{Err: err}
+			continue
+		}
+		slots <- struct{}{}
+		workers.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				workers.Done()
+			}()
+			entries[i] = task()
+		}()
+	}
+	workers.Wait()
+
+	result := &SyncResult{}
+	var errs []error
+	for _, entry := range entries {
+		if entry.Path == "" {
+			// Not started because the context ended.
+			continue
+		}
+		switch {
+		case entry.Err != nil:
+			result.Failed++
+			errs = append(errs, fmt.Errorf("%s %s: %w", entry.Action, entry.Path, entry.Err))
+		case entry.Action == SyncCopy:
+			result.Copied++
+			result.Bytes += entry.Size
+			if entry.Unchecked {
+				result.Unchecked++
+			}
+		case entry.Action == SyncSkip:
+			result.Skipped++
+		case entry.Action == SyncDelete:
+			result.Deleted++
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+	sort.Slice(result.Entries, func(i, j int) bool {
+		if result.Entries[i].Path != result.Entries[j].Path {
+			return result.Entries[i].Path < result.Entries[j].Path
+		}
+		return result.Entries[i].Action < result.Entries[j].Action
+	})
+	if result.Unchecked > 0 {
+		log.Warnf("sync: the storage does not report the digest of objects, %d files were copied without checking whether they changed", result.Unchecked)
+	}
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func syncActions(res *SyncResult) map[string]string {
+	actions := map[string]string{}
+	for _, entry := range res.Entries {
+		actions[entry.Path] = entry.Action
+	}
+	return actions
+}
+
+// testSync runs the same syncs against a bucket of objects. Without digests
+// the storage cannot tell unchanged files, which are copied again.
+func testSync(t *testing.T, objects *Object, bucketId string, digests bool) {
+	unchanged := SyncSkip
+	if !digests {
+		unchanged = SyncCopy
+	}
+	ctx := context.Background()
+	src, dst := t.TempDir(), t.TempDir()
+	writeFiles(t, src, map[string]string{
+		"shots/1.png":  "\x89PNG\r\n\x1a\nfirst",
+		"shots/2.png":  "\x89PNG\r\n\x1a\nsecond",
+		"report.pdf":   "%PDF-1.7 report",
+		"trace.json":   `{"steps":3}`,
+		"logs/run.log": "started",
+	})
+
+	res, err := objects.SyncDir(ctx, bucketId, src, WithDryRun(), WithExclude("*.log"))
+	if err != nil || res.Copied != 4 {
+		t.Fatalf("dry run: %+v, %v", res, err)
+	}
+	if list, _ := objects.ListObjects(ctx, bucketId, "", 1, 10); list.Total != 0 {
+		t.Fatalf("dry run uploaded %d objects", list.Total)
+	}
+
+	res, err = objects.SyncDir(ctx, bucketId, src, WithExclude("*.log"), WithSyncPrefix("run/"), WithSyncConcurrency(2))
+	if err != nil || res.Copied != 4 || res.Bytes != 53 {
+		t.Fatalf("upload: %+v, %v", res, err)
+	}
+	res, err = objects.SyncDir(ctx, bucketId, src, WithExclude("*.log"), WithSyncPrefix("run/"))
+	if err != nil || digests && (res.Skipped != 4 || res.Copied != 0) || !digests && (res.Copied != 4 || res.Unchecked != 4) {
+		t.Fatalf("unchanged: %+v, %v", res, err)
+	}
+
+	writeFiles(t, src, map[string]string{"report.pdf": "%PDF-1.7 report, revised"})
+	_ = os.Remove(filepath.Join(src, "trace.json"))
+	res, err = objects.SyncDir(ctx, bucketId, src, WithExclude("*.log"), WithSyncPrefix("run/"), WithDeleteExtraneous())
+	want := map[string]string{"report.pdf": SyncCopy, "shots/1.png": unchanged, "shots/2.png": unchanged, "trace.json": SyncDelete}
+	if err != nil || !reflect.DeepEqual(syncActions(res), want) {
+		t.Fatalf("changes: %v, %v", syncActions(res), err)
+	}
+	list, _ := objects.ListObjects(ctx, bucketId, "", 1, 10)
+	if list.Total != 3 {
+		t.Fatalf("bucket has %d objects, want 3", list.Total)
+	}
+
+	res, err = objects.SyncDirFromBucket(ctx, bucketId, dst, WithSyncPrefix("run/"), WithInclude("*.pdf", "shots/*"))
+	if err != nil || res.Copied != 3 {
+		t.Fatalf("download: %+v, %v", res, err)
+	}
+	for _, name := range []string{"report.pdf", "shots/1.png", "shots/2.png"} {
+		want, _ := os.ReadFile(filepath.Join(src, name))
+		got, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("%s: %q, %v", name, got, err)
+		}
+	}
+
+	writeFiles(t, dst, map[string]string{"shots/3.png": "stale", "notes.txt": "kept"})
+	res, err = objects.SyncDirFromBucket(ctx, bucketId, dst, WithSyncPrefix("run/"), WithInclude("*.pdf", "shots/*"), WithDeleteExtraneous())
+	want = map[string]string{"report.pdf": unchanged, "shots/1.png": unchanged, "shots/2.png": unchanged, "shots/3.png": SyncDelete}
+	if err != nil || !reflect.DeepEqual(syncActions(res), want) {
+		t.Fatalf("download changes: %v, %v", syncActions(res), err)
+	}
+	if _, err = os.Stat(filepath.Join(dst, "notes.txt")); err != nil {
+		t.Fatalf("a file not included was deleted: %v", err)
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	objects, bucketId := localObjects(t)
+	testSync(t, objects, bucketId, true)
+}
+
+// bucketServer serves a bucket like the API, which neither reports the
+// digests of objects nor stores their metadata.
+func bucketServer(t *testing.T) *Object {
+	t.Helper()
+	var (
+		mu      sync.Mutex
+		next    int
+		objects = map[string]map[string]any{}
+		content = map[string][]byte{}
+	)
+	client := httpClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/object/buckets/b/")
+		switch {
+		case r.Method == http.MethodGet && id == "objects":
+			search := r.URL.Query().Get("search")
+			var list []map[string]any
+			for _, object := range objects {
+				if strings.Contains(object["filename"].(string), search) {
+					list = append(list, object)
+				}
+			}
+			// One page holds everything in these tests.
+			_ = json.NewEncoder(w).Encode(request.RespInfo{Data: map[string]any{"objects": list, "total": len(list)}})
+		case r.Method == http.MethodPost && id == "object":
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data, _ := io.ReadAll(file)
+			next++
+			id = fmt.Sprintf("o%d", next)
+			objects[id] = map[string]any{
+				"id": id, "filename": r.FormValue("filename"), "size": len(data),
+				"createdAt": time.Now().Add(time.Duration(next) * time.Millisecond).Format(time.RFC3339Nano),
+			}
+			content[id] = data
+			_ = json.NewEncoder(w).Encode(request.RespInfo{Data: map[string]any{"objectId": id}})
+		case r.Method == http.MethodGet && objects[id] != nil:
+			_, _ = w.Write(content[id])
+		case r.Method == http.MethodDelete && objects[id] != nil:
+			delete(objects, id)
+			delete(content, id)
+			_ = json.NewEncoder(w).Encode(request.RespInfo{})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return &Object{client: client}
+}
+
+func TestSyncDirHTTP(t *testing.T) {
+	testSync(t, bucketServer(t), "b", false)
+}
+
+func TestSyncDirFromBucketOutsidePaths(t *testing.T) {
+	objects, bucketId := localObjects(t)
+	ctx := context.Background()
+	if _, err := objects.PutObject(ctx, bucketId, "../escape.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "dst")
+	res, err := objects.SyncDirFromBucket(ctx, bucketId, dst)
+	if err == nil || res.Failed != 1 {
+		t.Fatalf("want a failure, got %+v, %v", res, err)
+	}
+	if _, err = os.Stat(filepath.Join(filepath.Dir(dst), "escape.json")); !os.IsNotExist(err) {
+		t.Fatalf("a file was written outside of the directory: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+var pdfHead = []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+
+func TestDetectContentType(t *testing.T) {
+	RegisterContentType(".EPUB", "application/epub+zip")
+	tests := []struct {
+		filename string
+		head     []byte
+		want     string
+		ok       bool
+	}{
+		{"data.json", nil, "application/json", true},
+		{"Photo.JPG", nil, "image/jpeg", true},
+		{"book.epub", nil, "application/epub+zip", true},
+		{"report", pdfHead, "application/pdf", true},
+		{"notes.unknown", []byte("plain words"), "text/plain", true},
+		{"setup.exe", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff"), "", false},
+		{"empty", nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := DetectContentType(tt.filename, tt.head)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("DetectContentType(%q) = %q, %v, want %q, %v", tt.filename, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestObjectMetadata(t *testing.T) {
+	objects, bucketId := localObjects(t)
+	ctx := context.Background()
+
+	puts := []struct {
+		filename string
+		data     []byte
+		opts     []ObjectOption
+	}{
+		{"invoice.pdf", pdfHead, []ObjectOption{WithTags("invoice", "2024"), WithMetadata(map[string]string{"customer": "acme"})}},
+		{"scan", pdfHead, []ObjectOption{WithTags("invoice"), WithMetadata(map[string]string{"customer": "globex"})}},
+		{"logo.png", []byte("\x89PNG\r\n\x1a\n"), []ObjectOption{WithTags("brand"), WithMetadata(map[string]string{"customer": "acme"})}},
+		{"archive.bin", []byte("anything"), []ObjectOption{WithContentType("application/x-custom"), WithTags("backup")}},
+	}
+	for _, put := range puts {
+		if _, err := objects.PutObject(ctx, bucketId, put.filename, put.data, put.opts...); err != nil {
+			t.Fatalf("%s: %v", put.filename, err)
+		}
+	}
+	if _, err := objects.PutObject(ctx, bucketId, "setup.exe", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff")); !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("unsupported type: %v", err)
+	}
+	res, err := objects.PutObjectStream(ctx, bucketId, "stream", bytes.NewReader(pdfHead), -1, WithTags("stream"))
+	if err != nil || res.Size != int64(len(pdfHead)) {
+		t.Fatalf("sniffed stream: %+v, %v", res, err)
+	}
+
+	filenames := func(filter ObjectFilter) []string {
+		t.Helper()
+		list, err := objects.ListObjects(ctx, bucketId, "", 1, 10, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, object := range list.Objects {
+			names = append(names, object.Filename)
+		}
+		return names
+	}
+	if got := filenames(ObjectFilter{Tags: []string{"invoice"}}); !reflect.DeepEqual(got, []string{"invoice.pdf", "scan"}) {
+		t.Fatalf("by tag: %v", got)
+	}
+	if got := filenames(ObjectFilter{Metadata: map[string]string{"customer": "acme"}, Tags: []string{"invoice"}}); !reflect.DeepEqual(got, []string{"invoice.pdf"}) {
+		t.Fatalf("by metadata and tag: %v", got)
+	}
+	if got := filenames(ObjectFilter{ContentType: "application/pdf"}); !reflect.DeepEqual(got, []string{"invoice.pdf", "scan", "stream"}) {
+		t.Fatalf("by content type: %v", got)
+	}
+
+	list, _ := objects.ListObjects(ctx, bucketId, "logo", 1, 10)
+	if len(list.Objects) != 1 || list.Objects[0].ContentType != "image/png" || list.Objects[0].Metadata["customer"] != "acme" ||
+		!reflect.DeepEqual(list.Objects[0].Tags, []string{"brand"}) {
+		t.Fatalf("attributes: %+v", list.Objects)
+	}
+}
+
+func TestObjectMetadataHTTP(t *testing.T) {
+	var (
+		form   map[string][]string
+		query  url.Values
+		listed []models.BucketObject
+	)
+	for i := 0; i < 150; i++ {
+		object := models.BucketObject{Id: fmt.Sprintf("o%d", i), Filename: fmt.Sprintf("photo%d.png", i)}
+		switch i % 10 {
+		case 0:
+			object.ContentType = "image/png"
+		case 5:
+			// Known from the extension of the filename.
+		default:
+			object.ContentType = "text/plain"
+		}
+		listed = append(listed, object)
+	}
+	client := httpClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/object/buckets/b/object":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			_ = file.Close()
+			form = r.MultipartForm.Value
+			form["fileContentType"] = []string{header.Header.Get("Content-Type")}
+			_ = json.NewEncoder(w).Encode(request.RespInfo{Data: map[string]any{"objectId": "o1"}})
+		case "/api/v1/object/buckets/b/objects":
+			// The API only pages through the objects matching the search.
+			query = r.URL.Query()
+			page, _ := strconv.Atoi(query.Get("page"))
+			pageSize, _ := strconv.Atoi(query.Get("pageSize"))
+			pageSize = min(pageSize, 40) // Smaller pages than filters ask for
+			start := min((page-1)*pageSize, len(listed))
+			_ = json.NewEncoder(w).Encode(request.RespInfo{Data: map[string]any{
+				"objects": listed[start:min(start+pageSize, len(listed))],
+				"total":   len(listed),
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	objects := &Object{client: client}
+	ctx := context.Background()
+
+	// The API stores neither metadata nor tags.
+	_, err := objects.PutObject(ctx, "b", "invoice.pdf", pdfHead, WithTags("a"), WithMetadata(map[string]string{"k": "v"}))
+	if !errors.Is(err, scerrors.ErrNotImplemented) || form != nil {
+		t.Fatalf("put with metadata: %v, form %v", err, form)
+	}
+	if _, err = objects.ListObjects(ctx, "b", "", 1, 10, ObjectFilter{Tags: []string{"a"}}); !errors.Is(err, scerrors.ErrNotImplemented) {
+		t.Fatalf("list by tag: %v", err)
+	}
+
+	if _, err = objects.PutObject(ctx, "b", "invoice.pdf", pdfHead); err != nil {
+		t.Fatal(err)
+	}
+	delete(form, "actorId")
+	delete(form, "runId")
+	want := map[string][]string{
+		"filename":        {"invoice.pdf"},
+		"fileContentType": {"application/pdf"},
+	}
+	if !reflect.DeepEqual(form, want) {
+		t.Fatalf("form %v, want %v", form, want)
+	}
+
+	// One object in five is a PNG, the filter is applied on the client.
+	list, err := objects.ListObjects(ctx, "b", "photo", 2, 10, ObjectFilter{ContentType: "image/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, object := range list.Objects {
+		ids = append(ids, object.Id)
+	}
+	wantIds := []string{"o50", "o55", "o60", "o65", "o70", "o75", "o80", "o85", "o90", "o95"}
+	if list.Total != 30 || !reflect.DeepEqual(ids, wantIds) {
+		t.Fatalf("objects %v of %d", ids, list.Total)
+	}
+	if query.Get("search") != "photo" || query.Has("contentType") {
+		t.Fatalf("query %v", query)
+	}
+}
@@ -3,37 +3,41 @@ package storage
 import (
 	"context"
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
+	"iter"
 	"time"
 )
 
-type Queue struct{}
+type Queue struct {
+	client storage.Queue
+}
 
 // ListQueues retrieves a list of queues with pagination and sorting options.
 // Parameters:
 //
 //	ctx: The context for the request.
 //	page: int64 - The page number (minimum 1, defaults to 1 if invalid).
-//	pageSize: int64 - Number of items per page (minimum 10, defaults to 10 if invalid).
+//	pageSize: int64 - Number of items per page (defaults to 10 if <1).
 //	desc: bool - Whether to sort results in descending order.
 func (s *Queue) ListQueues(ctx context.Context, page int64, pageSize int64, desc bool) (*ListQueuesResponse, error) {
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	queues, err := storage.ClientInterface.GetQueues(ctx, &models.GetQueuesRequest{
+	queues, err := s.client.GetQueues(ctx, &models.GetQueuesRequest{
 		Page:     page,
 		PageSize: pageSize,
 		Desc:     desc,
 	})
 	if err != nil {
-		log.Errorf("failed to list queues: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to list queues: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var items []Item
 	for _, item := range queues.Items {
@@ -46,6 +50,7 @@ func (s *Queue) ListQueues(ctx context.Context, page int64, pageSize int64, desc
 			Description: item.Description,
 			CreatedAt:   item.CreatedAt,
 			UpdatedAt:   item.UpdatedAt,
+			Stats:       QueueStats(item.Stats),
 		})
 	}
 	return &ListQueuesResponse{
@@ -57,6 +62,22 @@ func (s *Queue) ListQueues(ctx context.Context, page int64, pageSize int64, desc
 	}, nil
 }
 
+// IterQueues iterates over the queues of the current run.
+// Parameters:
+//
+//	ctx: context.Context - The request context, cancelling it ends the iteration.
+//	desc: bool - Whether to sort results in descending order.
+//	opts: ...pager.Option - Page size, prefetch and limit.
+func (s *Queue) IterQueues(ctx context.Context, desc bool, opts ...pager.Option) iter.Seq2[Item, error] {
+	return pager.Seq(ctx, func(ctx context.Context, page, pageSize int64) ([]Item, int64, error) {
+		resp, err := s.ListQueues(ctx, page, pageSize, desc)
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Items, resp.Total, nil
+	}, opts...)
+}
+
 // CreateQueue creates a new HTTP queue with the provided request parameters.
 // Parameters:
 //
@@ -64,15 +85,15 @@ func (s *Queue) ListQueues(ctx context.Context, page int64, pageSize int64, desc
 //	req: The request object containing queue configuration details.
 func (s *Queue) CreateQueue(ctx context.Context, req *CreateQueueReq) (queueId string, queueName string, err error) {
 	name := req.Name + "-" + env.GetActorEnv().RunId
-	queue, err := storage.ClientInterface.CreateQueue(ctx, &models.CreateQueueRequest{
+	queue, err := s.client.CreateQueue(ctx, &models.CreateQueueRequest{
 		ActorId:     env.GetActorEnv().ActorId,
 		RunId:       env.GetActorEnv().RunId,
 		Name:        name,
 		Description: req.Description,
 	})
 	if err != nil {
-		log.Errorf("failed to create queue: %v", code.Format(err))
-		return "", "", code.Format(err)
+		log.Errorf("failed to create queue: %v", err)
+		return "", "", scerrors.From(err)
 	}
 
 	return queue.Id, name, nil
@@ -85,13 +106,13 @@ func (s *Queue) CreateQueue(ctx context.Context, req *CreateQueueReq) (queueId s
 //	name: The name of the queue to retrieve.
 func (s *Queue) GetQueue(ctx context.Context, queueId string, name string) (*Item, error) {
 	name = name + "-" + env.GetActorEnv().RunId
-	queue, err := storage.ClientInterface.GetQueue(ctx, &models.GetQueueRequest{
+	queue, err := s.client.GetQueue(ctx, &models.GetQueueRequest{
 		Id:   queueId,
 		Name: name,
 	})
 	if err != nil {
-		log.Errorf("failed to get queue: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to get queue: %v", err)
+		return nil, scerrors.From(err)
 	}
 	return &Item{
 		Id:          queue.Id,
@@ -102,6 +123,7 @@ func (s *Queue) GetQueue(ctx context.Context, queueId string, name string) (*Ite
 		Description: queue.Description,
 		CreatedAt:   queue.CreatedAt,
 		UpdatedAt:   queue.UpdatedAt,
+		Stats:       QueueStats(queue.Stats),
 	}, nil
 }
 
@@ -113,7 +135,7 @@ func (s *Queue) GetQueue(ctx context.Context, queueId string, name string) (*Ite
 //	description: The new description of the queue.
 func (s *Queue) UpdateQueue(ctx context.Context, queueId string, name string, description string) error {
 	name = name + "-" + env.GetActorEnv().RunId
-	err := storage.ClientInterface.UpdateQueue(ctx, &models.UpdateQueueRequest{
+	err := s.client.UpdateQueue(ctx, &models.UpdateQueueRequest{
 		QueueId:     queueId,
 		Name:        name,
 		Description: description,
@@ -126,10 +148,10 @@ func (s *Queue) UpdateQueue(ctx context.Context, queueId string, name string, de
 //
 //	ctx: The context for the request.
 func (s *Queue) DeleteQueue(ctx context.Context, queueId string) error {
-	err := storage.ClientInterface.DelQueue(ctx, &models.DelQueueRequest{QueueId: queueId})
+	err := s.client.DelQueue(ctx, &models.DelQueueRequest{QueueId: queueId})
 	if err != nil {
-		log.Errorf("failed to delete queue: %v", code.Format(err))
-		return code.Format(err)
+		log.Errorf("failed to delete queue: %v", err)
+		return scerrors.From(err)
 	}
 	return nil
 }
@@ -158,7 +180,7 @@ func (s *Queue) Push(ctx context.Context, queueId string, req PushQueue) (string
 	}
 
 	unix := time.Now().UTC().Add(time.Duration(req.Deadline) * time.Second).Unix()
-	queue, err := storage.ClientInterface.CreateMsg(ctx, &models.CreateMsgRequest{
+	queue, err := s.client.CreateMsg(ctx, &models.CreateMsgRequest{
 		QueueId:  queueId,
 		Name:     req.Name,
 		PayLoad:  string(req.Payload),
@@ -167,8 +189,8 @@ func (s *Queue) Push(ctx context.Context, queueId string, req PushQueue) (string
 		Deadline: unix,
 	})
 	if err != nil {
-		log.Errorf("failed to push to queue: %v", code.Format(err))
-		return "", code.Format(err)
+		log.Errorf("failed to push to queue: %v", err)
+		return "", scerrors.From(err)
 	}
 	return queue.MsgId, nil
 }
@@ -185,13 +207,13 @@ func (s *Queue) Pull(ctx context.Context, queueId string, size int32) (GetMsgRes
 	if size > 100 {
 		size = 100
 	}
-	msgs, err := storage.ClientInterface.GetMsg(ctx, &models.GetMsgRequest{
+	msgs, err := s.client.GetMsg(ctx, &models.GetMsgRequest{
 		QueueId: queueId,
 		Limit:   size,
 	})
 	if err != nil {
-		log.Errorf("failed to pull from queue: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to pull from queue: %v", err)
+		return nil, scerrors.From(err)
 	}
 	if msgs == nil {
 		return nil, nil
@@ -222,13 +244,13 @@ func (s *Queue) Pull(ctx context.Context, queueId string, size int32) (GetMsgRes
 //	ctx: The context used for request cancellation or timeout.
 //	msgId: The unique identifier of the message to acknowledge.
 func (s *Queue) Ack(ctx context.Context, queueId string, msgId string) error {
-	err := storage.ClientInterface.AckMsg(ctx, &models.AckMsgRequest{
+	err := s.client.AckMsg(ctx, &models.AckMsgRequest{
 		QueueId: queueId,
 		MsgId:   msgId,
 	})
 	if err != nil {
-		log.Errorf("failed to ack msg: %v", code.Format(err))
-		return code.Format(err)
+		log.Errorf("failed to ack msg: %v", err)
+		return scerrors.From(err)
 	}
 	return nil
 }
@@ -0,0 +1,445 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+const (
+	defaultConsumerWorkers      = 1
+	defaultConsumerPollInterval = time.Second
+	defaultRetryBackoff         = time.Second
+	defaultMaxRetryBackoff      = time.Minute
+	maxPushDeadline             = 86400
+	minPushDeadline             = 300
+)
+
+// QueueHandler processes one message. Returning nil acknowledges it; an error
+// hands it to the failure policy of the consumer.
+type QueueHandler func(ctx context.Context, msg *Msg) error
+
+// FailurePolicy tells a QueueConsumer what to do with a message whose
+// handler failed and that has retries left.
+type FailurePolicy int
+
+const (
+	// FailureNack leaves the message unacknowledged, so the queue delivers it
+	// again once its Timeout has elapsed.
+	FailureNack FailurePolicy = iota
+	// FailureRequeue acknowledges the message and pushes a copy with one
+	// retry less after a backoff delay.
+	FailureRequeue
+)
+
+// ConsumerOptions configures a QueueConsumer.
+type ConsumerOptions struct {
+	Workers           int           // Messages handled concurrently
+	PollInterval      time.Duration // Wait before pulling again from an empty queue or after a failed pull
+	HandlerTimeout    time.Duration // Limit of one handler call; defaults to the Timeout of the message
+	OnFailure         FailurePolicy
+	RetryBackoff      time.Duration // Delay before the first requeue, doubled for every further one
+	MaxRetryBackoff   time.Duration // Upper bound of the requeue delay
+	DeadLetterQueueId string        // Queue receiving messages out of retries; empty drops them
+	OnError           func(msg *Msg, err error)
+}
+
+// ConsumerOption configures a QueueConsumer.
+type ConsumerOption func(*ConsumerOptions)
+
+// WithWorkers sets how many messages are handled concurrently.
+func WithWorkers(n int) ConsumerOption {
+	return func(o *ConsumerOptions) { o.Workers = n }
+}
+
+// WithPollInterval sets the wait before pulling again from an empty queue.
+func WithPollInterval(d time.Duration) ConsumerOption {
+	return func(o *ConsumerOptions) { o.PollInterval = d }
+}
+
+// WithHandlerTimeout bounds every handler call.
+func WithHandlerTimeout(d time.Duration) ConsumerOption {
+	return func(o *ConsumerOptions) { o.HandlerTimeout = d }
+}
+
+// WithNack lets failed messages be redelivered by the queue after their
+// Timeout. This is the default.
+func WithNack() ConsumerOption {
+	return func(o *ConsumerOptions) { o.OnFailure = FailureNack }
+}
+
+// WithRequeue acknowledges failed messages and pushes them again after an
+// exponential backoff starting at initial and capped at maxBackoff.
+func WithRequeue(initial, maxBackoff time.Duration) ConsumerOption {
+	return func(o *ConsumerOptions) {
+		o.OnFailure = FailureRequeue
+		o.RetryBackoff = initial
+		o.MaxRetryBackoff = maxBackoff
+	}
+}
+
+// WithDeadLetterQueue moves messages that ran out of retries to queueId.
+func WithDeadLetterQueue(queueId string) ConsumerOption {
+	return func(o *ConsumerOptions) { o.DeadLetterQueueId = queueId }
+}
+
+// WithErrorHandler registers a callback invoked for every handler failure
+// and every failed queue operation on a message.
+func WithErrorHandler(fn func(msg *Msg, err error)) ConsumerOption {
+	return func(o *ConsumerOptions) { o.OnError = fn }
+}
+
+func newConsumerOptions(opts []ConsumerOption) ConsumerOptions {
+	o := ConsumerOptions{
+		Workers:         defaultConsumerWorkers,
+		PollInterval:    defaultConsumerPollInterval,
+		RetryBackoff:    defaultRetryBackoff,
+		MaxRetryBackoff: defaultMaxRetryBackoff,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.Workers = max(o.Workers, 1)
+	if o.PollInterval <= 0 {
+		o.PollInterval = defaultConsumerPollInterval
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = defaultRetryBackoff
+	}
+	o.MaxRetryBackoff = max(o.MaxRetryBackoff, o.RetryBackoff)
+	return o
+}
+
+// ConsumerStats counts what a QueueConsumer did with the messages it pulled.
+type ConsumerStats struct {
+	Handled      int64 // Handler calls
+	Acked        int64 // Messages handled successfully
+	Failed       int64 // Handler calls that returned an error
+	Requeued     int64 // Copies pushed back by FailureRequeue
+	DeadLettered int64 // Messages moved to the dead-letter queue
+	Dropped      int64 // Messages out of retries without a dead-letter queue
+}
+
+// QueueConsumer pulls messages from a queue and hands them to a pool of
+// workers. A message is acknowledged once its handler succeeds. On failure
+// it is nacked or requeued while it has retries left, then moved to the
+// dead-letter queue.
+//
+// Retries follow the counters of the message: Retry is the number of
+// deliveries allowed and Retried the number made so far, this one included.
+type QueueConsumer struct {
+	queue   *Queue
+	queueId string
+	handler QueueHandler
+	opts    ConsumerOptions
+
+	mu       sync.Mutex
+	running  bool
+	stop     chan struct{}
+	done     chan struct{}
+	cancel   context.CancelFunc
+	attempts map[string]int // Requeue count of messages pushed back by this consumer
+	timers   sync.WaitGroup
+
+	handled, acked, failed, requeued, deadLettered, dropped atomic.Int64
+}
+
+// NewConsumer creates a consumer of queueId. Call Run to start it.
+// Parameters:
+//
+//	queueId: The queue to consume.
+//	handler: Called for every message.
+//	opts: Worker count, failure policy and dead-letter queue.
+func (s *Queue) NewConsumer(queueId string, handler QueueHandler, opts ...ConsumerOption) *QueueConsumer {
+	return &QueueConsumer{
+		queue:    s,
+		queueId:  queueId,
+		handler:  handler,
+		opts:     newConsumerOptions(opts),
+		attempts: make(map[string]int),
+	}
+}
+
+// Run consumes messages until ctx ends or Stop is called, then waits for the
+// messages being handled. It returns nil after Stop and the context error
+// otherwise.
+func (c *QueueConsumer) Run(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return scerrors.New(scerrors.KindConflict, "queue consumer is already running")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c.running = true
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	c.cancel = cancel
+	stop, done := c.stop, c.done
+	c.mu.Unlock()
+
+	defer func() {
+		cancel()
+		c.mu.Lock()
+		c.running = false
+		c.mu.Unlock()
+		close(done)
+	}()
+
+	slots := make(chan struct{}, c.opts.Workers)
+	var workers sync.WaitGroup
+	defer func() {
+		workers.Wait()
+		// Pending requeues are pushed right away rather than lost.
+		c.timers.Wait()
+	}()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		case slots <- struct{}{}:
+		}
+		// Pull as many messages as there are idle workers.
+		free := 1
+	grab:
+		for free < c.opts.Workers {
+			select {
+			case slots <- struct{}{}:
+				free++
+			default:
+				break grab
+			}
+		}
+
+		msgs, err := c.queue.Pull(ctx, c.queueId, int32(free))
+		if err != nil && ctx.Err() == nil {
+			log.Warnf("queue consumer: pull from %s failed: %v", c.queueId, err)
+		}
+		for _, msg := range msgs {
+			workers.Add(1)
+			go func(msg *Msg) {
+				defer func() {
+					<-slots
+					workers.Done()
+				}()
+				c.process(ctx, msg)
+			}(msg)
+		}
+		for range free - len(msgs) {
+			<-slots
+		}
+		if len(msgs) == 0 {
+			select {
+			case <-stop:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.opts.PollInterval):
+			}
+		}
+	}
+}
+
+// Stop stops pulling messages and waits for the ones being handled. If ctx
+// ends first, the handlers still running are cancelled and Stop returns the
+// context error.
+func (c *QueueConsumer) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	select {
+	case <-c.stop:
+	default:
+		close(c.stop)
+	}
+	done, cancel := c.done, c.cancel
+	c.mu.Unlock()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// Stats returns the counters of the consumer.
+func (c *QueueConsumer) Stats() ConsumerStats {
+	return ConsumerStats{
+		Handled:      c.handled.Load(),
+		Acked:        c.acked.Load(),
+		Failed:       c.failed.Load(),
+		Requeued:     c.requeued.Load(),
+		DeadLettered: c.deadLettered.Load(),
+		Dropped:      c.dropped.Load(),
+	}
+}
+
+func (c *QueueConsumer) process(ctx context.Context, msg *Msg) {
+	timeout := c.opts.HandlerTimeout
+	if timeout <= 0 && msg.Timeout > 0 {
+		timeout = time.Duration(msg.Timeout) * time.Second
+	}
+	hctx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		hctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	c.handled.Add(1)
+	err := c.call(hctx, msg)
+	if err == nil {
+		c.forget(msg.ID)
+		if err = c.queue.Ack(context.WithoutCancel(ctx), c.queueId, msg.ID); err != nil {
+			c.report(msg, err)
+			return
+		}
+		c.acked.Add(1)
+		return
+	}
+	c.failed.Add(1)
+	if ctx.Err() != nil {
+		// Shutting down: leave the message to be delivered again without
+		// spending one of its retries here.
+		return
+	}
+	c.report(msg, err)
+
+	remaining := msg.Retry - msg.Retried
+	expired := msg.Deadline > 0 && time.Now().Unix() >= msg.Deadline
+	if remaining > 0 && !expired {
+		if c.opts.OnFailure == FailureRequeue {
+			c.scheduleRequeue(ctx, msg, remaining)
+		}
+		return
+	}
+	c.forget(msg.ID)
+	c.deadLetter(context.WithoutCancel(ctx), msg)
+}
+
+// call runs the handler, turning a panic into an error.
+func (c *QueueConsumer) call(ctx context.Context, msg *Msg) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = scerrors.Newf(scerrors.KindInternal, "queue handler panicked: %v", r)
+		}
+	}()
+	return c.handler(ctx, msg)
+}
+
+func (c *QueueConsumer) scheduleRequeue(ctx context.Context, msg *Msg, remaining int64) {
+	c.mu.Lock()
+	attempt := c.attempts[msg.ID]
+	delete(c.attempts, msg.ID)
+	c.mu.Unlock()
+
+	delay := c.opts.RetryBackoff << attempt
+	if delay <= 0 || delay > c.opts.MaxRetryBackoff {
+		delay = c.opts.MaxRetryBackoff
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+	// Push before the lease of the message expires, or the queue would
+	// deliver it again on its own.
+	if msg.Timeout > 0 {
+		delay = min(delay, time.Duration(msg.Timeout)*time.Second/2)
+	}
+
+	c.timers.Add(1)
+	go func() {
+		defer c.timers.Done()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+		case <-c.stopped():
+		}
+		bg := context.WithoutCancel(ctx)
+		id, err := c.queue.Push(bg, c.queueId, c.copyOf(msg, remaining))
+		if err != nil {
+			// The original stays unacknowledged and is delivered again.
+			c.report(msg, err)
+			return
+		}
+		c.mu.Lock()
+		c.attempts[id] = attempt + 1
+		c.mu.Unlock()
+		c.requeued.Add(1)
+		if err = c.queue.Ack(bg, c.queueId, msg.ID); err != nil {
+			c.report(msg, err)
+		}
+	}()
+}
+
+func (c *QueueConsumer) deadLetter(ctx context.Context, msg *Msg) {
+	if c.opts.DeadLetterQueueId == "" {
+		if c.opts.OnFailure == FailureRequeue {
+			// A requeued copy has no further delivery to wait for.
+			if err := c.queue.Ack(ctx, c.queueId, msg.ID); err != nil {
+				c.report(msg, err)
+			}
+		}
+		log.Warnf("queue consumer: message %s of %s is out of retries, dropping it", msg.ID, c.queueId)
+		c.dropped.Add(1)
+		return
+	}
+	dead := c.copyOf(msg, msg.Retry)
+	dead.Deadline = maxPushDeadline
+	if _, err := c.queue.Push(ctx, c.opts.DeadLetterQueueId, dead); err != nil {
+		c.report(msg, err)
+		return
+	}
+	c.deadLettered.Add(1)
+	if err := c.queue.Ack(ctx, c.queueId, msg.ID); err != nil {
+		c.report(msg, err)
+	}
+}
+
+func (c *QueueConsumer) copyOf(msg *Msg, retry int64) PushQueue {
+	deadline := int64(minPushDeadline)
+	if msg.Deadline > 0 {
+		deadline = max(msg.Deadline-time.Now().Unix(), minPushDeadline)
+	}
+	return PushQueue{
+		Name:     msg.Name,
+		Payload:  []byte(msg.Payload),
+		Retry:    retry,
+		Timeout:  msg.Timeout,
+		Deadline: min(deadline, maxPushDeadline),
+	}
+}
+
+func (c *QueueConsumer) forget(id string) {
+	c.mu.Lock()
+	delete(c.attempts, id)
+	c.mu.Unlock()
+}
+
+func (c *QueueConsumer) stopped() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stop
+}
+
+func (c *QueueConsumer) report(msg *Msg, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	log.Errorf("queue consumer: message %s of %s: %v", msg.ID, c.queueId, err)
+	if c.opts.OnError != nil {
+		c.opts.OnError(msg, err)
+	}
+}
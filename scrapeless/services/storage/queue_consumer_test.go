@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newLocalQueue(t *testing.T, names ...string) (*Queue, []string) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	var ids []string
+	for _, name := range names {
+		id, _, err := s.Queue.CreateQueue(ctx, &CreateQueueReq{Name: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	return s.Queue, ids
+}
+
+func TestQueueConsumer(t *testing.T) {
+	q, ids := newLocalQueue(t, "work", "dead")
+	work, dead := ids[0], ids[1]
+	ctx := context.Background()
+	for _, msg := range []PushQueue{
+		{Name: "ok-1", Payload: []byte("1"), Retry: 3},
+		{Name: "ok-2", Payload: []byte("2"), Retry: 3},
+		{Name: "bad", Payload: []byte("x"), Retry: 2},
+	} {
+		if _, err := q.Push(ctx, work, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var badCalls atomic.Int32
+	consumer := q.NewConsumer(work, func(ctx context.Context, msg *Msg) error {
+		if msg.Name == "bad" {
+			badCalls.Add(1)
+			return errors.New("cannot handle")
+		}
+		return nil
+	},
+		WithWorkers(3),
+		WithPollInterval(5*time.Millisecond),
+		WithRequeue(time.Millisecond, 2*time.Millisecond),
+		WithDeadLetterQueue(dead),
+	)
+	errc := make(chan error, 1)
+	go func() { errc <- consumer.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for consumer.Stats().DeadLettered == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := consumer.Stop(stopCtx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+
+	stats := consumer.Stats()
+	if stats.Acked != 2 || stats.Requeued != 1 || stats.DeadLettered != 1 || badCalls.Load() != 2 {
+		t.Fatalf("unexpected stats %+v after %d bad calls", stats, badCalls.Load())
+	}
+	msgs, err := q.Pull(ctx, dead, 10)
+	if err != nil || len(msgs) != 1 || msgs[0].Name != "bad" || msgs[0].Payload != "x" {
+		t.Fatalf("dead letter queue: %v, %v", msgs, err)
+	}
+	if msgs, _ = q.Pull(ctx, work, 10); len(msgs) != 0 {
+		t.Fatalf("work queue should be drained, got %d messages", len(msgs))
+	}
+}
+
+func TestQueueConsumerGracefulStop(t *testing.T) {
+	q, ids := newLocalQueue(t, "slow")
+	ctx := context.Background()
+	if _, err := q.Push(ctx, ids[0], PushQueue{Name: "slow", Retry: 1}); err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	consumer := q.NewConsumer(ids[0], func(ctx context.Context, msg *Msg) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return ctx.Err()
+	}, WithPollInterval(5*time.Millisecond))
+	errc := make(chan error, 1)
+	go func() { errc <- consumer.Run(ctx) }()
+	<-started
+
+	if err := consumer.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if stats := consumer.Stats(); stats.Acked != 1 {
+		t.Fatalf("in-flight message was not finished: %+v", stats)
+	}
+	if err := consumer.Stop(ctx); err != nil {
+		t.Fatal("stopping twice should be harmless")
+	}
+}
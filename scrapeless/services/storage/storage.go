@@ -1,12 +1,12 @@
 package storage
 
 import (
-	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
-	"sync"
 )
 
 type Storage struct {
+	client storage.Storage
 	*Dataset
 	*KV
 	*Object
@@ -14,28 +14,21 @@ type Storage struct {
 	*Vector
 }
 
-var (
-	defaultStorage *Storage
-	once           = sync.Once{}
-)
-
-func init() {
-	defaultStorage = &Storage{
-		Dataset: &Dataset{},
-		KV:      &KV{},
-		Object:  &Object{},
-		Queue:   &Queue{},
-		Vector:  &Vector{},
+// NewStorage creates the storage services on top of one backend. cfg
+// overrides the credentials and endpoints read from the environment.
+func NewStorage(serverMode string, cfg ...request.ClientConfig) *Storage {
+	c := request.ClientConfigOf(cfg...)
+	client := storage.NewClient(serverMode, c.StorageUrl, c)
+	return &Storage{
+		client:  client,
+		Dataset: &Dataset{client: client},
+		KV:      &KV{client: client},
+		Object:  &Object{client: client},
+		Queue:   &Queue{client: client},
+		Vector:  &Vector{client: client},
 	}
 }
 
-func NewStorage(serverMode string) *Storage {
-	once.Do(func() {
-		storage.NewClient(serverMode, env.Env.ScrapelessStorageUrl)
-	})
-	return defaultStorage
-}
-
 func (s *Storage) Close() error {
-	return nil
+	return s.client.Close()
 }
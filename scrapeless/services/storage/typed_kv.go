@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"time"
+)
+
+// Codec converts values to and from the strings stored in a KV namespace.
+type Codec interface {
+	Encode(v any) (string, error)
+	Decode(data string, v any) error
+}
+
+// Codecs available to TypedKV. Binary encodings are stored as base64.
+var (
+	JSONCodec    Codec = jsonCodec{}
+	GobCodec     Codec = binaryCodec{marshal: gobMarshal, unmarshal: gobUnmarshal}
+	MsgpackCodec Codec = binaryCodec{marshal: msgpack.Marshal, unmarshal: msgpack.Unmarshal}
+)
+
+// Gzip returns a codec that compresses the output of c, which pays off for
+// large, repetitive values.
+func Gzip(c Codec) Codec {
+	return gzipCodec{inner: c}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encode(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func (jsonCodec) Decode(data string, v any) error {
+	return json.Unmarshal([]byte(data), v)
+}
+
+type binaryCodec struct {
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+func (c binaryCodec) Encode(v any) (string, error) {
+	data, err := c.marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (c binaryCodec) Decode(data string, v any) error {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(raw, v)
+}
+
+func gobMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func gobUnmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type gzipCodec struct {
+	inner Codec
+}
+
+func (c gzipCodec) Encode(v any) (string, error) {
+	data, err := c.inner.Encode(v)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err = io.WriteString(zw, data); err != nil {
+		return "", err
+	}
+	if err = zw.Close(); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func (c gzipCodec) Decode(data string, v any) error {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	plain, err := io.ReadAll(zr)
+	if err != nil {
+		return err
+	}
+	return c.inner.Decode(string(plain), v)
+}
+
+// TypedKVOptions controls how a TypedKV stores its values.
+type TypedKVOptions struct {
+	Codec Codec         // Encoding of the values, JSONCodec by default
+	TTL   time.Duration // Lifetime of the values written by Set and BulkSet; 0 keeps the backend default
+}
+
+// TypedKVOption configures a TypedKV.
+type TypedKVOption func(*TypedKVOptions)
+
+// WithCodec sets the encoding of the values.
+func WithCodec(c Codec) TypedKVOption {
+	return func(o *TypedKVOptions) { o.Codec = c }
+}
+
+// WithTTL sets the lifetime of the values written by Set and BulkSet.
+func WithTTL(ttl time.Duration) TypedKVOption {
+	return func(o *TypedKVOptions) { o.TTL = ttl }
+}
+
+// TypedKV stores values of type T in a KV namespace, encoding them with a
+// Codec. A missing or expired key is reported by Get as an error matching
+// errors.ErrNotFound, whatever the backend.
+type TypedKV[T any] struct {
+	kv          *KV
+	namespaceId string
+	opts        TypedKVOptions
+}
+
+// NewTypedKV returns a typed view of a KV namespace.
+// Parameters:
+//
+//	kv: The KV service, HTTP or local.
+//	namespaceId: The namespace holding the values.
+//	opts: The codec and the default lifetime of the values.
+func NewTypedKV[T any](kv *KV, namespaceId string, opts ...TypedKVOption) *TypedKV[T] {
+	o := TypedKVOptions{Codec: JSONCodec}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Codec == nil {
+		o.Codec = JSONCodec
+	}
+	return &TypedKV[T]{kv: kv, namespaceId: namespaceId, opts: o}
+}
+
+// Get returns the value stored at key.
+// Parameters:
+//
+//	ctx: Request context
+//	key: The key to read
+func (t *TypedKV[T]) Get(ctx context.Context, key string) (T, error) {
+	var value T
+	data, err := t.kv.GetValue(ctx, t.namespaceId, key)
+	if err != nil {
+		return value, err
+	}
+	if err = t.opts.Codec.Decode(data, &value); err != nil {
+		return value, scerrors.Newf(scerrors.KindInternal, "decode value of %q: %v", key, err)
+	}
+	return value, nil
+}
+
+// Set stores value at key with the lifetime of the TypedKV.
+// Parameters:
+//
+//	ctx: Request context
+//	key: The key to write
+//	value: The value to store
+func (t *TypedKV[T]) Set(ctx context.Context, key string, value T) error {
+	return t.SetWithTTL(ctx, key, value, t.opts.TTL)
+}
+
+// SetWithTTL stores value at key for ttl, rounded up to the second.
+// Parameters:
+//
+//	ctx: Request context
+//	key: The key to write
+//	value: The value to store
+//	ttl: Lifetime of the value; 0 keeps the backend default
+func (t *TypedKV[T]) SetWithTTL(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := t.encode(key, value)
+	if err != nil {
+		return err
+	}
+	_, err = t.kv.SetValue(ctx, t.namespaceId, key, data, expiration(ttl))
+	return err
+}
+
+// BulkSet stores several values at once with the lifetime of the TypedKV and
+// returns how many were written.
+// Parameters:
+//
+//	ctx: Request context
+//	values: The values to store by key
+func (t *TypedKV[T]) BulkSet(ctx context.Context, values map[string]T) (int64, error) {
+	items := make([]BulkItem, 0, len(values))
+	for key, value := range values {
+		data, err := t.encode(key, value)
+		if err != nil {
+			return 0, err
+		}
+		items = append(items, BulkItem{Key: key, Value: data, Expiration: expiration(t.opts.TTL)})
+	}
+	return t.kv.BulkSetValue(ctx, t.namespaceId, items)
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+// Parameters:
+//
+//	ctx: Request context
+//	key: The key to delete
+func (t *TypedKV[T]) Delete(ctx context.Context, key string) error {
+	_, err := t.kv.DelValue(ctx, t.namespaceId, key)
+	if errors.Is(err, scerrors.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// GetOrCompute returns the value stored at key. When there is none, it calls
+// compute, stores its result and returns it. Concurrent callers may all
+// compute the value; the last write wins.
+// Parameters:
+//
+//	ctx: Request context
+//	key: The key to read
+//	compute: Builds the value when the key is missing
+func (t *TypedKV[T]) GetOrCompute(ctx context.Context, key string, compute func(ctx context.Context) (T, error)) (T, error) {
+	value, err := t.Get(ctx, key)
+	if !errors.Is(err, scerrors.ErrNotFound) {
+		return value, err
+	}
+	if value, err = compute(ctx); err != nil {
+		return value, err
+	}
+	return value, t.Set(ctx, key, value)
+}
+
+func (t *TypedKV[T]) encode(key string, value T) (string, error) {
+	data, err := t.opts.Codec.Encode(value)
+	if err != nil {
+		return "", scerrors.Newf(scerrors.KindInvalidArgument, "encode value of %q: %v", key, err)
+	}
+	return data, nil
+}
+
+// expiration converts a lifetime to the seconds expected by the KV API.
+func expiration(ttl time.Duration) uint {
+	if ttl <= 0 {
+		return 0
+	}
+	return uint((ttl + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,198 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+type product struct {
+	Name   string
+	Price  float64
+	Tags   []string
+	Stocks map[string]int
+}
+
+// kvServer is a minimal in-memory implementation of the HTTP KV API.
+func kvServer(t *testing.T) *KV {
+	t.Helper()
+	var (
+		mu     sync.Mutex
+		values = map[string]string{}
+	)
+	reply := func(w http.ResponseWriter, data any) {
+		_ = json.NewEncoder(w).Encode(request.RespInfo{Data: data})
+	}
+	client := httpClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/kv/ns/")
+		switch {
+		case r.Method == http.MethodPut && path == "key":
+			var body struct{ Key, Value string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			values[body.Key] = body.Value
+			reply(w, nil)
+		case r.Method == http.MethodPost && path == "bulk":
+			var body struct{ Items []struct{ Key, Value string } }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			for _, item := range body.Items {
+				values[item.Key] = item.Value
+			}
+			reply(w, map[string]any{"successfulKeyCount": len(body.Items)})
+		case r.Method == http.MethodGet && path == "keys":
+			keys := slices.Sorted(maps.Keys(values))
+			page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+			size, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+			size = min(size, 50) // Smaller pages than scans ask for
+			items := []map[string]any{}
+			for _, key := range keys[min((page-1)*size, len(keys)):min(page*size, len(keys))] {
+				items = append(items, map[string]any{"key": key, "size": len(values[key])})
+			}
+			reply(w, map[string]any{"items": items, "total": len(keys), "page": page, "pageSize": size})
+		case r.Method == http.MethodGet:
+			if v, ok := values[path]; ok {
+				reply(w, v)
+			} else {
+				reply(w, nil)
+			}
+		case r.Method == http.MethodDelete:
+			delete(values, path)
+			reply(w, nil)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return &KV{client: client}
+}
+
+func localKV(t *testing.T) (*KV, string) {
+	s := newLocalStorage(t)
+	id, _, err := s.KV.CreateNamespace(context.Background(), "typed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.KV, id
+}
+
+func testTypedKV(t *testing.T, kv *KV, namespaceId string, codec Codec) {
+	ctx := context.Background()
+	store := NewTypedKV[product](kv, namespaceId, WithCodec(codec), WithTTL(time.Hour))
+	want := product{Name: "lamp", Price: 12.5, Tags: []string{"home"}, Stocks: map[string]int{"paris": 3}}
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, scerrors.ErrNotFound) {
+		t.Fatalf("missing key: %v", err)
+	}
+	if err := store.Set(ctx, "lamp", want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(ctx, "lamp")
+	if err != nil || got.Name != want.Name || got.Price != want.Price || got.Stocks["paris"] != 3 {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+
+	n, err := store.BulkSet(ctx, map[string]product{"a": {Name: "a"}, "b": {Name: "b"}})
+	if err != nil || n != 2 {
+		t.Fatalf("bulk set: %d, %v", n, err)
+	}
+	if got, err = store.Get(ctx, "b"); err != nil || got.Name != "b" {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+
+	calls := 0
+	compute := func(ctx context.Context) (product, error) {
+		calls++
+		return product{Name: "computed"}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if got, err = store.GetOrCompute(ctx, "lazy", compute); err != nil || got.Name != "computed" {
+			t.Fatalf("get or compute: %+v, %v", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("computed %d times", calls)
+	}
+
+	if err = store.Delete(ctx, "lamp"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Get(ctx, "lamp"); !errors.Is(err, scerrors.ErrNotFound) {
+		t.Fatalf("deleted key: %v", err)
+	}
+}
+
+func TestTypedKV(t *testing.T) {
+	codecs := map[string]Codec{
+		"json":         JSONCodec,
+		"gob":          GobCodec,
+		"msgpack":      MsgpackCodec,
+		"gzip-json":    Gzip(JSONCodec),
+		"gzip-msgpack": Gzip(MsgpackCodec),
+	}
+	for name, codec := range codecs {
+		t.Run(name+"/http", func(t *testing.T) {
+			testTypedKV(t, kvServer(t), "ns", codec)
+		})
+		t.Run(name+"/local", func(t *testing.T) {
+			kv, id := localKV(t)
+			testTypedKV(t, kv, id, codec)
+		})
+	}
+}
+
+func TestTypedKVExpiration(t *testing.T) {
+	if got := expiration(1500 * time.Millisecond); got != 2 {
+		t.Fatalf("expiration rounds up to the second, got %d", got)
+	}
+	kv, id := localKV(t)
+	store := NewTypedKV[int](kv, id)
+	if err := store.SetWithTTL(context.Background(), "short", 1, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := store.Get(context.Background(), "short"); !errors.Is(err, scerrors.ErrNotFound) {
+		t.Fatalf("expired key: %v", err)
+	}
+}
+
+// rawCodec stores strings as they are, the empty string included.
+type rawCodec struct{}
+
+func (rawCodec) Encode(v any) (string, error) { return v.(string), nil }
+
+func (rawCodec) Decode(data string, v any) error {
+	*v.(*string) = data
+	return nil
+}
+
+func TestTypedKVEmptyValue(t *testing.T) {
+	local, id := localKV(t)
+	stores := map[string]*TypedKV[string]{
+		"http":  NewTypedKV[string](kvServer(t), "ns", WithCodec(rawCodec{})),
+		"local": NewTypedKV[string](local, id, WithCodec(rawCodec{})),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			if err := store.Set(context.Background(), "empty", ""); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := store.Get(context.Background(), "empty"); err != nil || got != "" {
+				t.Fatalf("got %q, %v", got, err)
+			}
+			if _, err := store.Get(context.Background(), "missing"); !errors.Is(err, scerrors.ErrNotFound) {
+				t.Fatalf("missing key: %v", err)
+			}
+		})
+	}
+}
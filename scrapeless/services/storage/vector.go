@@ -2,31 +2,169 @@ package storage
 
 import (
 	"context"
+	"iter"
+	"sync"
 
 	"github.com/scrapeless-ai/sdk-go/env"
-	"github.com/scrapeless-ai/sdk-go/internal/code"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/embedding"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/pager"
 )
 
-type Vector struct{}
+type Vector struct {
+	client storage.Vector
+
+	mu       sync.RWMutex
+	embedder embedding.Embedder
+	sparse   embedding.SparseEncoder
+}
+
+// SetEmbedder sets the embedder computing the vectors of the docs which only
+// have content, and of the text of queries. nil removes it.
+// Parameters:
+//
+//	e: The embedder, its vectors must have the dimension of the collections.
+func (s *Vector) SetEmbedder(e embedding.Embedder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.embedder = e
+}
+
+func (s *Vector) getEmbedder() embedding.Embedder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.embedder
+}
+
+// SetSparseEncoder sets the encoder computing the sparse vectors of the docs
+// which have content and no sparse vector, and of the text of hybrid
+// queries. nil removes it. The encoder knows the docs by collection and doc
+// ID: docs encoded again replace their previous version in its statistics,
+// and deleted ones, see DelDocs, leave them. Deleting a collection does not
+// update them.
+// Parameters:
+//
+//	e: The sparse encoder, such as embedding.LoadBM25.
+func (s *Vector) SetSparseEncoder(e embedding.SparseEncoder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sparse = e
+}
+
+func (s *Vector) getSparseEncoder() embedding.SparseEncoder {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sparse
+}
+
+// embedDocs sets the vector of the docs without one from their content, in
+// a single call to the embedder, then their sparse vector likewise.
+func (s *Vector) embedDocs(ctx context.Context, collId string, docs []models.Doc) error {
+	if err := s.encodeSparse(ctx, collId, docs); err != nil {
+		return err
+	}
+	e := s.getEmbedder()
+	if e == nil {
+		return nil
+	}
+	var (
+		texts   []string
+		indexes []int
+	)
+	for i, doc := range docs {
+		if len(doc.Vector) == 0 && doc.Content != "" {
+			texts = append(texts, doc.Content)
+			indexes = append(indexes, i)
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	vectors, err := e.Embed(ctx, texts)
+	if err != nil {
+		log.Errorf("failed to embed docs: %v", err)
+		return scerrors.From(err)
+	}
+	if len(vectors) != len(texts) {
+		return scerrors.Newf(scerrors.KindInternal, "embedder returned %d vectors for %d docs", len(vectors), len(texts))
+	}
+	for i, index := range indexes {
+		docs[index].Vector = vectors[i]
+	}
+	return nil
+}
+
+func (s *Vector) encodeSparse(ctx context.Context, collId string, docs []models.Doc) error {
+	e := s.getSparseEncoder()
+	if e == nil {
+		return nil
+	}
+	var (
+		ids      []string
+		texts    []string
+		indexes  []int
+		replaced []string // Docs given their own sparse vector
+	)
+	for i, doc := range docs {
+		switch {
+		case len(doc.SparseVector) == 0 && doc.Content != "":
+			ids = append(ids, sparseDocId(collId, doc.ID))
+			texts = append(texts, doc.Content)
+			indexes = append(indexes, i)
+		case len(doc.SparseVector) > 0 && doc.ID != "":
+			replaced = append(replaced, sparseDocId(collId, doc.ID))
+		}
+	}
+	if len(replaced) > 0 {
+		if err := e.RemoveDocs(ctx, replaced); err != nil {
+			log.Errorf("failed to remove sparse docs: %v", err)
+			return scerrors.From(err)
+		}
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+	vectors, err := e.EncodeDocs(ctx, ids, texts)
+	if err != nil {
+		log.Errorf("failed to encode sparse vectors: %v", err)
+		return scerrors.From(err)
+	}
+	if len(vectors) != len(texts) {
+		return scerrors.Newf(scerrors.KindInternal, "sparse encoder returned %d vectors for %d docs", len(vectors), len(texts))
+	}
+	for i, index := range indexes {
+		docs[index].SparseVector = vectors[i]
+	}
+	return nil
+}
+
+// sparseDocId is the ID of a doc for the sparse encoder, empty for the docs
+// whose ID is given by the storage.
+func sparseDocId(collId, docId string) string {
+	if docId == "" {
+		return ""
+	}
+	return collId + "/" + docId
+}
 
 // ListCollections retrieves a list of vector collections with pagination and sorting options.
 // Parameters:
 //
 //	ctx: The context for the request.
 //	page: The page number (minimum 1, defaults to 1 if invalid).
-//	pageSize: Number of items per page (minimum 10, defaults to 10 if invalid).
+//	pageSize: Number of items per page (defaults to 10 if <1).
 //	desc: Whether to sort results in descending order.
 func (s *Vector) ListCollections(ctx context.Context, page int64, pageSize int64, desc bool) (*ListCollectionsResponse, error) {
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 10 {
-		pageSize = 10
+	if pageSize < 1 {
+		pageSize = pager.DefaultPageSize
 	}
-	resp, err := storage.ClientInterface.ListCollections(ctx, &models.ListCollectionsRequest{
+	resp, err := s.client.ListCollections(ctx, &models.ListCollectionsRequest{
 		Page:     page,
 		PageSize: pageSize,
 		Desc:     desc,
@@ -34,8 +172,8 @@ func (s *Vector) ListCollections(ctx context.Context, page int64, pageSize int64
 		RunId:    &env.GetActorEnv().RunId,
 	})
 	if err != nil {
-		log.Errorf("failed to list queues: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to list queues: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var items []Collection
 	for _, item := range resp.Items {
@@ -65,6 +203,22 @@ func (s *Vector) ListCollections(ctx context.Context, page int64, pageSize int64
 	}, nil
 }
 
+// IterCollections iterates over the vector collections of the current run.
+// Parameters:
+//
+//	ctx: The context for the request, cancelling it ends the iteration.
+//	desc: Whether to sort results in descending order.
+//	opts: Page size, prefetch and limit, see package pager.
+func (s *Vector) IterCollections(ctx context.Context, desc bool, opts ...pager.Option) iter.Seq2[Collection, error] {
+	return pager.Seq(ctx, func(ctx context.Context, page, pageSize int64) ([]Collection, int64, error) {
+		resp, err := s.ListCollections(ctx, page, pageSize, desc)
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Items, resp.Total, nil
+	}, opts...)
+}
+
 // CreateCollections creates a new vector collection with the provided request parameters.
 // Parameters:
 //
@@ -72,16 +226,17 @@ func (s *Vector) ListCollections(ctx context.Context, page int64, pageSize int64
 //	req: The request object containing collection configuration details.
 func (s *Vector) CreateCollections(ctx context.Context, req *CreateCollectionRequest) (*CreateCollectionResponse, error) {
 	name := req.Name + "-" + env.GetActorEnv().RunId
-	resp, err := storage.ClientInterface.CreateCollections(ctx, &models.CreateCollectionRequest{
+	resp, err := s.client.CreateCollections(ctx, &models.CreateCollectionRequest{
 		ActorId:     env.GetActorEnv().ActorId,
 		RunId:       env.GetActorEnv().RunId,
 		Name:        name,
 		Description: req.Description,
 		Dimension:   req.Dimension,
+		Metric:      req.Metric,
 	})
 	if err != nil {
-		log.Errorf("failed to create queue: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to create queue: %v", err)
+		return nil, scerrors.From(err)
 	}
 
 	return &CreateCollectionResponse{
@@ -117,10 +272,10 @@ func (s *Vector) UpdateCollection(ctx context.Context, collId string, name strin
 		Name:        name,
 		Description: description,
 	}
-	err := storage.ClientInterface.UpdateCollection(ctx, req)
+	err := s.client.UpdateCollection(ctx, req)
 	if err != nil {
-		log.Errorf("failed to update collection: %v", code.Format(err))
-		return code.Format(err)
+		log.Errorf("failed to update collection: %v", err)
+		return scerrors.From(err)
 	}
 	return nil
 }
@@ -131,10 +286,10 @@ func (s *Vector) UpdateCollection(ctx context.Context, collId string, name strin
 //	ctx: The context for the request.
 //	collId: The ID of the collection to delete.
 func (s *Vector) DelCollection(ctx context.Context, collId string) error {
-	err := storage.ClientInterface.DelCollection(ctx, collId)
+	err := s.client.DelCollection(ctx, collId)
 	if err != nil {
-		log.Errorf("failed to delete collection: %v", code.Format(err))
-		return code.Format(err)
+		log.Errorf("failed to delete collection: %v", err)
+		return scerrors.From(err)
 	}
 	return nil
 }
@@ -145,10 +300,10 @@ func (s *Vector) DelCollection(ctx context.Context, collId string) error {
 //	ctx: The context for the request.
 //	collId: The ID of the collection to retrieve.
 func (s *Vector) GetCollection(ctx context.Context, collId string) (*Collection, error) {
-	coll, err := storage.ClientInterface.GetCollection(ctx, collId)
+	coll, err := s.client.GetCollection(ctx, collId)
 	if err != nil {
-		log.Errorf("failed to get collection: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to get collection: %v", err)
+		return nil, scerrors.From(err)
 	}
 	return &Collection{
 		Id:          coll.Id,
@@ -168,7 +323,8 @@ func (s *Vector) GetCollection(ctx context.Context, collId string) (*Collection,
 	}, nil
 }
 
-// CreateDocs inserts new documents into the collection.
+// CreateDocs inserts new documents into the collection. The vectors of the
+// docs which only have content are computed by the embedder, see SetEmbedder.
 // Parameters:
 //
 //	ctx: The context for the request.
@@ -181,16 +337,20 @@ func (s *Vector) CreateDocs(ctx context.Context, collId string, docs []*BaseDoc)
 			Vector:       d.Vector,
 			Content:      d.Content,
 			SparseVector: d.SparseVector,
+			Metadata:     d.Metadata,
 		})
 	}
+	if err := s.embedDocs(ctx, collId, modelDocs); err != nil {
+		return nil, err
+	}
 	req := &models.CreateDocsRequest{
 		CollId: collId,
 		Docs:   modelDocs,
 	}
-	resp, err := storage.ClientInterface.CreateDocs(ctx, req)
+	resp, err := s.client.CreateDocs(ctx, req)
 	if err != nil {
-		log.Errorf("failed to create docs: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to create docs: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var output []DocOpResult
 	for _, r := range resp.Output {
@@ -220,17 +380,21 @@ func (s *Vector) UpdateDocs(ctx context.Context, collId string, docs []*Doc) (*D
 			Vector:       d.Vector,
 			Content:      d.Content,
 			SparseVector: d.SparseVector,
+			Metadata:     d.Metadata,
 			Score:        d.Score,
 		})
 	}
+	if err := s.embedDocs(ctx, collId, modelDocs); err != nil {
+		return nil, err
+	}
 	req := &models.UpdateDocsRequest{
 		CollId: collId,
 		Docs:   modelDocs,
 	}
-	resp, err := storage.ClientInterface.UpdateDocs(ctx, req)
+	resp, err := s.client.UpdateDocs(ctx, req)
 	if err != nil {
-		log.Errorf("failed to update docs: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to update docs: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var output []DocOpResult
 	for _, r := range resp.Output {
@@ -260,17 +424,21 @@ func (s *Vector) UpsertDocs(ctx context.Context, collId string, docs []*Doc) (*D
 			Vector:       d.Vector,
 			Content:      d.Content,
 			SparseVector: d.SparseVector,
+			Metadata:     d.Metadata,
 			Score:        d.Score,
 		})
 	}
+	if err := s.embedDocs(ctx, collId, modelDocs); err != nil {
+		return nil, err
+	}
 	req := &models.UpsertVectorDocsParam{
 		CollId: collId,
 		Docs:   modelDocs,
 	}
-	resp, err := storage.ClientInterface.UpsertDocs(ctx, req)
+	resp, err := s.client.UpsertDocs(ctx, req)
 	if err != nil {
-		log.Errorf("failed to upsert docs: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to upsert docs: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var output []DocOpResult
 	for _, r := range resp.Output {
@@ -297,13 +465,19 @@ func (s *Vector) DelDocs(ctx context.Context, collId string, ids []string) (*Doc
 		CollId: collId,
 		Ids:    ids,
 	}
-	resp, err := storage.ClientInterface.DelDocs(ctx, req)
+	resp, err := s.client.DelDocs(ctx, req)
 	if err != nil {
-		log.Errorf("failed to delete docs: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to delete docs: %v", err)
+		return nil, scerrors.From(err)
 	}
-	var output []DocOpResult
+	var (
+		output []DocOpResult
+		failed = map[string]bool{}
+	)
 	for _, r := range resp.Output {
+		if r.Code != 0 {
+			failed[r.Id] = true
+		}
 		output = append(output, DocOpResult{
 			DocOp:   r.DocOp,
 			Id:      r.Id,
@@ -311,12 +485,26 @@ func (s *Vector) DelDocs(ctx context.Context, collId string, ids []string) (*Doc
 			Message: r.Message,
 		})
 	}
+	if e := s.getSparseEncoder(); e != nil {
+		var deleted []string
+		for _, id := range ids {
+			if !failed[id] {
+				deleted = append(deleted, sparseDocId(collId, id))
+			}
+		}
+		if err = e.RemoveDocs(ctx, deleted); err != nil {
+			log.Errorf("failed to remove sparse docs: %v", err)
+			return nil, scerrors.From(err)
+		}
+	}
 	return &DocOpResponse{
 		Output: output,
 	}, nil
 }
 
-// QueryDocs queries documents in the collection by vector.
+// QueryDocs queries documents in the collection by vector, or by text when
+// an embedder is set, see SetEmbedder. With a filter, only the documents
+// whose metadata match it are ranked.
 // Parameters:
 //
 //	ctx: The context for the request.
@@ -325,18 +513,35 @@ func (s *Vector) QueryDocs(ctx context.Context, collId string, query *QueryVecto
 	if query.Topk < 1 || query.Topk > 1024 {
 		query.Topk = 1
 	}
+	vector := query.Vector
+	if len(vector) == 0 && query.Text != "" {
+		e := s.getEmbedder()
+		if e == nil {
+			return nil, scerrors.New(scerrors.KindInvalidArgument, "querying by text needs an embedder")
+		}
+		vectors, err := e.Embed(ctx, []string{query.Text})
+		if err != nil {
+			log.Errorf("failed to embed query: %v", err)
+			return nil, scerrors.From(err)
+		}
+		if len(vectors) != 1 {
+			return nil, scerrors.Newf(scerrors.KindInternal, "embedder returned %d vectors for 1 text", len(vectors))
+		}
+		vector = vectors[0]
+	}
 	req := &models.QueryVectorRequest{
 		CollId:         collId,
-		Vector:         query.Vector,
+		Vector:         vector,
 		SparseVector:   query.SparseVector,
 		Topk:           query.Topk,
 		IncludeVector:  query.IncludeVector,
 		IncludeContent: query.IncludeContent,
+		Filter:         query.Filter.toModel(),
 	}
-	resp, err := storage.ClientInterface.QueryDocs(ctx, req)
+	resp, err := s.client.QueryDocs(ctx, req)
 	if err != nil {
-		log.Errorf("failed to query docs: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to query docs: %v", err)
+		return nil, scerrors.From(err)
 	}
 	var docs []*Doc
 	for _, d := range resp {
@@ -345,6 +550,7 @@ func (s *Vector) QueryDocs(ctx context.Context, collId string, query *QueryVecto
 			Vector:       d.Vector,
 			Content:      d.Content,
 			SparseVector: d.SparseVector,
+			Metadata:     d.Metadata,
 			Score:        d.Score,
 		})
 	}
@@ -362,10 +568,10 @@ func (s *Vector) QueryDocsByIds(ctx context.Context, collId string, ids []string
 		CollId: collId,
 		Ids:    ids,
 	}
-	resp, err := storage.ClientInterface.QueryDocsByIds(ctx, req)
+	resp, err := s.client.QueryDocsByIds(ctx, req)
 	if err != nil {
-		log.Errorf("failed to query docs by ids: %v", code.Format(err))
-		return nil, code.Format(err)
+		log.Errorf("failed to query docs by ids: %v", err)
+		return nil, scerrors.From(err)
 	}
 	result := make(map[string]*Doc)
 	for k, v := range resp {
@@ -374,6 +580,7 @@ func (s *Vector) QueryDocsByIds(ctx context.Context, collId string, ids []string
 			Vector:       v.Vector,
 			Content:      v.Content,
 			SparseVector: v.SparseVector,
+			Metadata:     v.Metadata,
 			Score:        v.Score,
 		}
 	}
@@ -0,0 +1,76 @@
+package storage
+
+import "github.com/scrapeless-ai/sdk-go/internal/remote/storage/models"
+
+// MetadataFilter is a condition on the metadata of vector docs, see
+// QueryVectorParam.Filter. A leaf tests the value at Field, a gjson path
+// such as "site" or "author.name", with one of the Filter operators; And and
+// Or combine nested filters. The helpers below build the common ones:
+//
+//	storage.MetadataAnd(
+//		storage.MetadataEq("site", "docs.example.com"),
+//		storage.MetadataRange("publishedAt", "2024-01-01", nil),
+//	)
+type MetadataFilter struct {
+	Field string           `json:"field,omitempty"`
+	Op    string           `json:"op,omitempty"`
+	Value any              `json:"value,omitempty"`
+	And   []MetadataFilter `json:"and,omitempty"` // All of them must hold
+	Or    []MetadataFilter `json:"or,omitempty"`  // One of them must hold
+}
+
+// MetadataEq matches the docs whose value at field equals value.
+func MetadataEq(field string, value any) MetadataFilter {
+	return MetadataFilter{Field: field, Op: FilterEq, Value: value}
+}
+
+// MetadataIn matches the docs whose value at field equals one of values.
+func MetadataIn[T any](field string, values ...T) MetadataFilter {
+	return MetadataFilter{Field: field, Op: FilterIn, Value: values}
+}
+
+// MetadataRange matches the docs whose value at field is between lower and
+// upper, both included. Bounds are numbers, or strings compared in
+// lexicographic order like RFC 3339 dates; a nil bound leaves the range
+// open on its side, and without bounds it matches the docs with a value at
+// field.
+func MetadataRange(field string, lower, upper any) MetadataFilter {
+	var bounds []MetadataFilter
+	if lower != nil {
+		bounds = append(bounds, MetadataFilter{Field: field, Op: FilterGte, Value: lower})
+	}
+	if upper != nil {
+		bounds = append(bounds, MetadataFilter{Field: field, Op: FilterLte, Value: upper})
+	}
+	if len(bounds) == 0 {
+		return MetadataFilter{Field: field, Op: FilterExists, Value: true}
+	}
+	if len(bounds) == 1 {
+		return bounds[0]
+	}
+	return MetadataFilter{And: bounds}
+}
+
+// MetadataAnd matches the docs matching all of filters.
+func MetadataAnd(filters ...MetadataFilter) MetadataFilter {
+	return MetadataFilter{And: filters}
+}
+
+// MetadataOr matches the docs matching one of filters.
+func MetadataOr(filters ...MetadataFilter) MetadataFilter {
+	return MetadataFilter{Or: filters}
+}
+
+func (f *MetadataFilter) toModel() *models.MetadataFilter {
+	if f == nil {
+		return nil
+	}
+	m := &models.MetadataFilter{Field: f.Field, Op: f.Op, Value: f.Value}
+	for i := range f.And {
+		m.And = append(m.And, *f.And[i].toModel())
+	}
+	for i := range f.Or {
+		m.Or = append(m.Or, *f.Or[i].toModel())
+	}
+	return m
+}
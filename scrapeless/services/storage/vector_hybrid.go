@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"sort"
+
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+)
+
+// HybridQuery ranks the documents of the collection by a weighted sum of
+// their dense and sparse scores, which finds both the documents close in
+// meaning and the ones sharing rare keywords, like product codes. It runs a
+// dense and a sparse query of Candidates results each, rescales the scores
+// of each query to [0, 1], from its worst result to its best, and adds them
+// up with the weights; a document missing from the results of a query gets
+// 0 from it. That sum is the Score of the results, the higher the better.
+// The query of a weight of 0 is not run.
+// Parameters:
+//
+//	ctx: The context for the request.
+//	collId: The ID of the collection.
+//	query: The param of query, its Text needs an embedder and a sparse encoder, see SetSparseEncoder.
+func (s *Vector) HybridQuery(ctx context.Context, collId string, query *HybridQueryParam) ([]*Doc, error) {
+	denseWeight, sparseWeight := query.DenseWeight, query.SparseWeight
+	if denseWeight < 0 || sparseWeight < 0 {
+		return nil, scerrors.New(scerrors.KindInvalidArgument, "hybrid query weights cannot be negative")
+	}
+	if denseWeight == 0 && sparseWeight == 0 {
+		denseWeight, sparseWeight = 0.5, 0.5
+	}
+	topk := query.Topk
+	if topk < 1 || topk > 1024 {
+		topk = 1
+	}
+	candidates := query.Candidates
+	if candidates < 1 {
+		candidates = 4 * topk
+	}
+	candidates = min(max(candidates, topk), 1024)
+
+	var (
+		results [][]*Doc
+		weights []float64
+	)
+	if denseWeight > 0 {
+		if len(query.Vector) == 0 && query.Text == "" {
+			return nil, scerrors.New(scerrors.KindInvalidArgument, "hybrid query needs a vector or a text")
+		}
+		docs, err := s.QueryDocs(ctx, collId, &QueryVectorParam{
+			Vector:         query.Vector,
+			Text:           query.Text,
+			Topk:           candidates,
+			IncludeVector:  query.IncludeVector,
+			IncludeContent: query.IncludeContent,
+			Filter:         query.Filter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		results, weights = append(results, docs), append(weights, denseWeight)
+	}
+	if sparseWeight > 0 {
+		sparse := query.SparseVector
+		if len(sparse) == 0 {
+			if query.Text == "" {
+				return nil, scerrors.New(scerrors.KindInvalidArgument, "hybrid query needs a sparse vector or a text")
+			}
+			e := s.getSparseEncoder()
+			if e == nil {
+				return nil, scerrors.New(scerrors.KindInvalidArgument, "hybrid query by text needs a sparse encoder")
+			}
+			var err error
+			if sparse, err = e.EncodeQuery(ctx, query.Text); err != nil {
+				log.Errorf("failed to encode query: %v", err)
+				return nil, scerrors.From(err)
+			}
+		}
+		// None of the terms of the text is known, no doc matches them.
+		if len(sparse) > 0 {
+			docs, err := s.QueryDocs(ctx, collId, &QueryVectorParam{
+				SparseVector:   sparse,
+				Topk:           candidates,
+				IncludeVector:  query.IncludeVector,
+				IncludeContent: query.IncludeContent,
+				Filter:         query.Filter,
+			})
+			if err != nil {
+				return nil, err
+			}
+			results, weights = append(results, docs), append(weights, sparseWeight)
+		}
+	}
+	return fuse(results, weights, int(topk)), nil
+}
+
+// fuse adds up the rescaled scores of the results of several queries, each
+// ranked from its best document, and returns the topk best documents.
+func fuse(results [][]*Doc, weights []float64, topk int) []*Doc {
+	byId := map[string]*Doc{}
+	var docs []*Doc
+	for i, ranked := range results {
+		if len(ranked) == 0 {
+			continue
+		}
+		// The order of the scores depends on the metric of the collection,
+		// the ranking does not.
+		best, worst := ranked[0].Score, ranked[len(ranked)-1].Score
+		for _, doc := range ranked {
+			scaled := 1.0
+			if best != worst {
+				scaled = (doc.Score - worst) / (best - worst)
+			}
+			fused := byId[doc.ID]
+			if fused == nil {
+				copied := *doc
+				fused = &copied
+				fused.Score = 0
+				byId[doc.ID] = fused
+				docs = append(docs, fused)
+			}
+			fused.Score += weights[i] * scaled
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].Score > docs[j].Score })
+	if len(docs) > topk {
+		docs = docs[:topk]
+	}
+	return docs
+}
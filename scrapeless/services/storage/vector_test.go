@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/embedding"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
+)
+
+func localVector(t *testing.T, dimension int) (*Vector, string) {
+	t.Helper()
+	s := newLocalStorage(t)
+	resp, err := s.Vector.CreateCollections(context.Background(), &CreateCollectionRequest{Name: "docs", Dimension: dimension})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.Vector, resp.Coll.Id
+}
+
+func TestVectorEmbedder(t *testing.T) {
+	vector, collId := localVector(t, 64)
+	ctx := context.Background()
+
+	_, err := vector.QueryDocs(ctx, collId, &QueryVectorParam{Text: "paris", Topk: 1})
+	if !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("text query without embedder: %v", err)
+	}
+
+	calls := 0
+	hashing := embedding.NewHashing(64)
+	vector.SetEmbedder(embedding.EmbedderFunc(func(ctx context.Context, texts []string) ([][]float64, error) {
+		calls++
+		return hashing.Embed(ctx, texts)
+	}))
+	given := make([]float64, 64)
+	given[0] = 1
+	resp, err := vector.CreateDocs(ctx, collId, []*BaseDoc{
+		{Content: "cheap flights to paris in spring"},
+		{Content: "a chocolate cake recipe with dark chocolate"},
+		{Content: "hotels near the eiffel tower in paris"},
+		{Content: "kept as given", Vector: given},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, out := range resp.Output {
+		if out.Code != 0 {
+			t.Fatalf("create: %+v", out)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("embedder called %d times for one batch of docs", calls)
+	}
+
+	docs, err := vector.QueryDocs(ctx, collId, &QueryVectorParam{Text: "chocolate cake", Topk: 2, IncludeContent: true})
+	if err != nil || len(docs) != 2 || docs[0].Content != "a chocolate cake recipe with dark chocolate" {
+		t.Fatalf("query: %v, %v", docs, err)
+	}
+	stored, _ := vector.QueryDocsByIds(ctx, collId, []string{resp.Output[3].Id})
+	if doc := stored[resp.Output[3].Id]; doc == nil || doc.Vector[0] != 1 {
+		t.Fatalf("the given vector was replaced: %+v", doc)
+	}
+}
+
+func TestVectorMetadataFilter(t *testing.T) {
+	vector, collId := localVector(t, 2)
+	ctx := context.Background()
+	docs := []*Doc{
+		{ID: "a", Vector: []float64{1, 0}, Metadata: map[string]any{"site": "docs", "year": 2023, "author": map[string]any{"name": "ann"}}},
+		{ID: "b", Vector: []float64{1, 0.1}, Metadata: map[string]any{"site": "blog", "year": 2024}},
+		{ID: "c", Vector: []float64{1, 0.2}, Metadata: map[string]any{"site": "docs", "year": 2025}},
+		{ID: "d", Vector: []float64{0, 1}},
+	}
+	if _, err := vector.UpsertDocs(ctx, collId, docs); err != nil {
+		t.Fatal(err)
+	}
+
+	filters := map[string]struct {
+		filter MetadataFilter
+		want   string
+	}{
+		"eq":          {MetadataEq("site", "docs"), "ac"},
+		"nested path": {MetadataEq("author.name", "ann"), "a"},
+		"in":          {MetadataIn("year", 2023, 2025), "ac"},
+		"range":       {MetadataRange("year", 2024, nil), "bc"},
+		"and":         {MetadataAnd(MetadataEq("site", "docs"), MetadataRange("year", nil, 2024)), "a"},
+		"or":          {MetadataOr(MetadataEq("site", "blog"), MetadataEq("author.name", "ann")), "ab"},
+		"no metadata": {MetadataFilter{Field: "site", Op: FilterExists, Value: false}, "d"},
+	}
+	for name, tc := range filters {
+		got, err := vector.QueryDocs(ctx, collId, &QueryVectorParam{Vector: []float64{1, 0}, Topk: 10, Filter: &tc.filter})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		ids := ""
+		for _, doc := range got {
+			ids += doc.ID
+		}
+		if ids != tc.want {
+			t.Errorf("%s: got %q, want %q", name, ids, tc.want)
+		}
+	}
+
+	for _, bad := range []MetadataFilter{{}, {Field: "year", Op: "between"}, {Field: "year", Op: FilterIn, Value: 1}, {Op: FilterEq, Or: []MetadataFilter{MetadataEq("a", 1)}}} {
+		_, err := vector.QueryDocs(ctx, collId, &QueryVectorParam{Vector: []float64{1, 0}, Filter: &bad})
+		if !errors.Is(err, scerrors.ErrInvalidArgument) {
+			t.Errorf("filter %+v: %v", bad, err)
+		}
+	}
+}
+
+func TestVectorMetadataFilterHTTP(t *testing.T) {
+	var body map[string]any
+	client := httpClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/vector/coll/docs/query" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(request.RespInfo{Data: []map[string]any{{"id": "a", "metadata": map[string]any{"site": "docs"}}}})
+	})
+	vector := &Vector{client: client}
+
+	filter := MetadataOr(MetadataEq("site", "docs"), MetadataRange("year", 2020, 2024))
+	docs, err := vector.QueryDocs(context.Background(), "coll", &QueryVectorParam{Vector: []float64{1}, Filter: &filter})
+	if err != nil || len(docs) != 1 || docs[0].Metadata["site"] != "docs" {
+		t.Fatalf("query: %v, %v", docs, err)
+	}
+	want := map[string]any{"or": []any{
+		map[string]any{"field": "site", "op": "eq", "value": "docs"},
+		map[string]any{"and": []any{
+			map[string]any{"field": "year", "op": "gte", "value": float64(2020)},
+			map[string]any{"field": "year", "op": "lte", "value": float64(2024)},
+		}},
+	}}
+	if !reflect.DeepEqual(body["filter"], want) {
+		t.Fatalf("filter sent %v", body["filter"])
+	}
+}
+
+func TestVectorHybridQuery(t *testing.T) {
+	s := newLocalStorage(t)
+	ctx := context.Background()
+	coll, err := s.Vector.CreateCollections(ctx, &CreateCollectionRequest{Name: "products", Dimension: 64})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collId := coll.Coll.Id
+	namespaceId, _, err := s.KV.CreateNamespace(ctx, "search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bm25, err := embedding.LoadBM25(ctx, s.KV, namespaceId, "products-vocabulary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Vector.SetEmbedder(embedding.NewHashing(64))
+
+	_, err = s.Vector.HybridQuery(ctx, collId, &HybridQueryParam{Text: "phone"})
+	if !errors.Is(err, scerrors.ErrInvalidArgument) {
+		t.Fatalf("text query without sparse encoder: %v", err)
+	}
+
+	s.Vector.SetSparseEncoder(bm25)
+	docs := []*Doc{
+		{ID: "b", Content: "Phone case XJ-9000-B in black"},
+		{ID: "a", Content: "Phone case XJ-9000-A in black"},
+		{ID: "leather", Content: "Black leather phone case with card slots"},
+		{ID: "cake", Content: "Chocolate cake recipe with dark chocolate"},
+	}
+	if _, err = s.Vector.UpsertDocs(ctx, collId, docs); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := s.Vector.QueryDocsByIds(ctx, collId, []string{"b"})
+	if doc := stored["b"]; doc == nil || len(doc.SparseVector) == 0 || len(doc.Vector) != 64 {
+		t.Fatalf("stored doc %+v", doc)
+	}
+
+	ids := func(docs []*Doc) string {
+		var out string
+		for _, doc := range docs {
+			out += doc.ID + " "
+		}
+		return out
+	}
+	got, err := s.Vector.HybridQuery(ctx, collId, &HybridQueryParam{Text: "XJ9000B", Topk: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "b" || got[0].Score <= got[1].Score {
+		t.Fatalf("code query: %s", ids(got))
+	}
+
+	got, err = s.Vector.HybridQuery(ctx, collId, &HybridQueryParam{Text: "chocolate", Topk: 4, DenseWeight: 1})
+	if err != nil || len(got) != 4 || got[0].ID != "cake" {
+		t.Fatalf("dense only: %s, %v", ids(got), err)
+	}
+
+	// Upserting the docs again leaves the statistics as they were, deleting
+	// one takes it out of them.
+	before, _ := bm25.EncodeQuery(ctx, "black case")
+	if _, err = s.Vector.UpsertDocs(ctx, collId, docs); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := bm25.EncodeQuery(ctx, "black case"); !reflect.DeepEqual(before, after) {
+		t.Fatalf("upserting again changed the query from %v to %v", before, after)
+	}
+	if _, err = s.Vector.DelDocs(ctx, collId, []string{"cake"}); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := bm25.EncodeQuery(ctx, "black case"); reflect.DeepEqual(before, after) {
+		t.Fatalf("deleting a doc left the query at %v", after)
+	}
+
+	if err = bm25.Save(ctx); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := embedding.LoadBM25(ctx, s.KV, namespaceId, "products-vocabulary")
+	if err != nil || loaded.Len() != bm25.Len() {
+		t.Fatalf("reloaded vocabulary of %d terms, want %d: %v", loaded.Len(), bm25.Len(), err)
+	}
+}
@@ -4,69 +4,80 @@ import (
 	"context"
 	"errors"
 	"github.com/scrapeless-ai/sdk-go/env"
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/universal"
-	sh "github.com/scrapeless-ai/sdk-go/internal/remote/universal/http"
 	"github.com/scrapeless-ai/sdk-go/internal/remote/universal/models"
+	scerrors "github.com/scrapeless-ai/sdk-go/scrapeless/errors"
 	"github.com/scrapeless-ai/sdk-go/scrapeless/log"
+	"github.com/scrapeless-ai/sdk-go/scrapeless/poller"
 	"github.com/tidwall/gjson"
 	"strings"
-	"time"
 )
 
-type Universal struct{}
+type Universal struct {
+	client universal.Universal
+}
 
-func New(serverMode string) *Universal {
+// New creates the universal scraping service. cfg overrides the credentials
+// and endpoints read from the environment.
+func New(serverMode string, cfg ...request.ClientConfig) *Universal {
 	log.Info("Internal Universal init")
-	universal.NewClient(serverMode, env.Env.ScrapelessBaseApiUrl)
-	return &Universal{}
+	c := request.ClientConfigOf(cfg...)
+	return &Universal{client: universal.NewClient(serverMode, c.BaseApiUrl, c)}
 }
 
 func (us *Universal) CreateTask(ctx context.Context, req UniversalTaskRequest) ([]byte, error) {
 	if req.ProxyCountry == "" {
-		req.ProxyCountry = env.Env.ProxyCountry
+		req.ProxyCountry = env.Get().ProxyCountry
 	}
 	if req.Actor == "" {
-		return nil, errors.New("actor do not be empty")
+		return nil, scerrors.New(scerrors.KindInvalidArgument, "actor do not be empty")
 	}
-	response, err := sh.Default().CreateTask(ctx, &models.UniversalTaskRequest{
+	response, err := us.client.CreateTask(ctx, &models.UniversalTaskRequest{
 		Actor: string(req.Actor),
 		Input: req.Input,
 		Proxy: models.TaskProxy{Country: strings.ToUpper(req.ProxyCountry)},
 	})
 	if err != nil {
 		log.Errorf("scraping create err:%v", err)
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	return response, nil
 }
 
 func (us *Universal) Close() error {
-	return sh.Default().Close()
+	return us.client.Close()
 }
 
 func (us *Universal) GetTaskResult(ctx context.Context, taskId string) ([]byte, error) {
-	result, err := sh.Default().GetTaskResult(ctx, taskId)
+	result, err := us.client.GetTaskResult(ctx, taskId)
 	if err != nil {
+		if errors.Is(err, poller.ErrPending) {
+			return nil, err
+		}
 		log.Errorf("get task result err:%v", err)
-		return nil, err
+		return nil, scerrors.From(err)
 	}
 	return result, nil
 }
 
-func (us *Universal) Scrape(ctx context.Context, req UniversalTaskRequest) ([]byte, error) {
+// Scrape creates a universal task and waits for it to finish, backing off
+// between polls until ctx is done.
+func (us *Universal) Scrape(ctx context.Context, req UniversalTaskRequest, opts ...poller.Option) ([]byte, error) {
 	task, err := us.CreateTask(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 	taskId := gjson.Parse(string(task)).Get("taskId").String()
-	if taskId != "" {
-		for {
-			result, err := us.GetTaskResult(ctx, taskId)
-			if err == nil {
-				return result, nil
-			}
-			time.Sleep(time.Millisecond * 200)
-		}
+	if taskId == "" {
+		return task, nil
 	}
-	return task, nil
+	result, err := poller.Poll(ctx, func(ctx context.Context) ([]byte, error) {
+		return us.client.GetTaskResult(ctx, taskId)
+	}, opts...)
+	if err != nil {
+		log.Errorf("get task result err:%v", err)
+		return nil, scerrors.From(err)
+	}
+	return result, nil
 }
@@ -0,0 +1,75 @@
+package scrapeless
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/scrapeless-ai/sdk-go/internal/remote/request"
+)
+
+// Settings of the HTTP transport the services of a Client send their requests
+// through. Clients without transport options share the default transport.
+type (
+	TransportConfig = request.Config
+	RetryPolicy     = request.RetryPolicy
+	EndpointPolicy  = request.EndpointPolicy
+	RateLimit       = request.RateLimit
+	BreakerConfig   = request.BreakerConfig
+)
+
+// ErrCircuitOpen is returned by requests to a host whose circuit breaker is open.
+var ErrCircuitOpen = request.ErrCircuitOpen
+
+type TransportOption struct {
+	apply func(cfg *TransportConfig)
+}
+
+func (o *TransportOption) Apply(c *Client) {
+	if c.transport == nil {
+		cfg := request.Default().Config()
+		c.transport = &cfg
+	}
+	o.apply(c.transport)
+}
+
+// WithTransport replaces the whole transport configuration.
+func WithTransport(cfg TransportConfig) Option {
+	return &TransportOption{apply: func(c *TransportConfig) { *c = cfg }}
+}
+
+// WithHTTPTimeout bounds every call, retries included. Zero means no limit.
+func WithHTTPTimeout(timeout time.Duration) Option {
+	return &TransportOption{apply: func(c *TransportConfig) { c.Timeout = timeout }}
+}
+
+// WithRetryPolicy sets the retry policy used by endpoints without their own.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return &TransportOption{apply: func(c *TransportConfig) { c.Retry = policy }}
+}
+
+// WithEndpointRetryPolicy sets the retry policy of requests matching method
+// (empty for any) and the URL path prefix. Policies added first win.
+func WithEndpointRetryPolicy(method, pathPrefix string, policy RetryPolicy) Option {
+	return &TransportOption{apply: func(c *TransportConfig) {
+		c.Endpoints = append(c.Endpoints, EndpointPolicy{Method: method, PathPrefix: pathPrefix, Retry: policy})
+	}}
+}
+
+// WithRateLimit limits requests per API key to rate per second with the given burst.
+func WithRateLimit(rate float64, burst int) Option {
+	return &TransportOption{apply: func(c *TransportConfig) { c.RateLimit = RateLimit{Rate: rate, Burst: burst} }}
+}
+
+// WithCircuitBreaker opens the circuit of a host after threshold consecutive
+// failures for cooldown. A negative threshold disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return &TransportOption{apply: func(c *TransportConfig) {
+		c.Breaker = BreakerConfig{FailureThreshold: threshold, Cooldown: cooldown}
+	}}
+}
+
+// WithHTTPTransport sets the round tripper that actually sends requests,
+// for example to add a proxy or custom TLS settings.
+func WithHTTPTransport(base http.RoundTripper) Option {
+	return &TransportOption{apply: func(c *TransportConfig) { c.Base = base }}
+}